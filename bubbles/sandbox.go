@@ -0,0 +1,78 @@
+package bubbles
+
+import (
+	"fmt"
+
+	force "github.com/ForceCLI/force/lib"
+	"github.com/charmbracelet/bubbles/progress"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type SandboxModel struct {
+	force.SandboxProcess
+	progress progress.Model
+}
+
+type NewSandboxStatusMsg struct {
+	force.SandboxProcess
+}
+
+func NewSandboxModel() SandboxModel {
+	return SandboxModel{
+		progress: progress.New(progress.WithDefaultGradient()),
+	}
+}
+
+func (m SandboxModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m SandboxModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.progress.Width = msg.Width - padding*2 - 4
+		if m.progress.Width > maxWidth {
+			m.progress.Width = maxWidth
+		}
+		return m, nil
+
+	case NewSandboxStatusMsg:
+		m.SandboxProcess = msg.SandboxProcess
+		cmd := m.progress.SetPercent(float64(m.CopyProgress) / 100)
+		return m, cmd
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "ctrl+c":
+			return m, tea.Quit
+		}
+	case progress.FrameMsg:
+		progressModel, cmd := m.progress.Update(msg)
+		m.progress = progressModel.(progress.Model)
+		return m, cmd
+	case QuitMsg:
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+func (m SandboxModel) View() string {
+	header := headerStyle.Render(fmt.Sprintf("Sandbox %s", m.SandboxName))
+	var status string
+	switch m.Status {
+	case force.SandboxStatusStopped, force.SandboxStatusDiscarded, force.SandboxStatusDeleted:
+		status = failureStyle.Render(fmt.Sprintf("Status: %s", m.Status))
+	default:
+		status = detailStyle.Render(fmt.Sprintf("Status: %s", m.Status))
+	}
+	components := []string{
+		header, "",
+		infoStyle.Render(fmt.Sprintf("Process Id: %s", m.Id)),
+		infoStyle.Render(fmt.Sprintf("License Type: %s", m.LicenseType)),
+		infoStyle.Render(fmt.Sprintf("Start Date: %s", m.StartDate)),
+		status,
+		infoStyle.Render(fmt.Sprintf("Copy Progress: %d%%", m.CopyProgress)),
+		m.progress.View(),
+	}
+	return lipgloss.JoinVertical(lipgloss.Top, components...) + "\n"
+}
@@ -8,6 +8,7 @@ import (
 
 	. "github.com/ForceCLI/force/config"
 	. "github.com/ForceCLI/force/error"
+	. "github.com/ForceCLI/force/lib"
 	"github.com/spf13/cobra"
 )
 
@@ -25,6 +26,7 @@ var activeCmd = &cobra.Command{
 	Example: `
   force active
   force active user@example.org
+  force active prod
   `,
 	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
@@ -57,6 +59,7 @@ func printCurrentAccount(cmd *cobra.Command) {
 }
 
 func setAccount(account string, local bool) {
+	account = ResolveAccount(account)
 	accounts, _ := Config.List("accounts")
 	i := sort.SearchStrings(accounts, account)
 	if i < len(accounts) && accounts[i] == account {
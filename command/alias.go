@@ -0,0 +1,90 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	. "github.com/ForceCLI/force/error"
+	. "github.com/ForceCLI/force/lib"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	aliasCmd.AddCommand(aliasSetCmd)
+	aliasCmd.AddCommand(aliasListCmd)
+	aliasCmd.AddCommand(aliasRemoveCmd)
+	RootCmd.AddCommand(aliasCmd)
+}
+
+var aliasCmd = &cobra.Command{
+	Use:   "alias",
+	Short: "Manage aliases for saved logins",
+	Long: `
+Manage aliases for saved logins.  An alias can be used in place of the
+username anywhere an account is expected, including --account and
+"force active".
+
+Logins saved under an alias, such as those added with "force usedxauth",
+are listed along with the aliases set here.  A saved login takes precedence
+over an alias with the same name.
+`,
+	Example: `
+  force alias set prod admin@example.com
+  force -a prod query "SELECT Id FROM Account LIMIT 1"
+  force alias list
+  force alias remove prod
+`,
+}
+
+var aliasSetCmd = &cobra.Command{
+	Use:   "set <alias> <account>",
+	Short: "Set an alias for a saved login",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := SetAlias(args[0], args[1]); err != nil {
+			ErrorAndExit(err.Error())
+		}
+		fmt.Printf("%s is now an alias for %s\n", args[0], ResolveAccount(args[0]))
+	},
+}
+
+var aliasListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List aliases",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		aliases := Aliases()
+		if len(aliases) == 0 {
+			fmt.Println("No aliases")
+			return
+		}
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Alias", "Account"})
+		table.SetAutoWrapText(false)
+		var names []string
+		for alias := range aliases {
+			names = append(names, alias)
+		}
+		sort.Strings(names)
+		for _, alias := range names {
+			table.Append([]string{alias, aliases[alias]})
+		}
+		table.Render()
+	},
+}
+
+var aliasRemoveCmd = &cobra.Command{
+	Use:   "remove <alias>...",
+	Short: "Remove aliases",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		for _, alias := range args {
+			if err := RemoveAlias(alias); err != nil {
+				ErrorAndExit(err.Error())
+			}
+			fmt.Printf("Removed %s\n", alias)
+		}
+	},
+}
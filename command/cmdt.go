@@ -0,0 +1,186 @@
+package command
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	. "github.com/ForceCLI/force/error"
+	. "github.com/ForceCLI/force/lib"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	cmdtExportCmd.Flags().StringP("output", "o", "", "write CSV to `file` instead of stdout")
+
+	cmdtImportCmd.Flags().BoolP("checkonly", "c", false, "validate the records without saving them")
+	cmdtImportCmd.Flags().StringP("directory", "d", "", "write customMetadata files to `directory` instead of deploying them")
+
+	cmdtCmd.AddCommand(cmdtExportCmd)
+	cmdtCmd.AddCommand(cmdtImportCmd)
+	RootCmd.AddCommand(cmdtCmd)
+}
+
+var cmdtCmd = &cobra.Command{
+	Use:   "cmdt",
+	Short: "Export and import custom metadata type records as CSV",
+	Example: `
+  force cmdt export Setting__mdt > settings.csv
+  force cmdt import Setting__mdt settings.csv
+`,
+}
+
+var cmdtExportCmd = &cobra.Command{
+	Use:   "export [flags] <type>",
+	Short: "Export custom metadata records to CSV",
+	Long: `
+Export the records of a custom metadata type to CSV.  The DeveloperName and
+MasterLabel columns are followed by a column for each custom field.
+`,
+	Example: `
+  force cmdt export Setting__mdt
+  force cmdt export Setting__mdt -o settings.csv
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+		out := os.Stdout
+		if output != "" {
+			var err error
+			out, err = os.Create(output)
+			if err != nil {
+				ErrorAndExit(err.Error())
+			}
+			defer out.Close()
+		}
+		runExportCustomMetadata(args[0], out)
+	},
+}
+
+var cmdtImportCmd = &cobra.Command{
+	Use:   "import [flags] <type> <file>",
+	Short: "Import custom metadata records from CSV",
+	Long: `
+Import the records of a custom metadata type from CSV.  The file must include a
+DeveloperName column.  The MasterLabel column is optional and defaults to the
+DeveloperName.  The remaining columns must be custom fields of the type.
+
+Values are validated against the field types before a customMetadata file is
+generated for each record and deployed.  Existing records with the same
+DeveloperName are updated.
+`,
+	Example: `
+  force cmdt import Setting__mdt settings.csv
+  force cmdt import Setting__mdt settings.csv --checkonly
+  force cmdt import Setting__mdt settings.csv -d src
+`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		checkOnly, _ := cmd.Flags().GetBool("checkonly")
+		directory, _ := cmd.Flags().GetString("directory")
+		runImportCustomMetadata(args[0], args[1], checkOnly, directory)
+	},
+}
+
+func runExportCustomMetadata(typeName string, out io.Writer) {
+	t, err := force.DescribeCustomMetadataType(typeName)
+	if err != nil {
+		ErrorAndExit(err.Error())
+	}
+	result, err := force.Query(t.Query())
+	if err != nil {
+		ErrorAndExit(err.Error())
+	}
+	columns := t.Columns()
+	w := csv.NewWriter(out)
+	w.Write(columns)
+	for _, record := range result.Records {
+		row := make([]string, len(columns))
+		for i, column := range columns {
+			row[i] = customMetadataCsvValue(record[column])
+		}
+		w.Write(row)
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		ErrorAndExit(err.Error())
+	}
+}
+
+func customMetadataCsvValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return fmt.Sprint(value)
+}
+
+func runImportCustomMetadata(typeName string, csvFile string, checkOnly bool, directory string) {
+	f, err := os.Open(csvFile)
+	if err != nil {
+		ErrorAndExit(err.Error())
+	}
+	defer f.Close()
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		ErrorAndExit("Could not read %s: %s", csvFile, err.Error())
+	}
+	if len(rows) == 0 {
+		ErrorAndExit("%s is empty", csvFile)
+	}
+	t, err := force.DescribeCustomMetadataType(typeName)
+	if err != nil {
+		ErrorAndExit(err.Error())
+	}
+	files, err := t.RecordFiles(rows[0], rows[1:])
+	if err != nil {
+		ErrorAndExit(err.Error())
+	}
+	if directory != "" {
+		writeCustomMetadataFiles(files, directory)
+		return
+	}
+	result, err := force.Metadata.Deploy(files, ForceDeployOptions{SinglePackage: true, RollbackOnError: true, CheckOnly: checkOnly})
+	if err != nil {
+		ErrorAndExit(err.Error())
+	}
+	if !result.Success {
+		for _, failure := range result.Details.ComponentFailures {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", failure.FullName, failure.Problem)
+		}
+		ErrorAndExit("Failed to import %s records", typeName)
+	}
+	if checkOnly {
+		fmt.Printf("Validated %d %s records\n", len(rows)-1, typeName)
+	} else {
+		fmt.Printf("Imported %d %s records\n", len(rows)-1, typeName)
+	}
+}
+
+func writeCustomMetadataFiles(files ForceMetadataFiles, directory string) {
+	var names []string
+	for name := range files {
+		if name != "package.xml" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		path := filepath.Join(directory, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			ErrorAndExit(err.Error())
+		}
+		if err := os.WriteFile(path, files[name], 0644); err != nil {
+			ErrorAndExit(err.Error())
+		}
+		fmt.Println(path)
+	}
+}
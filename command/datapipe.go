@@ -24,6 +24,7 @@ func init() {
 	dataPipeUpdateCmd.Flags().StringP("scripttype", "t", "Pig", "script type")
 
 	dataPipeListCmd.Flags().StringP("format", "f", "json", "format (csv or json)")
+	setOutputFormats(dataPipeListCmd, "csv", "json")
 
 	dataPipeDeleteCmd.Flags().StringP("name", "n", "", "data pipeline name")
 
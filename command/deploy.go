@@ -6,10 +6,10 @@ import (
 	"os"
 	"os/signal"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
-	. "github.com/ForceCLI/force/error"
 	. "github.com/ForceCLI/force/lib"
 	"github.com/spf13/cobra"
 )
@@ -22,6 +22,9 @@ type deployOutputOptions struct {
 	ignoreCodeCoverageWarnings bool
 	suppressUnexpectedError    bool
 	errorOnTestFailure         bool
+	// compact summarizes the result in a line followed by any failures, as
+	// used when watching for changes
+	compact bool
 }
 
 func defaultDeployOutputOptions() *deployOutputOptions {
@@ -38,7 +41,11 @@ func defaultDeployOutputOptions() *deployOutputOptions {
 
 var testFailureError = errors.New("Apex tests failed")
 
-func monitorDeploy(deployId string) (ForceCheckDeploymentStatusResult, error) {
+// runRelevantTests is a pseudo test level that selects the tests to run
+// client-side before deploying with RunSpecifiedTests
+const runRelevantTests = "RunRelevantTests"
+
+func monitorDeploy(force *Force, deployId string, log Logger) (ForceCheckDeploymentStatusResult, error) {
 	var result ForceCheckDeploymentStatusResult
 	var err error
 	retrying := false
@@ -49,7 +56,7 @@ func monitorDeploy(deployId string) (ForceCheckDeploymentStatusResult, error) {
 				return result, fmt.Errorf("Error getting deploy status: %w", err)
 			} else {
 				retrying = true
-				Log.Info(fmt.Sprintf("Received error checking deploy status: %s.  Will retry once before aborting.", err.Error()))
+				log.Info(fmt.Sprintf("Received error checking deploy status: %s.  Will retry once before aborting.", err.Error()))
 			}
 		} else {
 			retrying = false
@@ -58,7 +65,7 @@ func monitorDeploy(deployId string) (ForceCheckDeploymentStatusResult, error) {
 			break
 		}
 		if !retrying {
-			Log.Info(result)
+			log.Info(result)
 		}
 		time.Sleep(5000 * time.Millisecond)
 	}
@@ -66,25 +73,27 @@ func monitorDeploy(deployId string) (ForceCheckDeploymentStatusResult, error) {
 }
 
 func deploy(force *Force, files ForceMetadataFiles, deployOptions *ForceDeployOptions, outputOptions *deployOutputOptions) error {
-	if outputOptions.quiet {
-		previousLogger := Log
-		var l quietLogger
-		Log = l
-		defer func() {
-			Log = previousLogger
-		}()
+	log := Log
+	if outputOptions.quiet || outputOptions.compact {
+		log = quietLogger{}
+	}
+	if deployOptions.TestLevel == runRelevantTests {
+		if err := selectRelevantTests(force, files, deployOptions, outputOptions); err != nil {
+			return err
+		}
 	}
 	startTime := time.Now()
 	deployId, err := force.Metadata.StartDeploy(files, *deployOptions)
 	if err != nil {
-		ErrorAndExit(err.Error())
+		return err
 	}
 	stopDeployUponSignal(force, deployId)
 	if outputOptions.interactive {
 		watchDeploy(deployId)
 		return nil
 	}
-	result, err := monitorDeploy(deployId)
+	result, err := monitorDeploy(force, deployId, log)
+	deployFinished(deployId)
 	if err != nil {
 		return err
 	}
@@ -105,6 +114,9 @@ func deploy(force *Force, files ForceMetadataFiles, deployOptions *ForceDeployOp
 
 	switch {
 	case outputOptions.quiet:
+	case outputOptions.compact:
+		// Failures are included in the summary
+		fmt.Print(compactDeployResult(result, duration))
 	case junitOutput:
 		output, err := result.ToJunit(duration.Seconds())
 		if err != nil {
@@ -156,24 +168,94 @@ func deploy(force *Force, files ForceMetadataFiles, deployOptions *ForceDeployOp
 	return nil
 }
 
+func selectRelevantTests(force *Force, files ForceMetadataFiles, deployOptions *ForceDeployOptions, outputOptions *deployOutputOptions) error {
+	tests, err := force.SelectRelevantTests(files)
+	if err != nil {
+		return fmt.Errorf("Could not select relevant tests: %w", err)
+	}
+	deployOptions.TestLevel = "RunSpecifiedTests"
+	deployOptions.RunTests = nil
+	for _, t := range tests {
+		deployOptions.RunTests = append(deployOptions.RunTests, t.Name)
+		if !outputOptions.quiet {
+			fmt.Fprintf(os.Stderr, "Running %s: %s\n", t.Name, strings.Join(t.Reasons, "; "))
+		}
+	}
+	if len(deployOptions.RunTests) == 0 {
+		if !outputOptions.quiet {
+			fmt.Fprintln(os.Stderr, "No relevant tests found")
+		}
+		deployOptions.RunTests = []string{""}
+	}
+	return nil
+}
+
+// runningDeploys are the deploys cancelled upon SIGINT or SIGTERM.  A single
+// signal handler is shared so that deploying to several accounts at once, or
+// repeatedly in watch mode, cancels every deploy in progress.  A second
+// signal before the deploys finish, or a signal while no deploy is running,
+// exits.
+var runningDeploys = struct {
+	sync.Mutex
+	deploys map[string]*Force
+	// interrupts counts the signals received since a deploy was last running
+	interrupts int
+	handler    sync.Once
+}{deploys: make(map[string]*Force)}
+
 func stopDeployUponSignal(force *Force, deployId string) {
+	runningDeploys.handler.Do(cancelDeploysUponSignal)
+	runningDeploys.Lock()
+	defer runningDeploys.Unlock()
+	runningDeploys.deploys[deployId] = force
+}
+
+// deployFinished stops cancelling the deploy upon a signal
+func deployFinished(deployId string) {
+	runningDeploys.Lock()
+	defer runningDeploys.Unlock()
+	delete(runningDeploys.deploys, deployId)
+	if len(runningDeploys.deploys) == 0 {
+		runningDeploys.interrupts = 0
+	}
+}
+
+func cancelDeploysUponSignal() {
 	sigs := make(chan os.Signal, 1)
 	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
 
 	go func() {
-		interuptsReceived := 0
 		for {
 			<-sigs
-			if interuptsReceived > 0 {
+			if !cancelRunningDeploys() {
 				os.Exit(1)
 			}
-			fmt.Fprintf(os.Stderr, "Cancelling deploy %s\n", deployId)
-			force.Metadata.CancelDeploy(deployId)
-			interuptsReceived++
 		}
 	}()
 }
 
+// cancelRunningDeploys cancels the running deploys.  It returns false if there
+// are none, or if they're already being cancelled.
+func cancelRunningDeploys() bool {
+	runningDeploys.Lock()
+	if runningDeploys.interrupts > 0 || len(runningDeploys.deploys) == 0 {
+		runningDeploys.Unlock()
+		return false
+	}
+	runningDeploys.interrupts++
+	deploys := make(map[string]*Force, len(runningDeploys.deploys))
+	for deployId, force := range runningDeploys.deploys {
+		deploys[deployId] = force
+	}
+	runningDeploys.Unlock()
+
+	for deployId, force := range deploys {
+		fmt.Fprintf(os.Stderr, "Cancelling deploy %s\n", deployId)
+		force.Metadata.CancelDeploy(deployId)
+	}
+	return true
+}
+
 func getDeploymentOutputOptions(cmd *cobra.Command) *deployOutputOptions {
 	outputOptions := defaultDeployOutputOptions()
 
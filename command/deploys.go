@@ -27,6 +27,7 @@ func init() {
 	cancelDeployCmd.MarkFlagsMutuallyExclusive("deploy-id", "all")
 
 	listDeploysCmd.Flags().StringP("format", "f", defaultOutputFormat, "output format: csv, json, json-pretty, console")
+	setOutputFormats(listDeploysCmd, "csv", "json", "json-pretty", "console")
 
 	listDeployErrorsCmd.Flags().StringP("deploy-id", "d", "", "Deploy Id to cancel")
 	listDeployErrorsCmd.MarkFlagRequired("deploy-id")
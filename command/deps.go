@@ -0,0 +1,232 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	. "github.com/ForceCLI/force/error"
+	. "github.com/ForceCLI/force/lib"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	depsCmd.Flags().StringP("type", "t", "", "metadata type of component")
+	depsCmd.Flags().StringP("name", "n", "", "name of component")
+	depsCmd.Flags().StringP("package", "p", "", "path to package.xml listing components")
+	depsCmd.Flags().StringP("direction", "d", "both", "direction to follow: uses, usedby, or both")
+	depsCmd.Flags().Int("depth", 1, "levels of dependencies to follow (0 for unlimited)")
+	depsCmd.Flags().StringP("format", "f", "console", "output format: console, json, dot, mermaid")
+	setOutputFormats(depsCmd, "console", "json", "dot", "mermaid")
+	depsCmd.MarkFlagsMutuallyExclusive("package", "type")
+	depsCmd.MarkFlagsMutuallyExclusive("package", "name")
+	RootCmd.AddCommand(depsCmd)
+}
+
+var depsCmd = &cobra.Command{
+	Use:   "deps [flags]",
+	Short: "Display metadata component dependencies",
+	Long: `
+Display the dependency tree of metadata components using the Tooling API's
+MetadataComponentDependency object.  Shows both the components a component
+uses and the components that use it.
+`,
+	Example: `
+  force deps -t ApexClass -n MyClass
+  force deps -t CustomField -n Due_Date --direction usedby
+  force deps -t ApexClass -n MyClass --depth 0 --format dot | dot -Tsvg > deps.svg
+  force deps --package src/package.xml --format mermaid
+`,
+	Args: cobra.MaximumNArgs(0),
+	Run: func(cmd *cobra.Command, args []string) {
+		metadataType, _ := cmd.Flags().GetString("type")
+		metadataName, _ := cmd.Flags().GetString("name")
+		packageXml, _ := cmd.Flags().GetString("package")
+		directionFlag, _ := cmd.Flags().GetString("direction")
+		depth, _ := cmd.Flags().GetInt("depth")
+		format, _ := cmd.Flags().GetString("format")
+
+		var components []MetadataComponent
+		switch {
+		case packageXml != "":
+			data, err := ioutil.ReadFile(packageXml)
+			if err != nil {
+				ErrorAndExit(err.Error())
+			}
+			components, err = PackageComponents(data)
+			if err != nil {
+				ErrorAndExit(err.Error())
+			}
+		case metadataType != "" && metadataName != "":
+			components = append(components, NewMetadataComponent(metadataType, metadataName))
+		default:
+			ErrorAndExit("Specify a component with --type and --name, or a --package")
+		}
+		directions, err := dependencyDirections(directionFlag)
+		if err != nil {
+			ErrorAndExit(err.Error())
+		}
+		runDeps(components, directions, depth, format)
+	},
+}
+
+type componentDependencies struct {
+	Component MetadataComponent `json:"component"`
+	Uses      *DependencyNode   `json:"uses,omitempty"`
+	UsedBy    *DependencyNode   `json:"usedBy,omitempty"`
+}
+
+func dependencyDirections(direction string) ([]DependencyDirection, error) {
+	switch strings.ToLower(direction) {
+	case "uses":
+		return []DependencyDirection{DependencyUses}, nil
+	case "usedby", "used-by":
+		return []DependencyDirection{DependencyUsedBy}, nil
+	case "both":
+		return []DependencyDirection{DependencyUses, DependencyUsedBy}, nil
+	}
+	return nil, fmt.Errorf("Invalid direction: %s", direction)
+}
+
+func runDeps(components []MetadataComponent, directions []DependencyDirection, depth int, format string) {
+	var results []componentDependencies
+	for _, c := range components {
+		result := componentDependencies{Component: c}
+		for _, direction := range directions {
+			tree, err := force.DependencyTree(c, direction, depth)
+			if err != nil {
+				ErrorAndExit(err.Error())
+			}
+			if direction == DependencyUses {
+				result.Uses = tree
+			} else {
+				result.UsedBy = tree
+			}
+		}
+		results = append(results, result)
+	}
+	if err := renderDependencies(os.Stdout, results, format); err != nil {
+		ErrorAndExit(err.Error())
+	}
+}
+
+func renderDependencies(w io.Writer, results []componentDependencies, format string) error {
+	switch format {
+	case "console":
+		for i, r := range results {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			renderDependencyConsole(w, r)
+		}
+	case "json":
+		b, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, string(b))
+	case "dot":
+		renderDependencyDot(w, results)
+	case "mermaid":
+		renderDependencyMermaid(w, results)
+	default:
+		return fmt.Errorf("Format %s not supported", format)
+	}
+	return nil
+}
+
+func renderDependencyConsole(w io.Writer, r componentDependencies) {
+	fmt.Fprintln(w, r.Component.String())
+	for _, section := range []struct {
+		label string
+		tree  *DependencyNode
+	}{{"Uses", r.Uses}, {"Used By", r.UsedBy}} {
+		if section.tree == nil {
+			continue
+		}
+		fmt.Fprintf(w, "  %s:\n", section.label)
+		if len(section.tree.Children) == 0 {
+			fmt.Fprintln(w, "    (none)")
+			continue
+		}
+		renderDependencyChildren(w, section.tree, "    ")
+	}
+}
+
+func renderDependencyChildren(w io.Writer, node *DependencyNode, prefix string) {
+	for i, child := range node.Children {
+		branch, indent := "├── ", "│   "
+		if i == len(node.Children)-1 {
+			branch, indent = "└── ", "    "
+		}
+		label := child.String()
+		if child.Cycle {
+			label += " (cycle)"
+		}
+		fmt.Fprintln(w, prefix+branch+label)
+		renderDependencyChildren(w, child, prefix+indent)
+	}
+}
+
+// dependencyEdges returns the edges of all trees oriented so that each edge
+// points from the using component to the used component.
+func dependencyEdges(results []componentDependencies) [][2]MetadataComponent {
+	var edges [][2]MetadataComponent
+	seen := make(map[string]bool)
+	add := func(from, to MetadataComponent) {
+		key := from.String() + "->" + to.String()
+		if !seen[key] {
+			seen[key] = true
+			edges = append(edges, [2]MetadataComponent{from, to})
+		}
+	}
+	for _, r := range results {
+		if r.Uses != nil {
+			for _, e := range r.Uses.Edges() {
+				add(e[0], e[1])
+			}
+		}
+		if r.UsedBy != nil {
+			for _, e := range r.UsedBy.Edges() {
+				add(e[1], e[0])
+			}
+		}
+	}
+	return edges
+}
+
+func renderDependencyDot(w io.Writer, results []componentDependencies) {
+	fmt.Fprintln(w, "digraph dependencies {")
+	fmt.Fprintln(w, "  rankdir=LR;")
+	fmt.Fprintln(w, "  node [shape=box];")
+	for _, r := range results {
+		fmt.Fprintf(w, "  %q [style=bold];\n", r.Component.String())
+	}
+	for _, e := range dependencyEdges(results) {
+		fmt.Fprintf(w, "  %q -> %q;\n", e[0].String(), e[1].String())
+	}
+	fmt.Fprintln(w, "}")
+}
+
+func renderDependencyMermaid(w io.Writer, results []componentDependencies) {
+	ids := make(map[string]string)
+	nodeId := func(c MetadataComponent) string {
+		key := c.String()
+		if id, ok := ids[key]; ok {
+			return id
+		}
+		id := fmt.Sprintf("n%d", len(ids))
+		ids[key] = id
+		return fmt.Sprintf("%s[\"%s\"]", id, strings.ReplaceAll(key, `"`, "#quot;"))
+	}
+	fmt.Fprintln(w, "graph LR")
+	for _, r := range results {
+		fmt.Fprintf(w, "  %s\n", nodeId(r.Component))
+	}
+	for _, e := range dependencyEdges(results) {
+		fmt.Fprintf(w, "  %s --> %s\n", nodeId(e[0]), nodeId(e[1]))
+	}
+}
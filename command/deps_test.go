@@ -0,0 +1,99 @@
+package command
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ForceCLI/force/lib"
+)
+
+func fakeDependencyLookup(graph map[string][]lib.MetadataComponent) lib.DependencyLookup {
+	return func(c lib.MetadataComponent) ([]lib.MetadataComponent, error) {
+		return graph[c.String()], nil
+	}
+}
+
+func TestRenderDependencies(t *testing.T) {
+	class := lib.MetadataComponent{Type: "ApexClass", Name: "Invoice"}
+	util := lib.MetadataComponent{Type: "ApexClass", Name: "Util"}
+	object := lib.MetadataComponent{Type: "CustomObject", Name: "Invoice__c"}
+	trigger := lib.MetadataComponent{Type: "ApexTrigger", Name: "InvoiceTrigger"}
+
+	uses := fakeDependencyLookup(map[string][]lib.MetadataComponent{
+		class.String(): {util, object},
+		util.String():  {class},
+	})
+	usedBy := fakeDependencyLookup(map[string][]lib.MetadataComponent{
+		class.String(): {trigger},
+	})
+	usesTree, err := lib.BuildDependencyTree(class, 0, uses)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	usedByTree, err := lib.BuildDependencyTree(class, 0, usedBy)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	results := []componentDependencies{{Component: class, Uses: usesTree, UsedBy: usedByTree}}
+
+	var console bytes.Buffer
+	if err := renderDependencies(&console, results, "console"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `ApexClass:Invoice
+  Uses:
+    ├── ApexClass:Util
+    │   └── ApexClass:Invoice (cycle)
+    └── CustomObject:Invoice__c
+  Used By:
+    └── ApexTrigger:InvoiceTrigger
+`
+	if console.String() != expected {
+		t.Errorf("unexpected console output:\n%s", console.String())
+	}
+
+	var dot bytes.Buffer
+	if err := renderDependencies(&dot, results, "dot"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, edge := range []string{
+		`"ApexClass:Invoice" -> "ApexClass:Util";`,
+		`"ApexClass:Util" -> "ApexClass:Invoice";`,
+		`"ApexClass:Invoice" -> "CustomObject:Invoice__c";`,
+		`"ApexTrigger:InvoiceTrigger" -> "ApexClass:Invoice";`,
+	} {
+		if !strings.Contains(dot.String(), edge) {
+			t.Errorf("missing edge %s in dot output:\n%s", edge, dot.String())
+		}
+	}
+
+	var mermaid bytes.Buffer
+	if err := renderDependencies(&mermaid, results, "mermaid"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(mermaid.String(), `n3["ApexTrigger:InvoiceTrigger"] --> n0`) {
+		t.Errorf("unexpected mermaid output:\n%s", mermaid.String())
+	}
+
+	if err := renderDependencies(&bytes.Buffer{}, results, "xml"); err == nil {
+		t.Error("expected error for unsupported format")
+	}
+}
+
+func TestBuildDependencyTreeDepth(t *testing.T) {
+	a := lib.MetadataComponent{Type: "ApexClass", Name: "A"}
+	b := lib.MetadataComponent{Type: "ApexClass", Name: "B"}
+	c := lib.MetadataComponent{Type: "ApexClass", Name: "C"}
+	lookup := fakeDependencyLookup(map[string][]lib.MetadataComponent{
+		a.String(): {b},
+		b.String(): {c},
+	})
+	tree, err := lib.BuildDependencyTree(a, 1, lookup)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tree.Children) != 1 || len(tree.Children[0].Children) != 0 {
+		t.Errorf("expected only direct dependencies, got %+v", tree)
+	}
+}
@@ -38,6 +38,7 @@ Usage:
   force field delete <object> <field>
   force field type
   force field type <fieldtype>
+  force field usage <object>
   `,
 
 	Example: `
@@ -47,6 +48,7 @@ Usage:
   force field delete Todo__c Due
   force field type     # displays all the supported field types
   force field type email   # displays the required and optional attributes
+  force field usage Account   # displays the percentage of records with each field populated
 `,
 	DisableFlagsInUseLine: true,
 }
@@ -0,0 +1,87 @@
+package command
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+
+	. "github.com/ForceCLI/force/error"
+	. "github.com/ForceCLI/force/lib"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	fieldUsageCmd.Flags().BoolP("custom", "c", false, "only analyze custom fields")
+	fieldUsageCmd.Flags().BoolP("last-populated", "l", false, "find the most recently modified record with a value for each field.  runs a query per field")
+	fieldUsageCmd.Flags().StringP("format", "f", "table", "output format: table or csv")
+	setOutputFormats(fieldUsageCmd, "table", "csv")
+
+	fieldCmd.AddCommand(fieldUsageCmd)
+}
+
+var fieldUsageCmd = &cobra.Command{
+	Use:   "usage [flags] <object>",
+	Short: "Report the percentage of records with a value for each field",
+	Long: `
+Report the number and percentage of records with a value for each field of an
+object, sorted from least to most populated.  Checkbox fields are counted as
+populated when checked.  Fields that can't be counted in SOQL, such as long
+text areas, are listed last.
+
+The last modified date of each custom field's definition is included as a hint
+of whether the field is still in use.
+`,
+	Example: `
+  force field usage Account
+  force field usage Account --custom --last-populated -f csv > account-fields.csv
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var options FieldUsageOptions
+		options.CustomOnly, _ = cmd.Flags().GetBool("custom")
+		options.LastPopulated, _ = cmd.Flags().GetBool("last-populated")
+		format, _ := cmd.Flags().GetString("format")
+		if format != "table" && format != "csv" {
+			ErrorAndExit("Invalid format: %s", format)
+		}
+		usage, err := force.FieldUsage(args[0], options)
+		if err != nil {
+			ErrorAndExit(err.Error())
+		}
+		displayFieldUsage(usage, format)
+	},
+}
+
+func fieldUsageRow(u FieldUsage) []string {
+	populated, percent := "", "n/a"
+	if u.Countable {
+		populated = strconv.Itoa(u.Populated)
+		percent = fmt.Sprintf("%.1f%%", u.Percent())
+	}
+	return []string{u.Name, u.Type, populated, percent, u.LastPopulated, u.FieldLastModified}
+}
+
+func displayFieldUsage(usage []FieldUsage, format string) {
+	header := []string{"Field", "Type", "Populated", "Percent", "Last Populated", "Field Modified"}
+	if format == "csv" {
+		w := csv.NewWriter(os.Stdout)
+		w.Write(header)
+		for _, u := range usage {
+			w.Write(fieldUsageRow(u))
+		}
+		w.Flush()
+		return
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader(header)
+	table.SetAutoWrapText(false)
+	for _, u := range usage {
+		table.Append(fieldUsageRow(u))
+	}
+	if len(usage) > 0 {
+		table.SetFooter([]string{"", "", "", "", "Records", strconv.Itoa(usage[0].Total)})
+	}
+	table.Render()
+}
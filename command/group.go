@@ -0,0 +1,87 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	. "github.com/ForceCLI/force/error"
+	. "github.com/ForceCLI/force/lib"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	groupCmd.AddCommand(groupSetCmd)
+	groupCmd.AddCommand(groupListCmd)
+	groupCmd.AddCommand(groupRemoveCmd)
+	RootCmd.AddCommand(groupCmd)
+}
+
+var groupCmd = &cobra.Command{
+	Use:   "group",
+	Short: "Manage named groups of saved logins",
+	Long: `
+Manage named groups of saved logins.  A group can be passed to --account to
+target each of its members with commands that support multiple accounts, such
+as push, import, and schema snapshot.
+`,
+	Example: `
+  force group set qa qa1@example.com qa2@example.com uat
+  force -a qa push -t ApexClass
+  force group list
+  force group remove qa
+`,
+}
+
+var groupSetCmd = &cobra.Command{
+	Use:   "set <group> <account>...",
+	Short: "Create or replace a group of saved logins or aliases",
+	Args:  cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := SetGroup(args[0], args[1:]); err != nil {
+			ErrorAndExit(err.Error())
+		}
+		fmt.Printf("%s now contains %s\n", args[0], strings.Join(args[1:], ", "))
+	},
+}
+
+var groupListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List groups",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		groups := Groups()
+		if len(groups) == 0 {
+			fmt.Println("No groups")
+			return
+		}
+		var names []string
+		for group := range groups {
+			names = append(names, group)
+		}
+		sort.Strings(names)
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Group", "Accounts"})
+		table.SetAutoWrapText(false)
+		for _, group := range names {
+			table.Append([]string{group, strings.Join(groups[group], ", ")})
+		}
+		table.Render()
+	},
+}
+
+var groupRemoveCmd = &cobra.Command{
+	Use:   "remove <group>...",
+	Short: "Remove groups",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		for _, group := range args {
+			if err := RemoveGroup(group); err != nil {
+				ErrorAndExit(err.Error())
+			}
+			fmt.Printf("Removed %s\n", group)
+		}
+	},
+}
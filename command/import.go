@@ -18,7 +18,7 @@ func init() {
 	// Deploy options
 	importCmd.Flags().BoolP("rollbackonerror", "r", false, "roll back deployment on error")
 	importCmd.Flags().BoolP("runalltests", "t", false, "run all tests (equivalent to --testlevel RunAllTestsInOrg)")
-	importCmd.Flags().StringP("testlevel", "l", "NoTestRun", "test level")
+	importCmd.Flags().StringP("testlevel", "l", "NoTestRun", "test level (NoTestRun, RunSpecifiedTests, RunLocalTests, RunAllTestsInOrg, or RunRelevantTests)")
 	importCmd.Flags().BoolP("checkonly", "c", false, "check only deploy")
 	importCmd.Flags().BoolP("purgeondelete", "p", false, "purge metadata from org on delete")
 	importCmd.Flags().BoolP("allowmissingfiles", "m", false, "set allow missing files")
@@ -49,7 +49,9 @@ var importCmd = &cobra.Command{
   force import
   force import -directory=my_metadata -c -r -v
   force import -checkonly -runalltests
+  force import -a qa1@example.com -a qa2@example.com --reporttype junit
 `,
+	Annotations: map[string]string{multipleAccountsAnnotation: "true"},
 	Run: func(cmd *cobra.Command, args []string) {
 		options := getDeploymentOptions(cmd)
 		srcDir := sourceDir(cmd)
@@ -95,23 +97,11 @@ func sourceDir(cmd *cobra.Command) string {
 
 func runImport(root string, options ForceDeployOptions, displayOptions *deployOutputOptions, smartFlowVersion bool) {
 	_ = smartFlowVersion
-	files := make(ForceMetadataFiles)
 	if _, err := os.Stat(filepath.Join(root, "package.xml")); os.IsNotExist(err) {
 		ErrorAndExit(" \n" + filepath.Join(root, "package.xml") + "\ndoes not exist")
 	}
 
-	err := filepath.Walk(root, func(path string, f os.FileInfo, err error) error {
-		if f.Mode().IsRegular() {
-			if f.Name() != ".DS_Store" {
-				data, err := ioutil.ReadFile(path)
-				if err != nil {
-					ErrorAndExit(err.Error())
-				}
-				files[strings.Replace(path, fmt.Sprintf("%s%s", root, string(os.PathSeparator)), "", -1)] = data
-			}
-		}
-		return nil
-	})
+	files, err := metadataFilesFromDirectory(root)
 	if err != nil {
 		ErrorAndExit(err.Error())
 	}
@@ -123,7 +113,7 @@ func runImport(root string, options ForceDeployOptions, displayOptions *deployOu
 			ErrorAndExit(err2.Error())
 		}
 	}
-	err = deploy(force, files, &options, displayOptions)
+	err = deployToAccounts(files, &options, displayOptions)
 	if err == nil && displayOptions.reportFormat == "text" && !displayOptions.quiet {
 		fmt.Printf("Imported from %s\n", root)
 	}
@@ -131,3 +121,22 @@ func runImport(root string, options ForceDeployOptions, displayOptions *deployOu
 		ErrorAndExit(err.Error())
 	}
 }
+
+// metadataFilesFromDirectory reads all of the files in a metadata directory
+func metadataFilesFromDirectory(root string) (ForceMetadataFiles, error) {
+	files := make(ForceMetadataFiles)
+	err := filepath.Walk(root, func(path string, f os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if f.Mode().IsRegular() && f.Name() != ".DS_Store" {
+			data, err := ioutil.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			files[strings.Replace(path, fmt.Sprintf("%s%s", root, string(os.PathSeparator)), "", -1)] = data
+		}
+		return nil
+	})
+	return files, err
+}
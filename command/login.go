@@ -18,13 +18,19 @@ func init() {
 	loginCmd.Flags().StringP("api-version", "v", "", "API version to use")
 	loginCmd.Flags().String("connected-app-client-id", "", "Client Id (aka Consumer Key) to use instead of default")
 	loginCmd.Flags().StringP("key", "k", "", "JWT signing key filename")
-	loginCmd.Flags().String("connected-app-client-secret", "", "Client Secret (aka Consumer Secret) for Client Credentials flow")
+	loginCmd.Flags().String("connected-app-client-secret", "", "Client Secret (aka Consumer Secret) for Client Credentials flow, or for the web server flow with --pkce")
+	loginCmd.Flags().Bool("pkce", false, "log in using the OAuth web server flow with PKCE instead of the implicit flow")
+	loginCmd.Flags().Int("callback-port", DefaultCallbackPort, "local port for the OAuth callback.  the connected app's callback URL must be http://localhost:<port>/oauth/callback")
+	loginCmd.Flags().Bool("device", false, "log in using the OAuth device flow, approving the login from a browser on another device")
+	loginCmd.Flags().String("sfdx-url-file", "", "log in using the SFDX auth URL (force://<clientId>:<clientSecret>:<refreshToken>@<instance>) in a file, or the JSON output of \"sf org display --verbose --json\"")
 	loginCmd.Flags().BoolP("skip", "s", false, "skip login if already authenticated and only save token (useful with SSO)")
 	loginCmd.Flags().StringP("instance", "i", "", `Defaults to 'login' or last
 logged in system. non-production server to login to (values are 'pre',
 'test', or full instance url`)
 
 	scratchCmd.Flags().String("username", "", "username for scratch org user")
+	scratchCmd.Flags().StringP("definition", "f", "", "path to scratch org definition file (e.g. config/project-scratch-def.json)")
+	scratchCmd.Flags().IntP("duration", "d", 0, "days until the scratch org expires (1-30)")
 
 	loginCmd.AddCommand(scratchCmd)
 	RootCmd.AddCommand(loginCmd)
@@ -33,9 +39,35 @@ logged in system. non-production server to login to (values are 'pre',
 var scratchCmd = &cobra.Command{
 	Use:   "scratch",
 	Short: "Create scratch org and log in",
+	Long: `Create a scratch org from the active Dev Hub org and log in.
+
+An sfdx-style scratch org definition file can be used to set the edition,
+features, org name, language, and duration of the org.  Any settings in the
+definition are deployed to the org after it's created.`,
+	Example: `
+    force login scratch
+    force login scratch --definition config/project-scratch-def.json --duration 7
+`,
+	Args: cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		scratchUser, _ := cmd.Flags().GetString("username")
-		scratchLogin(scratchUser)
+		var definition ScratchOrgDefinition
+		if definitionFile, _ := cmd.Flags().GetString("definition"); definitionFile != "" {
+			data, err := os.ReadFile(definitionFile)
+			if err != nil {
+				ErrorAndExit(err.Error())
+			}
+			definition, err = ParseScratchOrgDefinition(data)
+			if err != nil {
+				ErrorAndExit(err.Error())
+			}
+		}
+		if scratchUser, _ := cmd.Flags().GetString("username"); scratchUser != "" {
+			definition.Username = scratchUser
+		}
+		if duration, _ := cmd.Flags().GetInt("duration"); duration > 0 {
+			definition.DurationDays = duration
+		}
+		scratchLogin(definition)
 	},
 }
 
@@ -44,7 +76,23 @@ var loginCmd = &cobra.Command{
 	Short: "Log into Salesforce and store a session token",
 	Long: `Log into Salesforce and store a session token.  By default, OAuth is
 used and a refresh token will be stored as well.  The refresh token is used
-to get a new session token automatically when needed.`,
+to get a new session token automatically when needed.
+
+On machines without a local browser, such as build servers reached over SSH,
+use --device to log in with the OAuth device flow.  A code is displayed to be
+entered at the verification URL from a browser on any device.  The connected
+app must have device flow enabled.
+
+Use --pkce to log in with the OAuth web server (authorization code) flow and
+PKCE instead of the implicit flow, for connected apps that don't allow the
+implicit flow.  If the connected app requires it, pass the client secret with
+--connected-app-client-secret; it's stored with the session so that it can be
+used when refreshing.  Refresh tokens rotated by the connected app are saved
+when the session is refreshed.
+
+Use --sfdx-url-file to log in with an SFDX auth URL, such as one exported
+with "force logins export-url" or "sf org display --verbose".  This is useful
+for logging in from CI using a stored secret.`,
 	Example: `
     force login
     force login -i test
@@ -55,6 +103,9 @@ to get a new session token automatically when needed.`,
     force login -i my-domain.my.salesforce.com -s[kipLogin]
     force login --connected-app-client-id <my-consumer-key> -u user@example.com -key jwt.key
     force login --connected-app-client-id <my-consumer-key> --connected-app-client-secret <my-consumer-secret>
+    force login --device
+    force login --pkce --connected-app-client-id <my-consumer-key> --callback-port 1717
+    force login --sfdx-url-file auth-url.txt
     force login scratch
 `,
 	Args: cobra.MaximumNArgs(0),
@@ -62,12 +113,24 @@ to get a new session token automatically when needed.`,
 		if connectedAppClientId, _ := cmd.Flags().GetString("connected-app-client-id"); connectedAppClientId != "" {
 			ClientId = connectedAppClientId
 		}
+		if callbackPort, _ := cmd.Flags().GetInt("callback-port"); callbackPort != 0 {
+			CallbackPort = callbackPort
+		}
 		endpoint := getEndpoint(cmd)
 		selectApiVersion(cmd)
 		username, _ := cmd.Flags().GetString("user")
 		keyFile, _ := cmd.Flags().GetString("key")
 		clientSecret, _ := cmd.Flags().GetString("connected-app-client-secret")
+		device, _ := cmd.Flags().GetBool("device")
+		pkce, _ := cmd.Flags().GetBool("pkce")
+		sfdxUrlFile, _ := cmd.Flags().GetString("sfdx-url-file")
 		switch {
+		case sfdxUrlFile != "":
+			sfdxUrlLogin(sfdxUrlFile)
+		case device:
+			deviceLogin(endpoint)
+		case pkce:
+			pkceLogin(endpoint, clientSecret)
 		case clientSecret != "":
 			clientCredentialsLogin(endpoint, ClientId, clientSecret)
 		case username == "":
@@ -82,11 +145,14 @@ to get a new session token automatically when needed.`,
 	},
 }
 
-func scratchLogin(scratchUser string) {
-	_, err := ForceScratchCreateLoginAndSave(scratchUser, os.Stderr)
+func scratchLogin(definition ScratchOrgDefinition) {
+	_, expirationDate, err := ForceScratchCreateFromDefinitionLoginAndSave(definition, os.Stderr)
 	if err != nil {
 		ErrorAndExit(err.Error())
 	}
+	if expirationDate != "" {
+		fmt.Fprintf(os.Stderr, "Scratch org expires on %s\n", expirationDate)
+	}
 }
 
 func oauthLogin(endpoint string, skipLogin bool) {
@@ -101,6 +167,31 @@ func oauthLogin(endpoint string, skipLogin bool) {
 	}
 }
 
+func deviceLogin(endpoint string) {
+	_, err := ForceLoginAtEndpointAndSaveDevice(endpoint, os.Stderr)
+	if err != nil {
+		ErrorAndExit(err.Error())
+	}
+}
+
+func pkceLogin(endpoint string, clientSecret string) {
+	_, err := ForceLoginAtEndpointAndSavePKCE(endpoint, clientSecret, os.Stderr)
+	if err != nil {
+		ErrorAndExit(err.Error())
+	}
+}
+
+func sfdxUrlLogin(filename string) {
+	authUrl, err := ReadSfdxAuthUrlFile(filename)
+	if err != nil {
+		ErrorAndExit(err.Error())
+	}
+	_, err = ForceLoginAndSaveSfdxAuthUrl(authUrl, os.Stderr)
+	if err != nil {
+		ErrorAndExit(err.Error())
+	}
+}
+
 func jwtLogin(endpoint, username, keyfile string) {
 	assertion, err := JwtAssertionForEndpoint(endpoint, username, keyfile, ClientId)
 	if err != nil {
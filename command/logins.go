@@ -1,13 +1,12 @@
 package command
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"strings"
 	"text/tabwriter"
 
-	. "github.com/ForceCLI/force/config"
+	. "github.com/ForceCLI/force/error"
 	. "github.com/ForceCLI/force/lib"
 	"github.com/spf13/cobra"
 )
@@ -15,6 +14,11 @@ import (
 func init() {
 	loginsCmd.Flags().StringP("org-id", "o", "", "filter by org id")
 	loginsCmd.Flags().StringP("user-id", "i", "", "filter by user id")
+	loginsCmd.Flags().Bool("check", false, "validate each login, refreshing sessions if needed")
+	loginsCmd.Flags().Bool("prune", false, "remove logins that are expired or invalid.  implies --check")
+	loginsCmd.AddCommand(loginsEncryptCmd)
+	loginsCmd.AddCommand(loginsDecryptCmd)
+	loginsCmd.AddCommand(loginsExportUrlCmd)
 	RootCmd.AddCommand(loginsCmd)
 }
 
@@ -23,14 +27,112 @@ type accountFilter func(ForceSession) bool
 var loginsCmd = &cobra.Command{
 	Use:   "logins",
 	Short: "List force.com logins used",
+	Long: `
+List saved logins.  With --check, each login is validated by requesting the
+user's info, refreshing the session if needed, and logins that are expired or
+invalid are marked.  With --prune, they are also removed.
+`,
 	Example: `
   force logins
+  force logins --check
+  force logins --prune
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		check, _ := cmd.Flags().GetBool("check")
+		prune, _ := cmd.Flags().GetBool("prune")
+		runLogins(filters(cmd), check || prune, prune)
+	},
+}
+
+var loginsEncryptCmd = &cobra.Command{
+	Use:   "encrypt",
+	Short: "Encrypt saved logins",
+	Long: fmt.Sprintf(`
+Encrypt the access and refresh tokens of saved logins.  The encryption key is
+derived from the passphrase in %[1]s, or from the contents of
+the file named by %[2]s.
+
+Once encrypted, the passphrase must be set to use the saved logins, and new
+logins are saved encrypted while it's set.
+`, CredentialsPassphraseEnv, CredentialsKeyFileEnv),
+	Example: fmt.Sprintf(`
+  %s=~/.force-key force logins encrypt
+`, CredentialsKeyFileEnv),
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		accounts, err := EncryptLogins()
+		reportLoginsRewritten(accounts, "Encrypted")
+		if err != nil {
+			ErrorAndExit(err.Error())
+		}
+	},
+}
+
+var loginsDecryptCmd = &cobra.Command{
+	Use:   "decrypt",
+	Short: "Decrypt saved logins",
+	Long: fmt.Sprintf(`
+Decrypt saved logins encrypted with "force logins encrypt", storing them as
+plain JSON.  Unset %[1]s and %[2]s afterwards,
+or new logins will continue to be saved encrypted.
+`, CredentialsPassphraseEnv, CredentialsKeyFileEnv),
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		accounts, err := DecryptLogins()
+		reportLoginsRewritten(accounts, "Decrypted")
+		if err != nil {
+			ErrorAndExit(err.Error())
+		}
+	},
+}
+
+var loginsExportUrlCmd = &cobra.Command{
+	Use:   "export-url [account]",
+	Short: "Export a saved login as an SFDX auth URL",
+	Long: `
+Export a saved login as an SFDX auth URL in the form
+force://<clientId>:<clientSecret>:<refreshToken>@<instance>.  The URL can be
+used with "force login --sfdx-url-file" or "sf org login sfdx-url", or stored
+in a CI secret.  Defaults to the active login.
+
+The URL contains the refresh token, so treat it like a password.
+`,
+	Example: `
+  force logins export-url user@example.com > auth-url.txt
 `,
+	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		runLogins(filters(cmd))
+		var account string
+		if len(args) > 0 {
+			account = ResolveAccount(args[0])
+		} else {
+			active, err := ActiveLogin()
+			if err != nil {
+				ErrorAndExit("No active login")
+			}
+			account = strings.TrimSpace(active)
+		}
+		creds, err := LoadLogin(account)
+		if err != nil {
+			ErrorAndExit("Could not load %s: %s", account, err.Error())
+		}
+		authUrl, err := SfdxAuthUrlForSession(creds)
+		if err != nil {
+			ErrorAndExit(err.Error())
+		}
+		fmt.Println(authUrl)
 	},
 }
 
+func reportLoginsRewritten(accounts []string, action string) {
+	for _, account := range accounts {
+		fmt.Printf("%s %s\n", action, account)
+	}
+	if len(accounts) == 0 {
+		fmt.Println("No logins changed")
+	}
+}
+
 func filters(cmd *cobra.Command) []accountFilter {
 	var filters []accountFilter
 	orgId, _ := cmd.Flags().GetString("org-id")
@@ -61,40 +163,74 @@ func filters(cmd *cobra.Command) []accountFilter {
 	return filters
 }
 
-func runLogins(filters []accountFilter) {
+func runLogins(filters []accountFilter, check bool, prune bool) {
 	active, _ := ActiveLogin()
-	accounts, _ := Config.List("accounts")
+	accounts := SavedLogins()
 	if len(accounts) == 0 {
 		fmt.Println("no logins")
 		return
 	}
-	w := new(tabwriter.Writer)
-	w.Init(os.Stdout, 1, 0, 1, ' ', 0)
 
+	var listed []string
+	credentials := make(map[string]ForceSession)
+	loadErrors := make(map[string]error)
 ACCOUNTS:
 	for _, account := range accounts {
-		if !strings.HasPrefix(account, ".") {
-			var creds ForceSession
-			data, err := Config.Load("accounts", account)
-			json.Unmarshal([]byte(data), &creds)
-			if err != nil {
-				return
-			}
-			for _, f := range filters {
-				if !f(creds) {
-					continue ACCOUNTS
-				}
+		creds, err := LoadLogin(account)
+		if err != nil && len(filters) > 0 {
+			continue
+		}
+		for _, f := range filters {
+			if !f(creds) {
+				continue ACCOUNTS
 			}
+		}
+		listed = append(listed, account)
+		credentials[account] = creds
+		loadErrors[account] = err
+	}
 
-			var banner = fmt.Sprintf("\t%s", creds.InstanceUrl)
-			if account == active {
-				account = fmt.Sprintf("\x1b[31;1m%s (active)\x1b[0m", account)
-			} else {
-				account = fmt.Sprintf("%s \x1b[31;1m\x1b[0m", account)
+	checks := make(map[string]LoginCheck)
+	if check {
+		for _, c := range CheckLogins(listed) {
+			checks[c.Account] = c
+		}
+	}
+
+	w := new(tabwriter.Writer)
+	w.Init(os.Stdout, 1, 0, 1, ' ', 0)
+	for _, account := range listed {
+		var banner = fmt.Sprintf("\t%s", credentials[account].InstanceUrl)
+		switch err := loadErrors[account]; {
+		case err == CredentialsPassphraseRequiredError:
+			banner = "\t(encrypted)"
+		case err != nil:
+			banner = "\t(invalid)"
+		}
+		if c, ok := checks[account]; ok {
+			banner += "\t" + c.Status
+			if c.Prunable() && prune {
+				banner += " (removed)"
 			}
-			fmt.Fprintln(w, fmt.Sprintf("%s%s", account, banner))
 		}
+		name := account
+		if account == active {
+			name = fmt.Sprintf("\x1b[31;1m%s (active)\x1b[0m", account)
+		} else {
+			name = fmt.Sprintf("%s \x1b[31;1m\x1b[0m", account)
+		}
+		fmt.Fprintln(w, fmt.Sprintf("%s%s", name, banner))
 	}
 	fmt.Fprintln(w)
 	w.Flush()
+
+	if prune {
+		for _, account := range listed {
+			if c := checks[account]; c.Prunable() {
+				if err := DeleteLogin(account); err != nil {
+					ErrorAndExit("Could not remove %s: %s", account, err.Error())
+				}
+			}
+		}
+	}
 }
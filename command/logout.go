@@ -2,6 +2,7 @@ package command
 
 import (
 	"fmt"
+	"os"
 	"os/exec"
 	"runtime"
 
@@ -16,8 +17,16 @@ func init() {
 }
 
 var logoutCmd = &cobra.Command{
-	Use:                   "logout",
-	Short:                 "Log out from Force.com",
+	Use:   "logout",
+	Short: "Log out from Force.com",
+	Long: `
+Log out of the active login, revoking its access and refresh tokens and
+removing it from the saved logins.  If the tokens can't be revoked, e.g.
+because the org is unreachable, the login is still removed.
+
+Tokens of logins from SFDX or imported with "force login --sfdx-url-file"
+aren't revoked since they're shared with other tools.
+`,
 	Args:                  cobra.MaximumNArgs(0),
 	DisableFlagsInUseLine: true,
 	Run: func(cmd *cobra.Command, args []string) {
@@ -31,11 +40,10 @@ func runLogout() {
 		ErrorAndExit("No logins, so a username cannot be assumed.")
 	}
 	username := force.Credentials.UserInfo.UserName
-	Config.Delete("accounts", username)
-	if active, _ := Config.Load("current", "account"); active == username {
-		Config.Delete("current", "account")
-		SetActiveLoginDefault()
+	if err := force.RevokeSession(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", err.Error())
 	}
+	DeleteLogin(username)
 	if runtime.GOOS == "windows" {
 		cmd := exec.Command("title", account)
 		cmd.Run()
@@ -0,0 +1,207 @@
+package command
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	. "github.com/ForceCLI/force/lib"
+	"github.com/ForceCLI/force/lib/junit"
+	"github.com/olekukonko/tablewriter"
+)
+
+type accountDeployResult struct {
+	account  string
+	result   ForceCheckDeploymentStatusResult
+	duration time.Duration
+	err      error
+}
+
+func (r accountDeployResult) status() string {
+	switch {
+	case r.err != nil:
+		return "Error"
+	case r.result.Status != "":
+		return r.result.Status
+	case r.result.Success:
+		return "Succeeded"
+	}
+	return "Failed"
+}
+
+func (r accountDeployResult) failed() bool {
+	return r.err != nil || !r.result.Success || r.result.HasComponentFailures() || r.result.HasTestFailures()
+}
+
+// deployToAccounts deploys files to the active account, or to each account
+// passed with --account if there are more than one.
+func deployToAccounts(files ForceMetadataFiles, deployOptions *ForceDeployOptions, outputOptions *deployOutputOptions) error {
+	if len(accountNames) <= 1 {
+		return deploy(force, files, deployOptions, outputOptions)
+	}
+	return deployToMultipleAccounts(accountNames, files, deployOptions, outputOptions)
+}
+
+// deployToMultipleAccounts builds the zip file once, then deploys it to each
+// account in parallel using a separate session for each.
+func deployToMultipleAccounts(accounts []string, files ForceMetadataFiles, deployOptions *ForceDeployOptions, outputOptions *deployOutputOptions) error {
+	if outputOptions.interactive {
+		return errors.New("Interactive mode is not supported when deploying to multiple accounts")
+	}
+	zipfile, err := force.Metadata.MakeZipWithOptions(files, *deployOptions)
+	if err != nil {
+		return fmt.Errorf("Could not create zip file: %w", err)
+	}
+
+	results := make([]accountDeployResult, len(accounts))
+	forces := loadAccountSessions(accounts, results)
+	var wg sync.WaitGroup
+	for i, account := range accounts {
+		wg.Add(1)
+		go func(i int, account string) {
+			defer wg.Done()
+			if results[i].err == nil {
+				results[i] = deployZipToAccount(forces[i], account, zipfile, files, *deployOptions)
+			}
+			if !outputOptions.quiet {
+				fmt.Fprintf(os.Stderr, "%s: %s\n", account, results[i].status())
+			}
+		}(i, account)
+	}
+	wg.Wait()
+
+	if outputOptions.reportFormat == "junit" {
+		output, err := accountDeployResultsToJunit(results)
+		if err != nil {
+			return fmt.Errorf("Failed to generate output: %w", err)
+		}
+		fmt.Println(output)
+	} else if !outputOptions.quiet {
+		renderAccountDeployResults(os.Stdout, results)
+	}
+
+	failed := 0
+	testFailuresOnly := true
+	for _, r := range results {
+		if r.failed() {
+			failed++
+			if r.err != nil || !r.result.HasTestFailures() || r.result.HasComponentFailures() {
+				testFailuresOnly = false
+			}
+		}
+	}
+	if failed == 0 {
+		return nil
+	}
+	if testFailuresOnly {
+		return fmt.Errorf("Deploy unsuccessful for %d of %d accounts: %w", failed, len(results), testFailureError)
+	}
+	return fmt.Errorf("Deploy unsuccessful for %d of %d accounts", failed, len(results))
+}
+
+// loadAccountSessions loads the session for each account, recording any error
+// in the account's result.  Loading a session sets the global API version to
+// the one saved with the login, so the sessions are loaded one at a time, each
+// capturing its own API version, before deploying concurrently.
+func loadAccountSessions(accounts []string, results []accountDeployResult) []*Force {
+	defaultVersion := ApiVersionNumber()
+	defer SetApiVersion(defaultVersion)
+	forces := make([]*Force, len(accounts))
+	for i, account := range accounts {
+		results[i].account = account
+		SetApiVersion(defaultVersion)
+		forces[i], results[i].err = GetForce(account)
+	}
+	return forces
+}
+
+func deployZipToAccount(f *Force, account string, zipfile []byte, files ForceMetadataFiles, deployOptions ForceDeployOptions) accountDeployResult {
+	r := accountDeployResult{account: account}
+	if deployOptions.TestLevel == runRelevantTests {
+		// Don't interleave the selected tests for each account
+		quiet := defaultDeployOutputOptions()
+		quiet.quiet = true
+		if r.err = selectRelevantTests(f, files, &deployOptions, quiet); r.err != nil {
+			return r
+		}
+	}
+	startTime := time.Now()
+	deployId, err := f.Metadata.StartDeployZipFile(zipfile, deployOptions)
+	if err != nil {
+		r.err = err
+		return r
+	}
+	stopDeployUponSignal(f, deployId)
+	r.result, r.err = monitorDeploy(f, deployId, quietLogger{})
+	deployFinished(deployId)
+	r.duration = time.Since(startTime)
+	return r
+}
+
+func renderAccountDeployResults(w io.Writer, results []accountDeployResult) {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Account", "Status", "Components", "Component Errors", "Tests", "Test Failures", "Duration"})
+	table.SetAutoWrapText(false)
+	for _, r := range results {
+		table.Append([]string{
+			r.account,
+			r.status(),
+			fmt.Sprintf("%d/%d", r.result.NumberComponentsDeployed, r.result.NumberComponentsTotal),
+			strconv.Itoa(r.result.NumberComponentErrors),
+			fmt.Sprintf("%d/%d", r.result.NumberTestsCompleted, r.result.NumberTestsTotal),
+			strconv.Itoa(len(r.result.Details.RunTestResult.TestFailures)),
+			r.duration.Round(time.Second).String(),
+		})
+	}
+	table.Render()
+
+	for _, r := range results {
+		if !r.failed() {
+			continue
+		}
+		fmt.Fprintf(w, "\n%s\n", r.account)
+		if r.err != nil {
+			fmt.Fprintf(w, "  %s\n", r.err.Error())
+			continue
+		}
+		for _, problem := range r.result.Details.ComponentFailures {
+			fmt.Fprintf(w, "  \"%s\", line %d: %s %s\n", problem.FullName, problem.LineNumber, problem.ProblemType, problem.Problem)
+		}
+		for _, failure := range r.result.Details.RunTestResult.TestFailures {
+			fmt.Fprintf(w, "  [FAIL]  %s::%s: %s\n", failure.Name, failure.MethodName, failure.Message)
+		}
+		if r.result.ErrorMessage != "" {
+			fmt.Fprintf(w, "  %s\n", r.result.ErrorMessage)
+		}
+	}
+}
+
+// accountDeployResultsToJunit combines the results into a JUnit report with a
+// test suite for each account.
+func accountDeployResultsToJunit(results []accountDeployResult) (string, error) {
+	var suites junit.TestSuites
+	for _, r := range results {
+		suite := r.result.ToJunitTestSuite(r.account, r.duration.Seconds())
+		if r.err != nil {
+			e := junit.TestCase{
+				Name:      "deploy",
+				Classname: r.account,
+			}
+			e.Errors = append(e.Errors, &junit.Error{Message: r.err.Error()})
+			suite.TestCases = append(suite.TestCases, &e)
+			suite.Tests++
+			suite.Errors++
+		}
+		suites.Suites = append(suites.Suites, suite)
+	}
+	output, err := xml.MarshalIndent(suites, "", "   ")
+	if err != nil {
+		return "", err
+	}
+	return xml.Header + string(output), nil
+}
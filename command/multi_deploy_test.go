@@ -0,0 +1,118 @@
+package command
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ForceCLI/force/lib"
+)
+
+func TestAccountDeployResults(t *testing.T) {
+	var passed lib.ForceCheckDeploymentStatusResult
+	passed.Success = true
+	passed.Status = "Succeeded"
+	passed.NumberComponentsTotal = 2
+	passed.NumberComponentsDeployed = 2
+
+	var failed lib.ForceCheckDeploymentStatusResult
+	failed.Status = "Failed"
+	failed.Details.RunTestResult.TestFailures = []lib.TestFailure{{
+		Name:       "InvoiceTest",
+		MethodName: "testTotal",
+		Message:    "Assertion Failed",
+	}}
+
+	results := []accountDeployResult{
+		{account: "qa1@example.com", result: passed},
+		{account: "qa2@example.com", result: failed},
+		{account: "qa3@example.com", err: errors.New("no such account")},
+	}
+
+	var console bytes.Buffer
+	renderAccountDeployResults(&console, results)
+	for _, expected := range []string{
+		"qa1@example.com",
+		"[FAIL]  InvoiceTest::testTotal: Assertion Failed",
+		"no such account",
+	} {
+		if !strings.Contains(console.String(), expected) {
+			t.Errorf("missing %q in output:\n%s", expected, console.String())
+		}
+	}
+	if strings.Contains(console.String(), "\nqa1@example.com\n") {
+		t.Errorf("successful account should not list failures:\n%s", console.String())
+	}
+
+	report, err := accountDeployResultsToJunit(results)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Count(report, "<testsuite ") != 3 {
+		t.Errorf("expected a test suite per account:\n%s", report)
+	}
+	if !strings.Contains(report, `<testsuite name="qa3@example.com" tests="1" errors="1"`) {
+		t.Errorf("expected error for account that could not deploy:\n%s", report)
+	}
+}
+
+func TestRunningDeploysShareSignalHandler(t *testing.T) {
+	a := lib.NewForce(&lib.ForceSession{})
+	b := lib.NewForce(&lib.ForceSession{})
+	stopDeployUponSignal(a, "0Af000000000001")
+	stopDeployUponSignal(b, "0Af000000000002")
+	runningDeploys.Lock()
+	running := len(runningDeploys.deploys)
+	runningDeploys.Unlock()
+	if running != 2 {
+		t.Errorf("expected both deploys to be cancelled upon a signal, got %d", running)
+	}
+
+	deployFinished("0Af000000000001")
+	deployFinished("0Af000000000002")
+	runningDeploys.Lock()
+	running = len(runningDeploys.deploys)
+	runningDeploys.Unlock()
+	if running != 0 {
+		t.Errorf("expected finished deploys to be removed, got %d", running)
+	}
+}
+
+func TestCancelRunningDeploysResetsAfterDeploysFinish(t *testing.T) {
+	f := lib.NewForce(&lib.ForceSession{InstanceUrl: "http://127.0.0.1:0"})
+	if cancelRunningDeploys() {
+		t.Error("expected no deploys to cancel")
+	}
+
+	stopDeployUponSignal(f, "0Af000000000003")
+	if !cancelRunningDeploys() {
+		t.Error("expected the running deploy to be cancelled")
+	}
+	if cancelRunningDeploys() {
+		t.Error("expected a second signal to exit")
+	}
+	deployFinished("0Af000000000003")
+
+	// A signal during a later deploy cancels it
+	stopDeployUponSignal(f, "0Af000000000004")
+	defer deployFinished("0Af000000000004")
+	if !cancelRunningDeploys() {
+		t.Error("expected a later deploy to be cancelled")
+	}
+}
+
+func TestLoadAccountSessionsRestoresApiVersion(t *testing.T) {
+	original := lib.ApiVersionNumber()
+	defer lib.SetApiVersion(original)
+	lib.SetApiVersion("50.0")
+
+	results := make([]accountDeployResult, 1)
+	loadAccountSessions([]string{"missing@example.com"}, results)
+	if results[0].account != "missing@example.com" || results[0].err == nil {
+		t.Errorf("expected an error for a missing login, got %+v", results[0])
+	}
+	if lib.ApiVersionNumber() != "50.0" {
+		t.Errorf("expected API version 50.0, got %s", lib.ApiVersionNumber())
+	}
+}
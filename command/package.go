@@ -1,32 +1,87 @@
 package command
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"strings"
+	"time"
 
 	. "github.com/ForceCLI/force/error"
+	. "github.com/ForceCLI/force/lib"
+	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
 )
 
 func init() {
 	packageInstallCmd.Flags().BoolP("activate", "A", false, "keep the isActive state of any Remote Site Settings (RSS) and Content Security Policies (CSP) in package")
 	packageInstallCmd.Flags().StringP("password", "p", "", "password for package")
+	packageInstallCmd.Flags().StringP("security-type", "s", "AdminsOnly", "access to grant when installing by id: AllUsers or AdminsOnly")
+	packageInstallCmd.Flags().StringP("upgrade-type", "u", "Mixed", "how to handle components removed from the package when upgrading by id: Mixed, DeprecateOnly, or Delete")
+	packageInstallCmd.Flags().Bool("wait", true, "wait for installation by id to complete")
+	packageInstallCmd.Flags().Duration("timeout", 30*time.Minute, "maximum time to wait for installation by id to complete.  0 waits until it completes")
+
+	packageUninstallCmd.Flags().Bool("wait", true, "wait for the uninstall to complete")
+	packageUninstallCmd.Flags().Duration("timeout", 30*time.Minute, "maximum time to wait for the uninstall to complete.  0 waits until it completes")
 
 	packageCmd.AddCommand(packageInstallCmd)
+	packageCmd.AddCommand(packageListCmd)
+	packageCmd.AddCommand(packageUninstallCmd)
 	RootCmd.AddCommand(packageCmd)
 }
 
 var packageCmd = &cobra.Command{
 	Use:   "package",
-	Short: "Manage installed packages",
+	Short: "Manage packages",
 }
 
 var packageInstallCmd = &cobra.Command{
-	Use:   "install [flags] <namespace> <version>",
-	Short: "Installed packages",
-	Args:  cobra.RangeArgs(2, 3),
+	Use:   "install [flags] (<namespace> <version> | <version id>)",
+	Short: "Install a package",
+	Long: `
+Install a package by namespace and version number, or by subscriber package
+version (04t) id.
+
+Installing by id uses a PackageInstallRequest, which reports the progress of
+the install and supports the security type and upgrade type options.
+`,
+	Example: `
+  force package install mynamespace 1.2
+  force package install 04t000000000000 --security-type AllUsers
+  force package install 04t000000000000 -p secret --upgrade-type DeprecateOnly
+`,
+	Args: cobra.RangeArgs(1, 3),
 	Run: func(cmd *cobra.Command, args []string) {
 		activateRSS, _ := cmd.Flags().GetBool("activate")
 		password, _ := cmd.Flags().GetString("password")
+		if len(args) == 1 {
+			if !strings.HasPrefix(args[0], "04t") {
+				ErrorAndExit("Specify a namespace and version, or a package version id (04t)")
+			}
+			options := PackageInstallOptions{Password: password, EnableRss: activateRSS}
+			securityType, _ := cmd.Flags().GetString("security-type")
+			upgradeType, _ := cmd.Flags().GetString("upgrade-type")
+			var err error
+			if options.SecurityType, err = packageSecurityType(securityType); err != nil {
+				ErrorAndExit(err.Error())
+			}
+			if options.UpgradeType, err = packageUpgradeType(upgradeType); err != nil {
+				ErrorAndExit(err.Error())
+			}
+			wait, _ := cmd.Flags().GetBool("wait")
+			if !wait {
+				requestId, err := force.StartPackageInstall(args[0], options)
+				if err != nil {
+					ErrorAndExit(err.Error())
+				}
+				fmt.Printf("Package install request %s started\n", requestId)
+				return
+			}
+			ctx, cancel := waitContext(cmd)
+			defer cancel()
+			installPackageVersion(ctx, force, args[0], options)
+			return
+		}
 		packageNamespace := args[0]
 		version := args[1]
 		if len(args) > 2 {
@@ -37,9 +92,115 @@ var packageInstallCmd = &cobra.Command{
 	},
 }
 
+var packageListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List installed packages",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		packages, err := force.QueryInstalledPackages()
+		if err != nil {
+			ErrorAndExit(err.Error())
+		}
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Namespace", "Name", "Version", "Version Name", "Version Id"})
+		table.SetAutoWrapText(false)
+		for _, p := range packages {
+			table.Append([]string{
+				p.SubscriberPackage.NamespacePrefix,
+				p.SubscriberPackage.Name,
+				p.VersionNumber(),
+				p.SubscriberPackageVersion.Name,
+				p.SubscriberPackageVersion.Id,
+			})
+		}
+		table.Render()
+	},
+}
+
+var packageUninstallCmd = &cobra.Command{
+	Use:   "uninstall [flags] (<namespace> | <version id>)",
+	Short: "Uninstall a package",
+	Long: `
+Uninstall a package, specified by namespace or by the subscriber package
+version (04t) id of the installed version.
+`,
+	Example: `
+  force package uninstall mynamespace
+  force package uninstall 04t000000000000
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		wait, _ := cmd.Flags().GetBool("wait")
+		ctx, cancel := waitContext(cmd)
+		defer cancel()
+		runUninstallPackage(ctx, args[0], wait)
+	},
+}
+
+func packageSecurityType(securityType string) (string, error) {
+	switch strings.ToLower(securityType) {
+	case "allusers", "full":
+		return "Full", nil
+	case "adminsonly", "none":
+		return "None", nil
+	}
+	return "", fmt.Errorf("Invalid security type: %s", securityType)
+}
+
+func packageUpgradeType(upgradeType string) (string, error) {
+	switch strings.ToLower(upgradeType) {
+	case "mixed", "mixed-mode":
+		return "mixed-mode", nil
+	case "deprecateonly", "deprecate-only":
+		return "deprecate-only", nil
+	case "delete", "delete-only":
+		return "delete-only", nil
+	}
+	return "", fmt.Errorf("Invalid upgrade type: %s", upgradeType)
+}
+
 func runInstallPackage(packageNamespace string, version string, password string, activateRSS bool) {
 	if err := force.Metadata.InstallPackageWithRSS(packageNamespace, version, password, activateRSS); err != nil {
 		ErrorAndExit(err.Error())
 	}
 	fmt.Println("Package installed")
 }
+
+func runUninstallPackage(ctx context.Context, pkg string, wait bool) {
+	versionId := pkg
+	if !strings.HasPrefix(pkg, "04t") {
+		packages, err := force.QueryInstalledPackages()
+		if err != nil {
+			ErrorAndExit(err.Error())
+		}
+		versionId = ""
+		for _, p := range packages {
+			if strings.EqualFold(p.SubscriberPackage.NamespacePrefix, pkg) {
+				versionId = p.SubscriberPackageVersion.Id
+				break
+			}
+		}
+		if versionId == "" {
+			ErrorAndExit("No installed package with namespace %s", pkg)
+		}
+	}
+	requestId, err := force.StartPackageUninstall(versionId)
+	if err != nil {
+		ErrorAndExit(err.Error())
+	}
+	fmt.Printf("Package uninstall request %s started\n", requestId)
+	if !wait {
+		return
+	}
+	lastStatus := ""
+	err = force.WaitForPackageUninstall(ctx, requestId, 10*time.Second, func(status string) {
+		if status != lastStatus {
+			fmt.Printf("Status: %s\n", status)
+			lastStatus = status
+		}
+	})
+	if err != nil {
+		ErrorAndExit(err.Error())
+	}
+	fmt.Println("Package uninstalled")
+}
@@ -0,0 +1,278 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/ForceCLI/force/config"
+	. "github.com/ForceCLI/force/error"
+	. "github.com/ForceCLI/force/lib"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	packageVersionCreateCmd.Flags().StringP("version-number", "n", "", "version number in major.minor.patch.build format; build may be NEXT")
+	packageVersionCreateCmd.Flags().String("version-name", "", "name of the version")
+	packageVersionCreateCmd.Flags().String("version-description", "", "description of the version")
+	packageVersionCreateCmd.Flags().String("ancestor", "", "id of the ancestor package version (04t)")
+	packageVersionCreateCmd.Flags().StringP("tag", "t", "", "tag to associate with the version")
+	packageVersionCreateCmd.Flags().StringP("branch", "b", "", "branch to associate with the version")
+	packageVersionCreateCmd.Flags().StringP("installation-key", "k", "", "installation key required to install the version")
+	packageVersionCreateCmd.Flags().BoolP("installation-key-bypass", "x", false, "allow the version to be installed without a key")
+	packageVersionCreateCmd.Flags().BoolP("code-coverage", "c", false, "calculate code coverage")
+	packageVersionCreateCmd.Flags().Bool("skip-validation", false, "skip validation of dependencies, package ancestors, and metadata")
+	packageVersionCreateCmd.Flags().StringSliceP("dependency", "d", []string{}, "id of package version (04t) the version depends on")
+	packageVersionCreateCmd.Flags().Bool("wait", true, "wait for the version to be created")
+	packageVersionCreateCmd.Flags().Duration("timeout", time.Hour, "maximum time to wait for the version to be created and installed.  0 waits until it completes")
+	packageVersionCreateCmd.Flags().StringP("install", "i", "", "account `username` in which to install the new version")
+	packageVersionCreateCmd.MarkFlagRequired("version-number")
+	packageVersionCreateCmd.MarkFlagsMutuallyExclusive("installation-key", "installation-key-bypass")
+
+	packageVersionListCmd.Flags().BoolP("released", "r", false, "only list released versions")
+
+	packageVersionCmd.AddCommand(packageVersionCreateCmd)
+	packageVersionCmd.AddCommand(packageVersionListCmd)
+	packageVersionCmd.AddCommand(packageVersionPromoteCmd)
+	packageVersionCmd.AddCommand(packageVersionReportCmd)
+	packageCmd.AddCommand(packageVersionCmd)
+}
+
+var packageVersionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Manage second-generation package versions on a Dev Hub",
+	Example: `
+  force package version create MyPackage src -n 1.2.0.NEXT -x -c
+  force package version list MyPackage
+  force package version report 04t000000000000
+  force package version promote 04t000000000000
+`,
+}
+
+var packageVersionCreateCmd = &cobra.Command{
+	Use:   "create [flags] <package> [directory]",
+	Short: "Create a package version",
+	Long: `
+Create a new version of a second-generation package from a metadata
+directory containing a package.xml.  The package can be specified by name or
+id (0Ho).  The directory defaults to the source directory.
+`,
+	Example: `
+  force package version create MyPackage -n 1.2.0.NEXT --installation-key-bypass
+  force package version create MyPackage src -n 1.2.0.NEXT -k secret -c --install qa@example.com
+`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		installKey, _ := cmd.Flags().GetString("installation-key")
+		installKeyBypass, _ := cmd.Flags().GetBool("installation-key-bypass")
+		if installKey == "" && !installKeyBypass {
+			ErrorAndExit("Specify an --installation-key or --installation-key-bypass")
+		}
+		var dir string
+		if len(args) > 1 {
+			dir = args[1]
+		} else {
+			var err error
+			dir, err = config.GetSourceDir()
+			ExitIfNoSourceDir(err)
+		}
+		pkg, err := force.GetPackage2(args[0])
+		if err != nil {
+			ErrorAndExit(err.Error())
+		}
+		options := Package2VersionCreateOptions{Package2Id: pkg.Id, InstallationKey: installKey}
+		options.VersionNumber, _ = cmd.Flags().GetString("version-number")
+		options.VersionName, _ = cmd.Flags().GetString("version-name")
+		options.VersionDescription, _ = cmd.Flags().GetString("version-description")
+		options.AncestorId, _ = cmd.Flags().GetString("ancestor")
+		options.Tag, _ = cmd.Flags().GetString("tag")
+		options.Branch, _ = cmd.Flags().GetString("branch")
+		options.CalculateCodeCoverage, _ = cmd.Flags().GetBool("code-coverage")
+		options.SkipValidation, _ = cmd.Flags().GetBool("skip-validation")
+		options.Dependencies, _ = cmd.Flags().GetStringSlice("dependency")
+		wait, _ := cmd.Flags().GetBool("wait")
+		installAccount, _ := cmd.Flags().GetString("install")
+		ctx, cancel := waitContext(cmd)
+		defer cancel()
+		runCreatePackageVersion(ctx, dir, options, wait, installAccount)
+	},
+}
+
+var packageVersionListCmd = &cobra.Command{
+	Use:   "list [flags] <package>",
+	Short: "List package versions",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		released, _ := cmd.Flags().GetBool("released")
+		pkg, err := force.GetPackage2(args[0])
+		if err != nil {
+			ErrorAndExit(err.Error())
+		}
+		versions, err := force.QueryPackage2Versions(pkg.Id, released)
+		if err != nil {
+			ErrorAndExit(err.Error())
+		}
+		displayPackageVersions(versions)
+	},
+}
+
+var packageVersionPromoteCmd = &cobra.Command{
+	Use:   "promote <version id>",
+	Short: "Promote a package version to released",
+	Long: `
+Promote a package version so that it can be installed in production orgs.  The
+version can be specified by its package version (05i) or subscriber package
+version (04t) id.
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := force.PromotePackage2Version(args[0]); err != nil {
+			ErrorAndExit(err.Error())
+		}
+		fmt.Printf("Package version %s promoted\n", args[0])
+	},
+}
+
+var packageVersionReportCmd = &cobra.Command{
+	Use:   "report <version id>",
+	Short: "Display details of a package version",
+	Long: `
+Display details of a package version, including its code coverage and
+dependencies.  The version can be specified by its package version (05i) or
+subscriber package version (04t) id.
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		version, err := force.GetPackage2Version(args[0])
+		if err != nil {
+			ErrorAndExit(err.Error())
+		}
+		displayPackageVersion(version)
+	},
+}
+
+func runCreatePackageVersion(ctx context.Context, dir string, options Package2VersionCreateOptions, wait bool, installAccount string) {
+	if _, err := os.Stat(filepath.Join(dir, "package.xml")); os.IsNotExist(err) {
+		ErrorAndExit("%s does not exist", filepath.Join(dir, "package.xml"))
+	}
+	files, err := metadataFilesFromDirectory(dir)
+	if err != nil {
+		ErrorAndExit(err.Error())
+	}
+	requestId, err := force.CreatePackage2Version(files, options)
+	if err != nil {
+		ErrorAndExit(err.Error())
+	}
+	fmt.Printf("Package version create request %s started\n", requestId)
+	if !wait {
+		return
+	}
+	lastStatus := ""
+	version, err := force.WaitForPackage2Version(ctx, requestId, 10*time.Second, func(r Package2VersionCreateRequest) {
+		if r.Status != lastStatus {
+			fmt.Printf("Status: %s\n", r.Status)
+			lastStatus = r.Status
+		}
+	})
+	if err != nil {
+		ErrorAndExit(err.Error())
+	}
+	displayPackageVersion(version)
+	if installAccount != "" {
+		installPackageVersionInAccount(ctx, installAccount, version.SubscriberPackageVersionId, PackageInstallOptions{Password: options.InstallationKey})
+	}
+}
+
+func installPackageVersionInAccount(ctx context.Context, account string, subscriberPackageVersionId string, options PackageInstallOptions) {
+	target, err := GetForce(account)
+	if err != nil {
+		ErrorAndExit(err.Error())
+	}
+	installPackageVersion(ctx, target, subscriberPackageVersionId, options)
+}
+
+func installPackageVersion(ctx context.Context, target *Force, subscriberPackageVersionId string, options PackageInstallOptions) {
+	requestId, err := target.StartPackageInstall(subscriberPackageVersionId, options)
+	if err != nil {
+		ErrorAndExit(err.Error())
+	}
+	fmt.Printf("Installing %s in %s\n", subscriberPackageVersionId, target.Credentials.SessionName())
+	lastStatus := ""
+	err = target.WaitForPackageInstall(ctx, requestId, 10*time.Second, func(r PackageInstallRequest) {
+		if r.Status != lastStatus {
+			fmt.Printf("Status: %s\n", r.Status)
+			lastStatus = r.Status
+		}
+	})
+	if err != nil {
+		ErrorAndExit(err.Error())
+	}
+	fmt.Println("Package installed")
+}
+
+func packageVersionCoverage(v Package2Version) string {
+	if v.CodeCoverage == nil {
+		return ""
+	}
+	return fmt.Sprintf("%.0f%%", v.CodeCoverage.ApexCodeCoveragePercentage)
+}
+
+func displayPackageVersions(versions []Package2Version) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Name", "Version", "Subscriber Package Version Id", "Released", "Coverage", "Tag", "Branch", "Created"})
+	table.SetAutoWrapText(false)
+	for _, v := range versions {
+		table.Append([]string{
+			v.Name,
+			v.VersionNumber(),
+			v.SubscriberPackageVersionId,
+			strconv.FormatBool(v.IsReleased),
+			packageVersionCoverage(v),
+			v.Tag,
+			v.Branch,
+			v.CreatedDate,
+		})
+	}
+	table.Render()
+}
+
+func displayPackageVersion(v Package2Version) {
+	coverage := packageVersionCoverage(v)
+	switch {
+	case v.ValidationSkipped:
+		coverage = "not calculated (validation skipped)"
+	case coverage == "":
+		coverage = "not calculated"
+	case v.HasPassedCodeCoverageCheck:
+		coverage += " (passed)"
+	default:
+		coverage += " (below 75%, cannot be promoted)"
+	}
+	fmt.Printf("Name: %s\n", v.Name)
+	fmt.Printf("Version: %s\n", v.VersionNumber())
+	fmt.Printf("Package Version Id: %s\n", v.Id)
+	fmt.Printf("Subscriber Package Version Id: %s\n", v.SubscriberPackageVersionId)
+	fmt.Printf("Released: %t\n", v.IsReleased)
+	fmt.Printf("Code Coverage: %s\n", coverage)
+	if v.Tag != "" {
+		fmt.Printf("Tag: %s\n", v.Tag)
+	}
+	if v.Branch != "" {
+		fmt.Printf("Branch: %s\n", v.Branch)
+	}
+	dependencies, err := force.SubscriberPackageVersionDependencies(v.SubscriberPackageVersionId)
+	if err != nil {
+		ErrorAndExit(err.Error())
+	}
+	fmt.Println("Dependencies:")
+	if len(dependencies) == 0 {
+		fmt.Println("  (none)")
+	}
+	for _, d := range dependencies {
+		fmt.Printf("  %s\n", d)
+	}
+}
@@ -0,0 +1,238 @@
+package command
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	. "github.com/ForceCLI/force/error"
+	. "github.com/ForceCLI/force/lib"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	for _, cmd := range []*cobra.Command{permsetAssignCmd, permsetUnassignCmd} {
+		cmd.Flags().StringP("query", "q", "", "SOQL condition selecting users, e.g. \"Profile.Name = 'Standard User'\"")
+		cmd.Flags().String("from-csv", "", "CSV file with Username and PermissionSet columns")
+	}
+
+	permsetCmd.AddCommand(permsetListCmd)
+	permsetCmd.AddCommand(permsetShowCmd)
+	permsetCmd.AddCommand(permsetAssignCmd)
+	permsetCmd.AddCommand(permsetUnassignCmd)
+	RootCmd.AddCommand(permsetCmd)
+}
+
+var permsetCmd = &cobra.Command{
+	Use:   "permset",
+	Short: "Manage permission set and permission set group assignments",
+}
+
+var permsetListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List permission sets and permission set groups",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		permissionSets, err := force.QueryPermissionSets()
+		if err != nil {
+			ErrorAndExit(err.Error())
+		}
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Name", "Label", "Type", "License"})
+		table.SetAutoWrapText(false)
+		for _, ps := range permissionSets {
+			table.Append([]string{ps.Name, ps.Label, ps.Type, ps.License})
+		}
+		table.Render()
+	},
+}
+
+var permsetShowCmd = &cobra.Command{
+	Use:   "show <permset>",
+	Short: "Show a permission set or permission set group and its assigned users",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ps, err := force.GetPermissionSet(args[0])
+		if err != nil {
+			ErrorAndExit(err.Error())
+		}
+		assignments, err := force.QueryPermissionSetAssignments(ps)
+		if err != nil {
+			ErrorAndExit(err.Error())
+		}
+		fmt.Printf("Name: %s\n", ps.Name)
+		fmt.Printf("Label: %s\n", ps.Label)
+		fmt.Printf("Type: %s\n", ps.Type)
+		if ps.License != "" {
+			fmt.Printf("License: %s\n", ps.License)
+		}
+		if ps.Description != "" {
+			fmt.Printf("Description: %s\n", ps.Description)
+		}
+		fmt.Printf("Assigned Users: %d\n", len(assignments))
+		if len(assignments) == 0 {
+			return
+		}
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Username", "Name"})
+		table.SetAutoWrapText(false)
+		for _, a := range assignments {
+			table.Append([]string{a.Username, a.Name})
+		}
+		table.Render()
+	},
+}
+
+var permsetAssignCmd = &cobra.Command{
+	Use:   "assign [flags] [<permset> [username]...]",
+	Short: "Assign a permission set or permission set group to users",
+	Long: `
+Assign a permission set or permission set group to users.  Users can be
+selected by username, by a SOQL condition on User, or both.  Users who already
+have the permission set are left unchanged.
+
+With --from-csv, assignments are read from a CSV file with Username and
+PermissionSet columns instead.
+`,
+	Example: `
+  force permset assign Sales_Ops user1@example.com user2@example.com
+  force permset assign Sales_Ops --query "Profile.Name = 'Standard User' AND IsActive = true"
+  force permset assign --from-csv assignments.csv
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runPermsetAssignment(cmd, args, force.AssignPermissionSet)
+	},
+}
+
+var permsetUnassignCmd = &cobra.Command{
+	Use:   "unassign [flags] [<permset> [username]...]",
+	Short: "Remove a permission set or permission set group from users",
+	Long: `
+Remove a permission set or permission set group from users.  Users can be
+selected by username, by a SOQL condition on User, or both.  Users who don't
+have the permission set are left unchanged.
+
+With --from-csv, assignments to remove are read from a CSV file with Username
+and PermissionSet columns instead.
+`,
+	Example: `
+  force permset unassign Sales_Ops user1@example.com
+  force permset unassign Sales_Ops --query "IsActive = false"
+  force permset unassign --from-csv assignments.csv
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runPermsetAssignment(cmd, args, force.UnassignPermissionSet)
+	},
+}
+
+type permsetAssignmentFunc func(PermissionSet, map[string]string) ([]PermissionSetAssignmentResult, error)
+
+func runPermsetAssignment(cmd *cobra.Command, args []string, apply permsetAssignmentFunc) {
+	query, _ := cmd.Flags().GetString("query")
+	csvFile, _ := cmd.Flags().GetString("from-csv")
+
+	// Usernames to assign, keyed by permission set name
+	assignments := make(map[string][]string)
+	if csvFile != "" {
+		if len(args) > 0 || query != "" {
+			ErrorAndExit("--from-csv cannot be combined with a permission set, usernames, or --query")
+		}
+		f, err := os.Open(csvFile)
+		if err != nil {
+			ErrorAndExit(err.Error())
+		}
+		defer f.Close()
+		assignments, err = readPermsetAssignments(f)
+		if err != nil {
+			ErrorAndExit(err.Error())
+		}
+	} else {
+		if len(args) == 0 {
+			ErrorAndExit("A permission set is required")
+		}
+		if len(args) == 1 && query == "" {
+			ErrorAndExit("Specify usernames or --query to select users")
+		}
+		assignments[args[0]] = args[1:]
+	}
+
+	failed := false
+	names := make([]string, 0, len(assignments))
+	for name := range assignments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		ps, err := force.GetPermissionSet(name)
+		if err != nil {
+			ErrorAndExit(err.Error())
+		}
+		userIds, err := force.QueryUserIds(assignments[name], query)
+		if err != nil {
+			ErrorAndExit(err.Error())
+		}
+		if len(userIds) == 0 {
+			fmt.Printf("%s: no matching users\n", ps.Name)
+			continue
+		}
+		results, err := apply(ps, userIds)
+		if err != nil {
+			ErrorAndExit(err.Error())
+		}
+		for _, r := range results {
+			if r.Error != nil {
+				failed = true
+				fmt.Printf("%s: %s %s: %s\n", ps.Name, r.Username, r.Status, r.Error)
+				continue
+			}
+			fmt.Printf("%s: %s %s\n", ps.Name, r.Username, r.Status)
+		}
+	}
+	if failed {
+		ErrorAndExit("Some assignments failed")
+	}
+}
+
+// readPermsetAssignments reads a CSV file with Username and PermissionSet
+// columns, returning the usernames keyed by permission set name
+func readPermsetAssignments(r io.Reader) (map[string][]string, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("Could not read CSV header: %w", err)
+	}
+	usernameColumn, permsetColumn := -1, -1
+	for i, h := range header {
+		switch strings.ToLower(strings.TrimSpace(h)) {
+		case "username":
+			usernameColumn = i
+		case "permissionset":
+			permsetColumn = i
+		}
+	}
+	if usernameColumn < 0 || permsetColumn < 0 {
+		return nil, fmt.Errorf("CSV must have Username and PermissionSet columns")
+	}
+	assignments := make(map[string][]string)
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Could not read CSV: %w", err)
+		}
+		username := strings.TrimSpace(row[usernameColumn])
+		permset := strings.TrimSpace(row[permsetColumn])
+		if username == "" || permset == "" {
+			return nil, fmt.Errorf("Missing Username or PermissionSet on line %d", line)
+		}
+		assignments[permset] = append(assignments[permset], username)
+	}
+	return assignments, nil
+}
@@ -0,0 +1,40 @@
+package command
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadPermsetAssignments(t *testing.T) {
+	input := `permissionset,Email,USERNAME
+Sales_Ops,a@example.com,a@example.com
+Sales_Ops,b@example.com,b@example.com
+Support, c@example.com, c@example.com
+`
+	assignments, err := readPermsetAssignments(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string][]string{
+		"Sales_Ops": {"a@example.com", "b@example.com"},
+		"Support":   {"c@example.com"},
+	}
+	if !reflect.DeepEqual(assignments, expected) {
+		t.Errorf("expected %v, got %v", expected, assignments)
+	}
+}
+
+func TestReadPermsetAssignmentsMissingColumn(t *testing.T) {
+	_, err := readPermsetAssignments(strings.NewReader("Username\na@example.com\n"))
+	if err == nil {
+		t.Error("expected error for missing PermissionSet column")
+	}
+}
+
+func TestReadPermsetAssignmentsMissingValue(t *testing.T) {
+	_, err := readPermsetAssignments(strings.NewReader("Username,PermissionSet\na@example.com,\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("expected error on line 2, got %v", err)
+	}
+}
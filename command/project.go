@@ -0,0 +1,57 @@
+package command
+
+import (
+	"strings"
+
+	"github.com/ForceCLI/force/config"
+	"github.com/spf13/cobra"
+)
+
+// outputFormatsAnnotation is set on --format flags to list the output formats
+// the command supports
+const outputFormatsAnnotation = "outputFormats"
+
+// setOutputFormats records the output formats supported by the command's
+// --format flag so the project's default output format is only applied to
+// commands that support it
+func setOutputFormats(cmd *cobra.Command, formats ...string) {
+	cmd.Flags().SetAnnotation("format", outputFormatsAnnotation, formats)
+}
+
+func supportsOutputFormat(formats []string, format string) bool {
+	for _, f := range formats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// applyProjectDefaults sets the defaults from the project config file, if
+// any, for flags not passed on the command line
+func applyProjectDefaults(cmd *cobra.Command, project *config.ProjectConfig) {
+	if project == nil {
+		return
+	}
+	// A session from the environment, e.g. in CI, takes precedence over the
+	// project's account
+	if len(accountNames) == 0 && project.Account != "" && !hasEnvSession() {
+		accountNames = []string{project.Account}
+	}
+	if _apiVersion == "" {
+		_apiVersion = project.ApiVersion
+	}
+	flags := cmd.Flags()
+	// Explicitly selected tests require RunSpecifiedTests
+	if project.TestLevel != "" && flags.Lookup("testlevel") != nil && !flags.Changed("testlevel") && !flags.Changed("test") {
+		flags.Set("testlevel", project.TestLevel)
+	}
+	// Output formats vary by command, so only apply the default where the
+	// format is supported
+	if f := flags.Lookup("format"); project.OutputFormat != "" && f != nil && !f.Changed && supportsOutputFormat(f.Annotations[outputFormatsAnnotation], project.OutputFormat) {
+		flags.Set("format", project.OutputFormat)
+	}
+	if cmd == exportCmd && len(project.ExportExclude) > 0 && !flags.Changed("exclude") {
+		flags.Set("exclude", strings.Join(project.ExportExclude, ","))
+	}
+}
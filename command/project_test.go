@@ -0,0 +1,87 @@
+package command
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ForceCLI/force/config"
+	"github.com/spf13/cobra"
+)
+
+func TestApplyProjectDefaults(t *testing.T) {
+	defer func() {
+		accountNames = nil
+		_apiVersion = ""
+	}()
+	project := &config.ProjectConfig{
+		Account:      "prod",
+		ApiVersion:   "59.0",
+		TestLevel:    "RunLocalTests",
+		OutputFormat: "json",
+	}
+
+	cmd := &cobra.Command{Use: "push"}
+	cmd.Flags().String("testlevel", "NoTestRun", "test level")
+	cmd.Flags().StringSlice("test", nil, "tests")
+	cmd.Flags().String("format", "console", "output format: csv, json, console")
+	setOutputFormats(cmd, "csv", "json", "console")
+	accountNames = nil
+	_apiVersion = ""
+	applyProjectDefaults(cmd, project)
+	if !reflect.DeepEqual(accountNames, []string{"prod"}) {
+		t.Errorf("expected account prod, got %v", accountNames)
+	}
+	if _apiVersion != "59.0" {
+		t.Errorf("expected api version 59.0, got %s", _apiVersion)
+	}
+	if testLevel, _ := cmd.Flags().GetString("testlevel"); testLevel != "RunLocalTests" {
+		t.Errorf("expected test level RunLocalTests, got %s", testLevel)
+	}
+	if format, _ := cmd.Flags().GetString("format"); format != "json" {
+		t.Errorf("expected format json, got %s", format)
+	}
+
+	// Flags passed on the command line take precedence
+	cmd = &cobra.Command{Use: "diagram"}
+	cmd.Flags().String("testlevel", "NoTestRun", "test level")
+	cmd.Flags().String("format", "mermaid", "output format: mermaid, dot, or plantuml")
+	setOutputFormats(cmd, "mermaid", "dot", "plantuml")
+	cmd.Flags().Set("testlevel", "RunAllTestsInOrg")
+	accountNames = []string{"dev"}
+	applyProjectDefaults(cmd, project)
+	if !reflect.DeepEqual(accountNames, []string{"dev"}) {
+		t.Errorf("expected account dev, got %v", accountNames)
+	}
+	if testLevel, _ := cmd.Flags().GetString("testlevel"); testLevel != "RunAllTestsInOrg" {
+		t.Errorf("expected test level RunAllTestsInOrg, got %s", testLevel)
+	}
+	if format, _ := cmd.Flags().GetString("format"); format != "mermaid" {
+		t.Errorf("expected unsupported format to be ignored, got %s", format)
+	}
+
+	// Formats must match exactly
+	for _, format := range []string{"pretty", "on", "table"} {
+		project.OutputFormat = format
+		cmd = &cobra.Command{Use: "query"}
+		cmd.Flags().String("format", "console", "output format: csv, json, json-pretty, console")
+		setOutputFormats(cmd, "csv", "json", "json-pretty", "console")
+		applyProjectDefaults(cmd, project)
+		if got, _ := cmd.Flags().GetString("format"); got != "console" {
+			t.Errorf("expected unsupported format %s to be ignored, got %s", format, got)
+		}
+	}
+}
+
+func TestApplyProjectDefaultsWithEnvSession(t *testing.T) {
+	defer func() {
+		accountNames = nil
+		_apiVersion = ""
+	}()
+	t.Setenv("SF_ACCESS_TOKEN", "token")
+	t.Setenv("SF_INSTANCE_URL", "https://example.my.salesforce.com")
+	accountNames = nil
+	applyProjectDefaults(&cobra.Command{Use: "query"}, &config.ProjectConfig{Account: "prod"})
+	if len(accountNames) != 0 {
+		t.Errorf("expected the environment session to be used, got account %v", accountNames)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/ForceCLI/force/config"
 	. "github.com/ForceCLI/force/error"
@@ -17,7 +18,7 @@ func init() {
 	// Deploy options
 	pushCmd.Flags().BoolP("rollbackonerror", "r", false, "roll back deployment on error")
 	pushCmd.Flags().Bool("runalltests", false, "run all tests (equivalent to --testlevel RunAllTestsInOrg)")
-	pushCmd.Flags().StringP("testlevel", "l", "NoTestRun", "test level")
+	pushCmd.Flags().StringP("testlevel", "l", "NoTestRun", "test level (NoTestRun, RunSpecifiedTests, RunLocalTests, RunAllTestsInOrg, or RunRelevantTests)")
 	pushCmd.Flags().BoolP("checkonly", "c", false, "check only deploy")
 	pushCmd.Flags().BoolP("purgeondelete", "p", false, "purge metadata from org on delete")
 	pushCmd.Flags().BoolP("allowmissingfiles", "m", false, "set allow missing files")
@@ -37,6 +38,8 @@ func init() {
 	pushCmd.Flags().StringSliceP("type", "t", []string{}, "Metatdata type")
 	pushCmd.Flags().StringSliceP("name", "n", []string{}, "name of metadata object")
 	pushCmd.Flags().StringSlice("test", []string{}, "Test(s) to run")
+	pushCmd.Flags().Bool("watch", false, "watch directory for changes and deploy changed components")
+	pushCmd.Flags().Duration("debounce", 500*time.Millisecond, "time to wait for further changes before deploying in watch mode")
 	pushCmd.Flags().Bool("smart-flow-version", false, "enable smart flow versioning (auto-select new version and prune inactive flows)")
 	RootCmd.AddCommand(pushCmd)
 }
@@ -48,6 +51,9 @@ var pushCmd = &cobra.Command{
 Deploy artifact from a local directory
 <metadata>: Accepts either actual directory name or Metadata type
 File path can be specified as - to read from stdin; see examples
+
+With --watch, watch a directory (the source directory by default) and deploy
+components as their files are saved
 `,
 
 	Example: `
@@ -56,11 +62,28 @@ File path can be specified as - to read from stdin; see examples
   force push -f metadata/classes/MyClass.cls
   force push -checkonly -test MyClass_Test metadata/classes/MyClass.cls
   force push -n MyApex -n MyObject__c
+  force push -l RunRelevantTests -f metadata/classes/MyClass.cls
   git diff HEAD^ --name-only --diff-filter=ACM | force push -f -
+  force push -a qa1@example.com -a qa2@example.com -t ApexClass
+  force push --watch
+  force push --watch src/lwc
 `,
 	DisableFlagsInUseLine: false,
+	Annotations:           map[string]string{multipleAccountsAnnotation: "true"},
 	Run: func(cmd *cobra.Command, args []string) {
 		deployOptions := getDeploymentOptions(cmd)
+		if watch, _ := cmd.Flags().GetBool("watch"); watch {
+			if len(args) > 1 {
+				ErrorAndExit("Only one directory can be watched")
+			}
+			dir := ""
+			if len(args) == 1 {
+				dir = args[0]
+			}
+			debounce, _ := cmd.Flags().GetDuration("debounce")
+			runPushWatch(dir, debounce, &deployOptions, getDeploymentOutputOptions(cmd))
+			return
+		}
 		metadataTypes, _ := cmd.Flags().GetStringSlice("type")
 		metadataNames, _ := cmd.Flags().GetStringSlice("name")
 		resourcePaths, _ := cmd.Flags().GetStringSlice("filepath")
@@ -189,7 +212,7 @@ func pushByPaths(resourcePaths []string, deployOptions *ForceDeployOptions, disp
 		}
 	}
 	// Deploy
-	err = deploy(force, files, deployOptions, displayOptions)
+	err = deployToAccounts(files, deployOptions, displayOptions)
 	if err != nil {
 		ErrorAndExit(err.Error())
 	}
@@ -222,7 +245,7 @@ func pushByMetadataType(metadataType string, metadataNames []string, deployOptio
 			ErrorAndExit(err.Error())
 		}
 	}
-	if err = deploy(force, files, deployOptions, displayOptions); err != nil {
+	if err = deployToAccounts(files, deployOptions, displayOptions); err != nil {
 		ErrorAndExit(err.Error())
 	}
 }
@@ -248,7 +271,7 @@ func pushMetadataTypes(metadataTypes []string, deployOptions *ForceDeployOptions
 			ErrorAndExit(err.Error())
 		}
 	}
-	if err = deploy(force, files, deployOptions, displayOptions); err != nil {
+	if err = deployToAccounts(files, deployOptions, displayOptions); err != nil {
 		ErrorAndExit(err.Error())
 	}
 }
@@ -0,0 +1,213 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ForceCLI/force/config"
+	. "github.com/ForceCLI/force/error"
+	. "github.com/ForceCLI/force/lib"
+	"github.com/fsnotify/fsnotify"
+)
+
+// runPushWatch watches dir, or the source directory if dir is empty, and
+// deploys components as their files change.  Changes are batched until no
+// further changes are seen for the debounce interval.
+func runPushWatch(dir string, debounce time.Duration, deployOptions *ForceDeployOptions, displayOptions *deployOutputOptions) {
+	// Metadata types are determined relative to the source directory, so
+	// when watching a subdirectory, such as src/lwc, the source directory is
+	// still needed
+	var sourceDir string
+	var err error
+	if dir != "" && !filepath.IsAbs(dir) {
+		sourceDir = sourceDirFromPaths([]string{dir})
+	}
+	if sourceDir == "" {
+		sourceDir, err = config.GetSourceDir()
+		ExitIfNoSourceDir(err)
+	}
+	if dir == "" {
+		dir = sourceDir
+	}
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		ErrorAndExit(err.Error())
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		ErrorAndExit("Could not start watcher: %s", err.Error())
+	}
+	defer watcher.Close()
+	if err = watchDirectoryTree(watcher, root); err != nil {
+		ErrorAndExit("Could not watch %s: %s", root, err.Error())
+	}
+	fmt.Printf("Watching %s for changes\n", root)
+
+	changed := make(map[string]bool)
+	timer := time.NewTimer(debounce)
+	timer.Stop()
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if ignoreWatchedPath(event.Name) {
+				continue
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+				continue
+			}
+			if f, err := os.Stat(event.Name); err == nil && f.IsDir() {
+				// Watch new directories, e.g. a new lwc bundle
+				if err = watchDirectoryTree(watcher, event.Name); err != nil {
+					fmt.Fprintf(os.Stderr, "Could not watch %s: %s\n", event.Name, err.Error())
+				}
+				continue
+			}
+			changed[event.Name] = true
+			timer.Reset(debounce)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			fmt.Fprintf(os.Stderr, "Watch error: %s\n", err.Error())
+		case <-timer.C:
+			var paths []string
+			for p := range changed {
+				paths = append(paths, p)
+			}
+			changed = make(map[string]bool)
+			pushChangedPaths(sourceDir, paths, *deployOptions, displayOptions)
+		}
+	}
+}
+
+func watchDirectoryTree(watcher *fsnotify.Watcher, dir string) error {
+	return filepath.Walk(dir, func(path string, f os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !f.IsDir() {
+			return nil
+		}
+		if path != dir && strings.HasPrefix(f.Name(), ".") {
+			return filepath.SkipDir
+		}
+		return watcher.Add(path)
+	})
+}
+
+// ignoreWatchedPath returns true for hidden files and the temporary files
+// editors create while saving.
+func ignoreWatchedPath(path string) bool {
+	name := filepath.Base(path)
+	switch {
+	case strings.HasPrefix(name, "."), strings.HasPrefix(name, "#"):
+		return true
+	case strings.HasSuffix(name, "~"), strings.HasSuffix(name, ".swp"), strings.HasSuffix(name, ".swx"), strings.HasSuffix(name, ".tmp"):
+		return true
+	case name == "4913":
+		// vim checks whether it can create files in the directory
+		return true
+	}
+	return false
+}
+
+// changedComponentPaths maps changed files to the paths to deploy, replacing
+// aura and lwc files with their bundles and removing duplicates and files
+// that no longer exist.
+func changedComponentPaths(paths []string) []string {
+	seen := make(map[string]bool)
+	var componentPaths []string
+	for _, p := range paths {
+		p = replaceComponentWithBundle(p)
+		if seen[p] {
+			continue
+		}
+		seen[p] = true
+		if _, err := os.Stat(p); err != nil {
+			continue
+		}
+		componentPaths = append(componentPaths, p)
+	}
+	sort.Strings(componentPaths)
+	return componentPaths
+}
+
+// changedComponents returns a package of the components whose files changed.
+// Metadata types are determined by the paths relative to sourceDir.
+func changedComponents(sourceDir string, paths []string) PackageBuilder {
+	pb := NewPushBuilder()
+	pb.Root = sourceDir
+	for _, p := range changedComponentPaths(paths) {
+		f, err := os.Stat(p)
+		if err != nil {
+			continue
+		}
+		if f.Mode().IsDir() {
+			err = pb.AddDirectory(p)
+		} else {
+			err = pb.AddFile(p)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Could not add %s: %s\n", p, err.Error())
+		}
+	}
+	return pb
+}
+
+func pushChangedPaths(sourceDir string, paths []string, deployOptions ForceDeployOptions, displayOptions *deployOutputOptions) {
+	pb := changedComponents(sourceDir, paths)
+	if len(pb.Metadata) == 0 {
+		return
+	}
+	var components []string
+	for metadataType, m := range pb.Metadata {
+		for _, name := range m.Members {
+			components = append(components, metadataType+":"+name)
+		}
+	}
+	sort.Strings(components)
+	fmt.Printf("%s Deploying %s\n", time.Now().Format("15:04:05"), strings.Join(components, ", "))
+
+	outputOptions := *displayOptions
+	outputOptions.compact = true
+	outputOptions.interactive = false
+	if err := deploy(force, pb.ForceMetadataFiles(), &deployOptions, &outputOptions); err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+	}
+}
+
+// compactDeployResult summarizes a deploy in a line, followed by a line for
+// each component or test failure.
+func compactDeployResult(result ForceCheckDeploymentStatusResult, duration time.Duration) string {
+	var b strings.Builder
+	status := "Deployed"
+	if !result.Success {
+		status = "Failed"
+	}
+	fmt.Fprintf(&b, "%s %s %d/%d components", time.Now().Format("15:04:05"), status, result.NumberComponentsDeployed, result.NumberComponentsTotal)
+	if result.NumberTestsTotal > 0 {
+		fmt.Fprintf(&b, ", %d/%d tests passed", result.NumberTestsCompleted, result.NumberTestsTotal)
+	}
+	fmt.Fprintf(&b, " in %.1fs\n", duration.Seconds())
+	for _, f := range result.Details.ComponentFailures {
+		location := f.FileName
+		if f.LineNumber > 0 {
+			location = fmt.Sprintf("%s:%d:%d", location, f.LineNumber, f.ColumnNumber)
+		}
+		fmt.Fprintf(&b, "  %s: %s\n", location, f.Problem)
+	}
+	for _, f := range result.Details.RunTestResult.TestFailures {
+		fmt.Fprintf(&b, "  %s.%s: %s\n", f.Name, f.MethodName, f.Message)
+	}
+	if !result.Success && len(result.Details.ComponentFailures) == 0 && len(result.Details.RunTestResult.TestFailures) == 0 && result.ErrorMessage != "" {
+		fmt.Fprintf(&b, "  %s\n", result.ErrorMessage)
+	}
+	return b.String()
+}
@@ -0,0 +1,88 @@
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ForceCLI/force/lib"
+)
+
+func TestIgnoreWatchedPath(t *testing.T) {
+	for path, ignored := range map[string]bool{
+		"src/classes/Foo.cls":          false,
+		"src/classes/Foo.cls-meta.xml": false,
+		"src/classes/.Foo.cls.swp":     true,
+		"src/classes/Foo.cls~":         true,
+		"src/classes/#Foo.cls#":        true,
+		"src/classes/4913":             true,
+	} {
+		if ignoreWatchedPath(path) != ignored {
+			t.Errorf("ignoreWatchedPath(%s) should be %v", path, ignored)
+		}
+	}
+}
+
+func TestChangedComponentPaths(t *testing.T) {
+	dir := t.TempDir()
+	bundle := filepath.Join(dir, "src", "lwc", "myComponent")
+	if err := os.MkdirAll(bundle, 0755); err != nil {
+		t.Fatal(err)
+	}
+	js := filepath.Join(bundle, "myComponent.js")
+	html := filepath.Join(bundle, "myComponent.html")
+	for _, f := range []string{js, html} {
+		if err := os.WriteFile(f, []byte(""), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	deleted := filepath.Join(dir, "src", "classes", "Deleted.cls")
+
+	paths := changedComponentPaths([]string{js, html, deleted})
+	if len(paths) != 1 || paths[0] != bundle {
+		t.Errorf("expected only the bundle directory, got %v", paths)
+	}
+}
+
+func TestChangedComponentsInSubdirectory(t *testing.T) {
+	dir := t.TempDir()
+	sourceDir := filepath.Join(dir, "src")
+	bundle := filepath.Join(sourceDir, "lwc", "myComponent")
+	if err := os.MkdirAll(bundle, 0755); err != nil {
+		t.Fatal(err)
+	}
+	js := filepath.Join(bundle, "myComponent.js")
+	for _, f := range []string{js, filepath.Join(bundle, "myComponent.js-meta.xml")} {
+		if err := os.WriteFile(f, []byte(""), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Watching src/lwc still maps paths relative to src
+	pb := changedComponents(sourceDir, []string{js})
+	members := pb.Metadata["LightningComponentBundle"].Members
+	if len(members) != 1 || members[0] != "myComponent" {
+		t.Errorf("expected LightningComponentBundle myComponent, got %v", pb.Metadata)
+	}
+}
+
+func TestCompactDeployResult(t *testing.T) {
+	var result lib.ForceCheckDeploymentStatusResult
+	result.NumberComponentsTotal = 2
+	result.NumberComponentsDeployed = 1
+	result.Details.ComponentFailures = []lib.ComponentFailure{{
+		FileName:     "classes/Foo.cls",
+		LineNumber:   3,
+		ColumnNumber: 7,
+		Problem:      "Variable does not exist: bar",
+	}}
+	output := compactDeployResult(result, 1500*time.Millisecond)
+	if !strings.Contains(output, "Failed 1/2 components in 1.5s") {
+		t.Errorf("unexpected summary:\n%s", output)
+	}
+	if !strings.Contains(output, "  classes/Foo.cls:3:7: Variable does not exist: bar\n") {
+		t.Errorf("missing failure:\n%s", output)
+	}
+}
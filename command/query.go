@@ -23,6 +23,7 @@ func init() {
 	queryCmd.Flags().BoolP("tooling", "t", false, "use Tooling API")
 	queryCmd.Flags().BoolP("explain", "e", false, "return query plans")
 	queryCmd.Flags().StringP("format", "f", defaultOutputFormat, "output format: csv, json, json-pretty, console")
+	setOutputFormats(queryCmd, "csv", "json", "json-pretty", "console")
 	RootCmd.AddCommand(queryCmd)
 }
 
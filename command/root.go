@@ -14,9 +14,10 @@ import (
 )
 
 var (
-	account     string
-	configName  string
-	_apiVersion string
+	account      string
+	accountNames []string
+	configName   string
+	_apiVersion  string
 
 	force *Force
 )
@@ -30,24 +31,42 @@ func init() {
 		}
 	}
 	RootCmd.SetArgs(args)
-	RootCmd.PersistentFlags().StringVarP(&account, "account", "a", "", "account `username` to use")
+	RootCmd.PersistentFlags().StringSliceVarP(&accountNames, "account", "a", []string{}, "account `username`, alias, or group to use")
 	RootCmd.PersistentFlags().StringVar(&configName, "config", "", "config directory to use (default: .force)")
 	RootCmd.PersistentFlags().StringVarP(&_apiVersion, "apiversion", "V", "", "API version to use")
 
 	RootCmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {
 		initializeConfig()
+		project, err := forceConfig.Project()
+		if err != nil {
+			ErrorAndExit(err.Error())
+		}
+		applyProjectDefaults(cmd, project)
+		if accountNames, err = ResolveAccounts(accountNames); err != nil {
+			ErrorAndExit(err.Error())
+		}
+		if len(accountNames) > 1 && cmd.Annotations[multipleAccountsAnnotation] != "true" {
+			ErrorAndExit("%s does not support multiple accounts", cmd.CommandPath())
+		}
+		if len(accountNames) > 0 {
+			account = accountNames[0]
+		}
 		current := cmd
 		for current.Parent() != nil && current.Parent() != RootCmd {
 			current = current.Parent()
 		}
 		switch current.Name() {
-		case "force", "login", "completion":
+		case "force", "login", "completion", "alias", "group":
 		default:
 			initializeSession()
 		}
 	}
 }
 
+// Commands annotated with multipleAccountsAnnotation accept more than one
+// --account flag
+const multipleAccountsAnnotation = "multipleAccounts"
+
 var RootCmd = &cobra.Command{
 	Use:   "force",
 	Short: "force CLI",
@@ -65,15 +84,19 @@ func initializeConfig() {
 	}
 }
 
+// hasEnvSession returns true if a session is provided by the SF_ACCESS_TOKEN
+// and SF_INSTANCE_URL environment variables
+func hasEnvSession() bool {
+	return os.Getenv("SF_ACCESS_TOKEN") != "" && os.Getenv("SF_INSTANCE_URL") != ""
+}
+
 func envSession() *Force {
-	token := os.Getenv("SF_ACCESS_TOKEN")
-	instance := os.Getenv("SF_INSTANCE_URL")
-	if token == "" || instance == "" {
+	if !hasEnvSession() {
 		return nil
 	}
 	creds := &ForceSession{
-		AccessToken: token,
-		InstanceUrl: instance,
+		AccessToken: os.Getenv("SF_ACCESS_TOKEN"),
+		InstanceUrl: os.Getenv("SF_INSTANCE_URL"),
 	}
 	f := NewForce(creds)
 	return f
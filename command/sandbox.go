@@ -0,0 +1,292 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ForceCLI/force/bubbles"
+	. "github.com/ForceCLI/force/error"
+	. "github.com/ForceCLI/force/lib"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	for _, cmd := range []*cobra.Command{sandboxCreateCmd, sandboxRefreshCmd, sandboxCloneCmd} {
+		cmd.Flags().StringP("license-type", "l", "", "license type: Developer, Developer_Pro, Partial, or Full.  Defaults to Developer for new sandboxes")
+		cmd.Flags().StringP("description", "d", "", "description of the sandbox")
+		cmd.Flags().StringP("template", "t", "", "id of the sandbox template (1ps) used to select data for partial and full copies")
+		cmd.Flags().StringP("post-copy-class", "c", "", "name or id of an Apex class implementing SandboxPostCopy to run after copying")
+		cmd.Flags().Int("history-days", 0, "days of field history to copy to a full sandbox; -1 for all")
+		cmd.Flags().Bool("copy-chatter", false, "copy Chatter data to a full sandbox.  Refreshes keep the current setting unless specified")
+		cmd.Flags().Bool("auto-activate", true, "activate the sandbox when the copy completes.  Refreshes keep the current setting unless specified")
+	}
+	for _, cmd := range []*cobra.Command{sandboxCreateCmd, sandboxRefreshCmd, sandboxCloneCmd, sandboxStatusCmd} {
+		cmd.Flags().BoolP("wait", "w", false, "wait for the copy to complete, displaying its progress")
+		cmd.Flags().Bool("login", false, "log into the sandbox and save the login when the copy completes.  implies --wait")
+		cmd.Flags().Duration("timeout", 0, "maximum time to wait for the copy to complete, e.g. 12h.  0 waits until the copy completes")
+	}
+
+	sandboxCmd.AddCommand(sandboxCreateCmd)
+	sandboxCmd.AddCommand(sandboxRefreshCmd)
+	sandboxCmd.AddCommand(sandboxCloneCmd)
+	sandboxCmd.AddCommand(sandboxStatusCmd)
+	sandboxCmd.AddCommand(sandboxListCmd)
+	RootCmd.AddCommand(sandboxCmd)
+}
+
+var sandboxCmd = &cobra.Command{
+	Use:   "sandbox",
+	Short: "Create, refresh, and clone sandboxes",
+	Long: `
+Create, refresh, and clone the sandboxes of the active production org, and
+monitor their progress.
+`,
+	Example: `
+  force sandbox create qa --license-type Developer_Pro --wait
+  force sandbox refresh qa --post-copy-class SandboxSetup --login
+  force sandbox clone qa uat
+  force sandbox status qa --wait
+  force sandbox list
+`,
+}
+
+var sandboxCreateCmd = &cobra.Command{
+	Use:   "create [flags] <name>",
+	Short: "Create a sandbox",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		options := sandboxOptions(cmd)
+		if options.LicenseType == "" {
+			options.LicenseType = "DEVELOPER"
+		}
+		sandboxInfoId, err := force.CreateSandbox(args[0], options)
+		if err != nil {
+			ErrorAndExit(err.Error())
+		}
+		fmt.Printf("Sandbox %s creation started\n", args[0])
+		waitForSandboxIfRequested(cmd, sandboxInfoId)
+	},
+}
+
+var sandboxRefreshCmd = &cobra.Command{
+	Use:   "refresh [flags] <name>",
+	Short: "Refresh a sandbox",
+	Long: `
+Refresh an existing sandbox.  The license type defaults to the sandbox's
+current license type.
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		options := sandboxOptions(cmd)
+		sandboxInfoId, err := force.RefreshSandbox(args[0], options)
+		if err != nil {
+			ErrorAndExit(err.Error())
+		}
+		fmt.Printf("Sandbox %s refresh started\n", args[0])
+		waitForSandboxIfRequested(cmd, sandboxInfoId)
+	},
+}
+
+var sandboxCloneCmd = &cobra.Command{
+	Use:   "clone [flags] <source> <name>",
+	Short: "Create a sandbox by copying an existing sandbox",
+	Long: `
+Create a new sandbox by copying an existing sandbox rather than production.
+The license type defaults to the source sandbox's license type.
+`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		options := sandboxOptions(cmd)
+		sandboxInfoId, err := force.CloneSandbox(args[0], args[1], options)
+		if err != nil {
+			ErrorAndExit(err.Error())
+		}
+		fmt.Printf("Sandbox %s clone of %s started\n", args[1], args[0])
+		waitForSandboxIfRequested(cmd, sandboxInfoId)
+	},
+}
+
+var sandboxStatusCmd = &cobra.Command{
+	Use:   "status [flags] <name>",
+	Short: "Display the progress of a sandbox copy",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		info, err := force.GetSandboxInfo(args[0])
+		if err != nil {
+			ErrorAndExit(err.Error())
+		}
+		wait, _ := cmd.Flags().GetBool("wait")
+		login, _ := cmd.Flags().GetBool("login")
+		if wait || login {
+			waitForSandboxIfRequested(cmd, info.Id)
+			return
+		}
+		process, err := force.GetSandboxProcess(info.Id)
+		if err != nil {
+			ErrorAndExit(err.Error())
+		}
+		displaySandboxProcess(process)
+	},
+}
+
+var sandboxListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List sandboxes",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		sandboxes, err := force.QuerySandboxInfos()
+		if err != nil {
+			ErrorAndExit(err.Error())
+		}
+		processes, err := force.QuerySandboxProcesses()
+		if err != nil {
+			ErrorAndExit(err.Error())
+		}
+		displaySandboxes(sandboxes, processes)
+	},
+}
+
+func sandboxOptions(cmd *cobra.Command) SandboxOptions {
+	var options SandboxOptions
+	licenseType, _ := cmd.Flags().GetString("license-type")
+	if licenseType != "" {
+		var err error
+		if options.LicenseType, err = SandboxLicenseType(licenseType); err != nil {
+			ErrorAndExit(err.Error())
+		}
+	}
+	options.Description, _ = cmd.Flags().GetString("description")
+	options.TemplateId, _ = cmd.Flags().GetString("template")
+	options.HistoryDays, _ = cmd.Flags().GetInt("history-days")
+	if cmd.Flags().Changed("copy-chatter") {
+		copyChatter, _ := cmd.Flags().GetBool("copy-chatter")
+		options.CopyChatter = &copyChatter
+	}
+	if cmd.Flags().Changed("auto-activate") {
+		autoActivate, _ := cmd.Flags().GetBool("auto-activate")
+		options.AutoActivate = &autoActivate
+	}
+	postCopyClass, _ := cmd.Flags().GetString("post-copy-class")
+	switch {
+	case postCopyClass == "":
+	case strings.HasPrefix(postCopyClass, "01p"):
+		options.ApexClassId = postCopyClass
+	default:
+		var err error
+		if options.ApexClassId, err = force.GetApexClassId(postCopyClass); err != nil {
+			ErrorAndExit(err.Error())
+		}
+	}
+	return options
+}
+
+func waitForSandboxIfRequested(cmd *cobra.Command, sandboxInfoId string) {
+	wait, _ := cmd.Flags().GetBool("wait")
+	login, _ := cmd.Flags().GetBool("login")
+	if !wait && !login {
+		return
+	}
+	ctx, cancel := waitContext(cmd)
+	defer cancel()
+	process, err := waitForSandbox(ctx, sandboxInfoId)
+	if err != nil {
+		ErrorAndExit(err.Error())
+	}
+	fmt.Printf("Sandbox %s is ready\n", process.SandboxName)
+	if login {
+		loginToSandbox(process)
+	}
+}
+
+// waitContext returns a context that is done after the duration of the
+// command's --timeout flag, or never if it's 0
+func waitContext(cmd *cobra.Command) (context.Context, context.CancelFunc) {
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	if timeout <= 0 {
+		return context.WithCancel(context.Background())
+	}
+	return context.WithTimeout(context.Background(), timeout)
+}
+
+func waitForSandbox(ctx context.Context, sandboxInfoId string) (SandboxProcess, error) {
+	var process SandboxProcess
+	var err error
+	done := make(chan struct{})
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	p := tea.NewProgram(bubbles.NewSandboxModel(), tea.WithOutput(os.Stderr))
+	go func() {
+		process, err = force.WaitForSandbox(ctx, sandboxInfoId, 30*time.Second, func(status SandboxProcess) {
+			p.Send(bubbles.NewSandboxStatusMsg{SandboxProcess: status})
+		})
+		close(done)
+		p.Send(bubbles.QuitMsg{})
+	}()
+	if _, runErr := p.Run(); runErr != nil {
+		return process, runErr
+	}
+	select {
+	case <-done:
+		return process, err
+	default:
+		return process, fmt.Errorf("Stopped waiting for sandbox.  Use force sandbox status to check its progress.")
+	}
+}
+
+func loginToSandbox(process SandboxProcess) {
+	if force.Credentials.UserInfo != nil {
+		fmt.Printf("Log in as %s\n", SandboxUsername(force.Credentials.UserInfo.UserName, process.SandboxName))
+	}
+	username, err := ForceLoginAtEndpointAndSave("https://test.salesforce.com", os.Stdout)
+	if err != nil {
+		ErrorAndExit(err.Error())
+	}
+	fmt.Printf("Logged in as %s\n", username)
+}
+
+func displaySandboxProcess(p SandboxProcess) {
+	fmt.Printf("Name: %s\n", p.SandboxName)
+	fmt.Printf("License Type: %s\n", p.LicenseType)
+	fmt.Printf("Status: %s\n", p.Status)
+	fmt.Printf("Copy Progress: %d%%\n", p.CopyProgress)
+	if p.SandboxOrganization != "" {
+		fmt.Printf("Org Id: %s\n", p.SandboxOrganization)
+	}
+	fmt.Printf("Started: %s\n", p.StartDate)
+	if p.EndDate != "" {
+		fmt.Printf("Completed: %s\n", p.EndDate)
+	}
+}
+
+func displaySandboxes(sandboxes []SandboxInfo, processes []SandboxProcess) {
+	latest := make(map[string]SandboxProcess)
+	for _, p := range processes {
+		latest[p.SandboxInfoId] = p
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Name", "License Type", "Status", "Progress", "Org Id", "Completed", "Description"})
+	table.SetAutoWrapText(false)
+	for _, s := range sandboxes {
+		p := latest[s.Id]
+		progress := ""
+		if p.Id != "" {
+			progress = strconv.Itoa(p.CopyProgress) + "%"
+		}
+		table.Append([]string{
+			s.SandboxName,
+			s.LicenseType,
+			p.Status,
+			progress,
+			p.SandboxOrganization,
+			p.EndDate,
+			s.Description,
+		})
+	}
+	table.Render()
+}
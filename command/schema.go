@@ -0,0 +1,106 @@
+package command
+
+import (
+	"fmt"
+	"os"
+
+	. "github.com/ForceCLI/force/error"
+	. "github.com/ForceCLI/force/lib"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	schemaApplyCmd.Flags().Bool("plan", false, "show the changes that would be made without making them")
+
+	schemaCmd.AddCommand(schemaApplyCmd)
+	RootCmd.AddCommand(schemaCmd)
+}
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Manage objects and fields declaratively",
+}
+
+var schemaApplyCmd = &cobra.Command{
+	Use:   "apply [flags] <file>",
+	Short: "Create and update objects, fields, and field-level security from a YAML spec",
+	Long: `
+Create and update custom objects, custom fields, and field-level security to
+match a YAML spec.  The spec is compared to the org's describe results and
+field permissions, and only the differences are deployed.
+
+Objects ending in __c are created if they don't exist.  Fields are created or
+updated; fields that are not in the spec are left alone.  Field permissions
+map profile names to none, read, or edit.
+
+Example spec:
+
+  objects:
+    - name: Invoice__c
+      label: Invoice
+      nameField:
+        type: AutoNumber
+        displayFormat: INV-{00000}
+      fields:
+        - name: Amount__c
+          type: Currency
+          required: true
+          permissions:
+            System Administrator: edit
+            Standard User: read
+        - name: Status__c
+          type: Picklist
+          values: [Draft, Sent, Paid]
+        - name: Account__c
+          type: Lookup
+          referenceTo: Account
+          relationshipName: Invoices
+    - name: Account
+      fields:
+        - name: Region__c
+          type: Text
+          length: 40
+
+Supported field types are Text, TextArea, LongTextArea, Html, Number,
+Currency, Percent, Checkbox, Date, DateTime, Email, Phone, Url, Picklist,
+MultiselectPicklist, Lookup, and MasterDetail.
+`,
+	Example: `
+  force schema apply schema.yaml --plan
+  force schema apply schema.yaml
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		planOnly, _ := cmd.Flags().GetBool("plan")
+		runSchemaApply(args[0], planOnly)
+	},
+}
+
+func runSchemaApply(file string, planOnly bool) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		ErrorAndExit(err.Error())
+	}
+	spec, err := ParseSchemaSpec(data)
+	if err != nil {
+		ErrorAndExit(err.Error())
+	}
+	plan, err := force.PlanSchema(spec)
+	if err != nil {
+		ErrorAndExit(err.Error())
+	}
+	if len(plan.Changes) == 0 {
+		fmt.Println("Schema is up to date")
+		return
+	}
+	for _, c := range plan.Changes {
+		fmt.Println(c)
+	}
+	if planOnly {
+		return
+	}
+	if err := force.ApplySchema(plan); err != nil {
+		ErrorAndExit(err.Error())
+	}
+	fmt.Printf("Applied %d changes\n", len(plan.Changes))
+}
@@ -0,0 +1,73 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	. "github.com/ForceCLI/force/error"
+	. "github.com/ForceCLI/force/lib"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	schemaDiagramCmd.Flags().StringP("format", "f", "mermaid", "output format: mermaid, dot, or plantuml")
+	setOutputFormats(schemaDiagramCmd, "mermaid", "dot", "plantuml")
+	schemaDiagramCmd.Flags().BoolP("fields", "k", false, "include key fields: Id, name, reference, and unique fields")
+	schemaDiagramCmd.Flags().StringP("output", "o", "", "write the diagram to `file` instead of stdout")
+
+	schemaCmd.AddCommand(schemaDiagramCmd)
+}
+
+var schemaDiagramCmd = &cobra.Command{
+	Use:   "diagram [flags] <object>...",
+	Short: "Generate an entity-relationship diagram",
+	Long: `
+Generate an entity-relationship diagram of the lookup and master-detail
+relationships between the given objects.  Relationships to objects that are
+not listed are omitted.
+
+Mermaid and PlantUML diagrams use crow's foot notation, with a required
+parent for master-detail and required lookup relationships.  In Graphviz
+diagrams, master-detail relationships are bold, required lookups are solid,
+and optional lookups are dashed.
+`,
+	Example: `
+  force schema diagram Account Contact Opportunity
+  force schema diagram Account Invoice__c Invoice_Line__c --fields -f dot | dot -Tpng > schema.png
+  force schema diagram Account Contact -f plantuml -o schema.puml
+`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		format, _ := cmd.Flags().GetString("format")
+		keyFields, _ := cmd.Flags().GetBool("fields")
+		output, _ := cmd.Flags().GetString("output")
+		runSchemaDiagram(args, format, keyFields, output)
+	},
+}
+
+func runSchemaDiagram(objects []string, format string, keyFields bool, output string) {
+	var render func(SchemaDiagram) string
+	switch strings.ToLower(format) {
+	case "mermaid":
+		render = SchemaDiagram.Mermaid
+	case "dot", "graphviz":
+		render = SchemaDiagram.Dot
+	case "plantuml":
+		render = SchemaDiagram.PlantUML
+	default:
+		ErrorAndExit("Invalid format: %s", format)
+	}
+	describes, err := force.DescribeSObjects(objects)
+	if err != nil {
+		ErrorAndExit(err.Error())
+	}
+	diagram := render(NewSchemaDiagram(describes, keyFields))
+	if output == "" {
+		fmt.Print(diagram)
+		return
+	}
+	if err := os.WriteFile(output, []byte(diagram), 0644); err != nil {
+		ErrorAndExit(err.Error())
+	}
+}
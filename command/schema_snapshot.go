@@ -0,0 +1,154 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	. "github.com/ForceCLI/force/error"
+	. "github.com/ForceCLI/force/lib"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	schemaSnapshotCmd.Flags().StringP("output", "o", "", "write the snapshot to `file` instead of stdout")
+
+	schemaDriftCmd.Flags().StringSliceP("object", "s", []string{}, "object to compare")
+	schemaDriftCmd.Flags().Bool("exit-code", false, "exit with status 1 if there are differences")
+
+	schemaCmd.AddCommand(schemaSnapshotCmd)
+	schemaCmd.AddCommand(schemaDriftCmd)
+}
+
+var schemaSnapshotCmd = &cobra.Command{
+	Use:   "snapshot [flags] [object]...",
+	Short: "Save the describe results of objects as JSON",
+	Long: `
+Save the objects and fields of the org, including their types, lengths, and
+picklist values, as JSON.  All queryable objects are included unless objects
+are specified.  Use "force schema drift" to compare a snapshot to an org or to
+another snapshot.
+`,
+	Example: `
+  force schema snapshot -o prod-schema.json
+  force schema snapshot Account Contact Invoice__c > schema.json
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+		snapshot, err := force.TakeSchemaSnapshot(args)
+		if err != nil {
+			ErrorAndExit(err.Error())
+		}
+		data, err := json.MarshalIndent(snapshot, "", "  ")
+		if err != nil {
+			ErrorAndExit(err.Error())
+		}
+		data = append(data, '\n')
+		if output == "" {
+			os.Stdout.Write(data)
+			return
+		}
+		if err := os.WriteFile(output, data, 0644); err != nil {
+			ErrorAndExit(err.Error())
+		}
+	},
+}
+
+var schemaDriftCmd = &cobra.Command{
+	Use:   "drift [flags] [snapshot] [snapshot]",
+	Short: "Report objects and fields added, removed, or changed",
+	Long: `
+Compare two schemas and report the objects and fields that were added,
+removed, or changed.  Each schema can be a snapshot file saved by "force
+schema snapshot" or a live org.
+
+With one snapshot, the snapshot is compared to the active org, or the org
+specified with --account.  With two snapshots, the snapshots are compared.
+With no snapshots, the two orgs specified with --account are compared.
+
+When describing a live org, the objects in the snapshot are compared.  Use
+--object to compare only the specified objects.
+`,
+	Example: `
+  force schema drift prod-schema.json
+  force schema drift prod-schema.json -a admin@example.com --exit-code
+  force schema drift last-week.json today.json
+  force schema drift -a admin@example.com -a admin@example.com.uat -s Account -s Invoice__c
+`,
+	Args:        cobra.MaximumNArgs(2),
+	Annotations: map[string]string{multipleAccountsAnnotation: "true"},
+	Run: func(cmd *cobra.Command, args []string) {
+		objects, _ := cmd.Flags().GetStringSlice("object")
+		exitCode, _ := cmd.Flags().GetBool("exit-code")
+		runSchemaDrift(args, objects, exitCode)
+	},
+}
+
+func runSchemaDrift(files []string, objects []string, exitCode bool) {
+	var snapshots []SchemaSnapshot
+	for _, file := range files {
+		snapshot, err := readSchemaSnapshot(file)
+		if err != nil {
+			ErrorAndExit(err.Error())
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	if len(objects) == 0 && len(snapshots) > 0 {
+		objects = snapshots[0].ObjectNames()
+	} else {
+		for i := range snapshots {
+			snapshots[i] = snapshots[i].Only(objects)
+		}
+	}
+
+	var orgs []*Force
+	switch {
+	case len(snapshots) == 2 && len(accountNames) > 0:
+		ErrorAndExit("Specify two snapshots, a snapshot and an account, or two accounts")
+	case len(snapshots) == 1 && len(accountNames) > 1:
+		ErrorAndExit("Specify one account to compare with %s", files[0])
+	case len(snapshots) == 1:
+		orgs = []*Force{force}
+	case len(snapshots) == 0 && len(accountNames) != 2:
+		ErrorAndExit("Specify two accounts to compare, or a snapshot")
+	case len(snapshots) == 0:
+		for _, account := range accountNames {
+			org, err := GetForce(account)
+			if err != nil {
+				ErrorAndExit(err.Error())
+			}
+			orgs = append(orgs, org)
+		}
+	}
+	for _, org := range orgs {
+		snapshot, err := org.TakeSchemaSnapshot(objects)
+		if err != nil {
+			ErrorAndExit(err.Error())
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	drift := CompareSchemaSnapshots(snapshots[0], snapshots[1])
+	if len(drift) == 0 {
+		fmt.Println("No differences")
+		return
+	}
+	for _, d := range drift {
+		fmt.Println(d)
+	}
+	if exitCode {
+		os.Exit(1)
+	}
+}
+
+func readSchemaSnapshot(file string) (SchemaSnapshot, error) {
+	var snapshot SchemaSnapshot
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return snapshot, err
+	}
+	if err = json.Unmarshal(data, &snapshot); err != nil {
+		return snapshot, fmt.Errorf("Invalid snapshot %s: %w", file, err)
+	}
+	return snapshot, nil
+}
@@ -0,0 +1,217 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	. "github.com/ForceCLI/force/config"
+	. "github.com/ForceCLI/force/error"
+	. "github.com/ForceCLI/force/lib"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	scratchListCmd.Flags().Bool("all", false, "include deleted and expired scratch orgs")
+
+	scratchDeleteCmd.Flags().Bool("expired", false, "remove saved logins for expired and deleted scratch orgs")
+	scratchDeleteCmd.Flags().Int("unused", 0, "delete scratch orgs not logged into in this many `days`")
+	scratchDeleteCmd.Flags().BoolP("dry-run", "n", false, "show what would be deleted without deleting")
+
+	scratchOrgCmd.AddCommand(scratchListCmd)
+	scratchOrgCmd.AddCommand(scratchDeleteCmd)
+	scratchOrgCmd.AddCommand(scratchOpenCmd)
+	RootCmd.AddCommand(scratchOrgCmd)
+}
+
+var scratchOrgCmd = &cobra.Command{
+	Use:   "scratch",
+	Short: "Manage scratch orgs created from the active Dev Hub",
+	Long: `
+Manage the scratch orgs created from the active Dev Hub org.  Use "force login
+scratch" to create a new scratch org.
+`,
+	Example: `
+  force scratch list
+  force scratch delete scratch-user@example.com
+  force scratch delete --expired --unused 7
+  force scratch open scratch-user@example.com
+`,
+}
+
+var scratchListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List scratch orgs",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		all, _ := cmd.Flags().GetBool("all")
+		orgs, err := force.QueryScratchOrgInfos(all)
+		if err != nil {
+			ErrorAndExit(err.Error())
+		}
+		displayScratchOrgs(orgs)
+	},
+}
+
+var scratchDeleteCmd = &cobra.Command{
+	Use:   "delete [flags] [username]...",
+	Short: "Delete scratch orgs and their saved logins",
+	Args:  cobra.ArbitraryArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		expired, _ := cmd.Flags().GetBool("expired")
+		unusedDays, _ := cmd.Flags().GetInt("unused")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		if len(args) == 0 && !expired && unusedDays == 0 {
+			ErrorAndExit("Specify scratch org usernames, --expired, or --unused")
+		}
+		runDeleteScratchOrgs(args, expired, unusedDays, dryRun)
+	},
+}
+
+var scratchOpenCmd = &cobra.Command{
+	Use:   "open <username>",
+	Short: "Open a browser window, logged into a scratch org",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if !hasSavedLogin(args[0]) {
+			ErrorAndExit("No saved login for %s.  Scratch orgs can only be opened if they were created with force login scratch.", args[0])
+		}
+		var err error
+		force, err = GetForce(args[0])
+		if err != nil {
+			ErrorAndExit(err.Error())
+		}
+		runOpen("")
+	},
+}
+
+func hasSavedLogin(username string) bool {
+	_, err := Config.Load("accounts", username)
+	return err == nil
+}
+
+// scratchOrgDaysLeft returns the number of days until a scratch org expires
+func scratchOrgDaysLeft(expirationDate string, now time.Time) (int, bool) {
+	expires, err := time.Parse("2006-01-02", expirationDate)
+	if err != nil {
+		return 0, false
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return int(expires.Sub(today).Hours() / 24), true
+}
+
+// scratchOrgUnused returns true if the org hasn't been logged into within
+// days of now
+func scratchOrgUnused(org ActiveScratchOrg, days int, now time.Time) bool {
+	if org.LastLoginDate == "" {
+		return true
+	}
+	lastLogin, err := time.Parse("2006-01-02T15:04:05.000-0700", org.LastLoginDate)
+	if err != nil {
+		return false
+	}
+	return now.Sub(lastLogin) > time.Duration(days)*24*time.Hour
+}
+
+func displayScratchOrgs(orgs []ScratchOrgInfo) {
+	now := time.Now()
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Org Name", "Username", "Org Id", "Edition", "Status", "Expires", "Days Left", "Saved Login"})
+	table.SetAutoWrapText(false)
+	for _, o := range orgs {
+		daysLeft := ""
+		if d, ok := scratchOrgDaysLeft(o.ExpirationDate, now); ok && o.Status == "Active" {
+			daysLeft = strconv.Itoa(d)
+		}
+		saved := ""
+		if hasSavedLogin(o.SignupUsername) {
+			saved = "yes"
+		}
+		table.Append([]string{
+			o.OrgName,
+			o.SignupUsername,
+			o.ScratchOrg,
+			o.Edition,
+			o.Status,
+			o.ExpirationDate,
+			daysLeft,
+			saved,
+		})
+	}
+	table.Render()
+}
+
+func runDeleteScratchOrgs(usernames []string, expired bool, unusedDays int, dryRun bool) {
+	activeOrgs, err := force.QueryActiveScratchOrgs()
+	if err != nil {
+		ErrorAndExit(err.Error())
+	}
+	now := time.Now()
+	toDelete := make(map[string]ActiveScratchOrg)
+	for _, username := range usernames {
+		found := false
+		for _, o := range activeOrgs {
+			if o.SignupUsername == username {
+				toDelete[username] = o
+				found = true
+			}
+		}
+		if !found {
+			ErrorAndExit("No active scratch org with username %s", username)
+		}
+	}
+	if unusedDays > 0 {
+		for _, o := range activeOrgs {
+			if scratchOrgUnused(o, unusedDays, now) {
+				toDelete[o.SignupUsername] = o
+			}
+		}
+	}
+
+	loginErrors := 0
+	for username, o := range toDelete {
+		fmt.Printf("Deleting scratch org %s (%s)\n", username, o.ScratchOrg)
+		if dryRun {
+			continue
+		}
+		if err := force.DeleteScratchOrg(o.Id); err != nil {
+			ErrorAndExit(err.Error())
+		}
+		if hasSavedLogin(username) {
+			loginErrors += removeSavedLogin(username)
+		}
+	}
+
+	if expired {
+		orgs, err := force.QueryScratchOrgInfos(true)
+		if err != nil {
+			ErrorAndExit(err.Error())
+		}
+		for _, o := range orgs {
+			daysLeft, ok := scratchOrgDaysLeft(o.ExpirationDate, now)
+			isExpired := o.Status == "Deleted" || (ok && daysLeft < 0)
+			if !isExpired || !hasSavedLogin(o.SignupUsername) {
+				continue
+			}
+			fmt.Printf("Removing saved login for expired scratch org %s\n", o.SignupUsername)
+			if !dryRun {
+				loginErrors += removeSavedLogin(o.SignupUsername)
+			}
+		}
+	}
+	if loginErrors > 0 {
+		ErrorAndExit("Could not remove %d saved login(s)", loginErrors)
+	}
+}
+
+// removeSavedLogin deletes the saved login for username, reporting any error,
+// and returns the number of errors
+func removeSavedLogin(username string) int {
+	if err := DeleteLogin(username); err != nil {
+		fmt.Fprintf(os.Stderr, "Could not remove saved login for %s: %s\n", username, err.Error())
+		return 1
+	}
+	return 0
+}
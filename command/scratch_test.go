@@ -0,0 +1,37 @@
+package command
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ForceCLI/force/lib"
+)
+
+func TestScratchOrgDaysLeft(t *testing.T) {
+	now := time.Date(2024, 5, 1, 18, 30, 0, 0, time.UTC)
+	if d, ok := scratchOrgDaysLeft("2024-05-08", now); !ok || d != 7 {
+		t.Errorf("expected 7 days left, got %d", d)
+	}
+	if d, ok := scratchOrgDaysLeft("2024-04-30", now); !ok || d != -1 {
+		t.Errorf("expected expired org, got %d", d)
+	}
+	if _, ok := scratchOrgDaysLeft("", now); ok {
+		t.Error("expected missing expiration date to be unknown")
+	}
+}
+
+func TestScratchOrgUnused(t *testing.T) {
+	now := time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)
+	recent := lib.ActiveScratchOrg{LastLoginDate: "2024-05-08T12:00:00.000+0000"}
+	stale := lib.ActiveScratchOrg{LastLoginDate: "2024-04-20T12:00:00.000+0000"}
+	never := lib.ActiveScratchOrg{}
+	if scratchOrgUnused(recent, 7, now) {
+		t.Error("recently used org should not be unused")
+	}
+	if !scratchOrgUnused(stale, 7, now) {
+		t.Error("stale org should be unused")
+	}
+	if !scratchOrgUnused(never, 7, now) {
+		t.Error("org never logged into should be unused")
+	}
+}
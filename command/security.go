@@ -1,364 +1,281 @@
 package command
 
 import (
-	"container/list"
-	"encoding/xml"
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
+	"html"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/ForceCLI/force/desktop"
 	. "github.com/ForceCLI/force/error"
 	. "github.com/ForceCLI/force/lib"
+	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
 )
 
-////////////////////////////////////////////////////////////////////////
-// Parse the permissions for a given profile and return a Profile struct
-////////////////////////////////////////////////////////////////////////
-
 func init() {
+	securityCmd.Flags().StringP("format", "f", "", "output format: html, csv, or json.  --compare also supports table, the default")
+	setOutputFormats(securityCmd, "csv", "json")
+	securityCmd.Flags().StringP("user", "u", "", "show the effective access of a user from their profile, permission sets, and permission set groups")
+	securityCmd.Flags().Bool("compare", false, "compare the access granted by two profiles, permission sets, or permission set groups")
 	RootCmd.AddCommand(securityCmd)
 }
 
 var securityCmd = &cobra.Command{
-	Use:   "security [SObject]",
+	Use:   "security [flags] <SObject> | --compare <name> <name> [SObject...]",
 	Short: "Displays the OLS and FLS for a given SObject",
+	Long: `
+Display the object-level and field-level security granted on an SObject by
+each profile, permission set, and permission set group.  Profiles, permission
+sets, and groups with identical access are grouped together in the HTML
+output, which is written to security.html and opened in a browser.
+
+Use --user to show the effective access of a single user, combining their
+profile, permission sets, and permission set groups.
+
+Use --compare to list the object and field permissions that differ between
+two profiles, permission sets, or permission set groups.  If no SObjects are
+given, all objects are compared.
+`,
 	Example: `
   force security Case
+  force security Case -f csv > case-security.csv
+  force security Case --user jane@example.com -f json
+  force security --compare "System Administrator" "Standard User"
+  force security --compare Sales_Ops Support Case Account
 `,
-	Args: cobra.ExactArgs(1),
+	Args: func(cmd *cobra.Command, args []string) error {
+		if compare, _ := cmd.Flags().GetBool("compare"); compare {
+			return cobra.MinimumNArgs(2)(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
 	Run: func(cmd *cobra.Command, args []string) {
-		runSecurity(args[0])
+		format, _ := cmd.Flags().GetString("format")
+		user, _ := cmd.Flags().GetString("user")
+		compare, _ := cmd.Flags().GetBool("compare")
+		if compare {
+			if user != "" {
+				ErrorAndExit("--user cannot be combined with --compare")
+			}
+			runSecurityCompare(args[0], args[1], args[2:], format)
+			return
+		}
+		runSecurity(args[0], user, format)
 	},
 }
 
-type XLS interface {
-	addProperty(name string, value string)
-	addToProfile(p Profile)
-}
-
-type OLS struct {
-	objectName       string
-	allowCreate      string
-	allowDelete      string
-	allowEdit        string
-	allowRead        string
-	modifyAllRecords string
-	viewAllRecords   string
-}
-
-func (o *OLS) addProperty(name string, value string) {
-	switch name {
-	case "object":
-		o.objectName = value
-	case "allowCreate":
-		o.allowCreate = value
-	case "allowDelete":
-		o.allowDelete = value
-	case "allowEdit":
-		o.allowEdit = value
-	case "allowRead":
-		o.allowRead = value
-	case "modifyAllRecords":
-		o.modifyAllRecords = value
-	case "viewAllRecords":
-		o.viewAllRecords = value
+func runSecurity(sobject string, user string, format string) {
+	if format == "" {
+		format = "html"
 	}
-}
-func (o *OLS) getProperty(name string) string {
-	switch name {
-	case "Allow Create":
-		return o.allowCreate
-	case "Allow Delete":
-		return o.allowDelete
-	case "Allow Edit":
-		return o.allowEdit
-	case "Allow Read":
-		return o.allowRead
-	case "Modify All Records":
-		return o.modifyAllRecords
-	case "View All Records":
-		return o.viewAllRecords
+	if format != "html" && format != "csv" && format != "json" {
+		ErrorAndExit("Invalid format: %s", format)
 	}
-	return ""
-}
-func (o *OLS) addToProfile(p Profile) {
-	p.objectPermissions[o.objectName] = *o
-}
+	objects := []string{sobject}
 
-type FLS struct {
-	field    string
-	editable string
-	readable string
-}
+	var grants []SecurityGrant
+	if user != "" {
+		grant, err := force.UserSecurityGrant(user, objects)
+		if err != nil {
+			ErrorAndExit(err.Error())
+		}
+		grants = []SecurityGrant{grant}
+	} else {
+		var err error
+		grants, err = force.QuerySecurityGrants("", objects)
+		if err != nil {
+			ErrorAndExit(err.Error())
+		}
+	}
 
-func (f *FLS) addProperty(name string, value string) {
-	switch name {
-	case "field":
-		f.field = value
-	case "editable":
-		f.editable = value
-	case "readable":
-		f.readable = value
+	sobj, err := force.GetSobject(sobject)
+	if err != nil {
+		ErrorAndExit(err.Error())
 	}
-	//	fmt.Println("Field Property " + name + "=" + value)
-}
-func (f *FLS) addToProfile(p Profile) {
-	p.fieldPermissions[f.field] = *f
-}
+	sobject, _ = sobj["name"].(string)
+	fields := permissionableFields(sobj)
 
-type Profile struct {
-	name              string
-	fieldPermissions  map[string]FLS
-	objectPermissions map[string]OLS
+	switch format {
+	case "json":
+		out, err := json.MarshalIndent(grants, "", "  ")
+		if err != nil {
+			ErrorAndExit(err.Error())
+		}
+		fmt.Println(string(out))
+	case "csv":
+		displaySecurityCSV(grants, sobject, fields)
+	default:
+		displaySecurityHTML(grants, sobject, fields)
+	}
 }
 
-func parseProfileXML(profileName string, text string) Profile {
-	p := new(Profile)
-	p.name = profileName
-	p.fieldPermissions = map[string]FLS{}
-	p.objectPermissions = map[string]OLS{}
-	var currentElement XLS
-
-	r := strings.NewReader(text)
-	parser := xml.NewDecoder(r)
-	depth := 0
-
-	eltType := ""
-	propertyName := ""
-
-	for {
-
-		token, err := parser.Token()
-		if err != nil {
-			break
+// permissionableFields returns the names of the fields of an object whose
+// field-level security can be set
+func permissionableFields(sobject ForceSobject) []string {
+	var names []string
+	fields, _ := sobject["fields"].([]interface{})
+	for _, f := range fields {
+		field, ok := f.(map[string]interface{})
+		if !ok {
+			continue
 		}
-		switch t := token.(type) {
-		case xml.StartElement:
-			elmt := xml.StartElement(t)
-			name := elmt.Name.Local
-			if depth == 1 {
-				eltType = name
-				if eltType == "objectPermissions" {
-					currentElement = new(OLS)
-				} else if eltType == "fieldPermissions" {
-					currentElement = new(FLS)
-				} else {
-					currentElement = nil
-				}
-			}
-			if depth == 2 {
-				propertyName = name
-			}
-			depth++
-		case xml.EndElement:
-			if depth == 2 && currentElement != nil {
-				currentElement.addToProfile(*p)
-			}
-			depth--
-		case xml.CharData:
-			bytes := xml.CharData(t)
-			if currentElement != nil && depth == 3 {
-				currentElement.addProperty(propertyName, string(bytes))
-			}
-		default:
+		if permissionable, _ := field["permissionable"].(bool); permissionable {
+			names = append(names, fmt.Sprint(field["name"]))
 		}
 	}
-
-	//	fmt.Println(p)
-
-	return *p
+	return names
 }
 
-//////////////////////////////////////////////////////////////////////
-// Read information about an SObject and returns a CustomObject struct
-//////////////////////////////////////////////////////////////////////
-
-type CustomObject struct {
-	objectName string
-	fieldNames []string
-	nbFields   int
+func securityCSVRows(grants []SecurityGrant, sobject string, fields []string) [][]string {
+	rows := [][]string{append([]string{"Name", "Type", "Object", "Field"}, ObjectPermissionNames()...)}
+	for _, g := range grants {
+		row := []string{g.Name, g.Type, sobject, ""}
+		access := g.Objects[sobject]
+		for _, p := range ObjectPermissionNames() {
+			row = append(row, strconv.FormatBool(access.Permission(p)))
+		}
+		rows = append(rows, row)
+		for _, f := range fields {
+			fieldAccess := g.Fields[sobject+"."+f]
+			rows = append(rows, []string{g.Name, g.Type, sobject, f, "", strconv.FormatBool(fieldAccess.Read), strconv.FormatBool(fieldAccess.Edit), "", "", ""})
+		}
+	}
+	return rows
 }
 
-func (co *CustomObject) addField(name string) {
-	co.fieldNames[co.nbFields] = name
-	co.nbFields++
+func displaySecurityCSV(grants []SecurityGrant, sobject string, fields []string) {
+	w := csv.NewWriter(os.Stdout)
+	w.WriteAll(securityCSVRows(grants, sobject, fields))
 }
-func (co *CustomObject) getProfileFootprint(p Profile) string {
-	key := "OLS:" + p.objectPermissions[co.objectName].allowCreate + "," +
-		p.objectPermissions[co.objectName].allowDelete + "," +
-		p.objectPermissions[co.objectName].allowEdit + "," +
-		p.objectPermissions[co.objectName].allowRead + "," +
-		p.objectPermissions[co.objectName].modifyAllRecords + "," +
-		p.objectPermissions[co.objectName].viewAllRecords + ","
 
-	for idx := 0; idx < co.nbFields; idx++ {
-		f := co.fieldNames[idx]
-		key += f + ":" + p.fieldPermissions[co.objectName+"."+f].editable + "," +
-			p.fieldPermissions[co.objectName+"."+f].readable + ","
+// securityFootprint returns a key that is identical for grants with the same
+// access to the object and its fields
+func securityFootprint(g SecurityGrant, sobject string, fields []string) string {
+	key := fmt.Sprintf("%+v", g.Objects[sobject])
+	for _, f := range fields {
+		key += "," + g.Fields[sobject+"."+f].String()
 	}
 	return key
 }
 
-func parseCustomObjectXML(objectName string, text string) CustomObject {
-	obj := CustomObject{objectName: objectName, nbFields: 0, fieldNames: make([]string, 900, 900)}
-	r := strings.NewReader(text)
-	parser := xml.NewDecoder(r)
-	depth := 0
-	var firstLevel, secondLevel string
-
-	for {
-
-		token, err := parser.Token()
-		if err != nil {
-			break
-		}
-		switch t := token.(type) {
-		case xml.StartElement:
-			elmt := xml.StartElement(t)
-			name := elmt.Name.Local
-			if depth == 1 {
-				firstLevel = name
-			} else if depth == 2 {
-				secondLevel = name
-			}
-			depth++
-		case xml.EndElement:
-			if depth == 3 {
-				secondLevel = ""
-			} else if depth == 2 {
-				firstLevel = ""
-			}
-			depth--
-		case xml.CharData:
-			bytes := xml.CharData(t)
-			if depth == 3 && firstLevel == "fields" && secondLevel == "fullName" {
-				obj.addField(string(bytes))
-			}
-		default:
+// groupSecurityGrants groups grants with identical access to the object and
+// its fields, preserving the order in which each group first appears
+func groupSecurityGrants(grants []SecurityGrant, sobject string, fields []string) [][]SecurityGrant {
+	var groups [][]SecurityGrant
+	index := make(map[string]int)
+	for _, g := range grants {
+		key := securityFootprint(g, sobject, fields)
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, nil)
 		}
+		groups[i] = append(groups[i], g)
 	}
-
-	//	fmt.Println(obj)
-	return obj
+	return groups
 }
 
-/////////////////////////////////////////////////////////
-
-func runSecurity(sobject string) {
-	wd, _ := os.Getwd()
-	root := filepath.Join(wd, ".")
-
-	query := ForceMetadataQuery{
-		{Name: []string{"Profile"}, Members: []string{"*"}},
-		{Name: []string{"CustomObject"}, Members: []string{sobject}},
-	}
-
-	// Step 1: retrieve the desired metadata
-	files, problems, err := force.Metadata.Retrieve(query)
-	if err != nil {
-		ErrorAndExit(err.Error())
-	}
-	for _, problem := range problems {
-		fmt.Fprintln(os.Stderr, problem)
-	}
-
-	// Step 2: go through the metadata and construct a list of Profile (profiles) and a CustomObject (theObject)
-	var profiles list.List
-	var theObject CustomObject
-
-	for name, data := range files {
-		if strings.HasPrefix(name, "profiles/") {
-			profileName := strings.TrimSuffix(strings.TrimPrefix(name, "profiles/"), ".profile")
-			profiles.PushBack(parseProfileXML(profileName, string(data)))
-		} else if strings.HasPrefix(name, "objects/") {
-			objectName := strings.TrimSuffix(strings.TrimPrefix(name, "objects/"), ".object")
-			if strings.ToLower(objectName) == strings.ToLower(sobject) {
-				theObject = parseCustomObjectXML(objectName, string(data))
+func displaySecurityHTML(grants []SecurityGrant, sobject string, fields []string) {
+	groups := groupSecurityGrants(grants, sobject, fields)
+
+	var b strings.Builder
+	b.WriteString(`<html><body><table border="1" style="border-collapse:collapse;"><tr><td></td>`)
+	for _, group := range groups {
+		var names []string
+		for _, g := range group {
+			name := html.EscapeString(g.Name)
+			if g.Type != SecurityGrantProfile {
+				name += " <i>(" + g.Type + ")</i>"
 			}
+			names = append(names, strings.Replace(name, " ", "&nbsp;", -1))
 		}
+		b.WriteString("<td>" + strings.Join(names, "<br/>") + "</td>")
 	}
+	b.WriteString("</tr>")
 
-	// Step 3: group the profiles that have the exact same OLS and FLS
-	// for the desired object together
-	allProfiles := map[string]list.List{}
-	var p Profile
-	var profileKeys list.List
-
-	for e := profiles.Front(); e != nil; e = e.Next() {
-		p = e.Value.(Profile)
-		key := theObject.getProfileFootprint(p)
-		tmpList, OK := allProfiles[key]
-		if !OK {
-			var tmpList2 list.List
-			tmpList2.PushBack(p)
-			allProfiles[key] = tmpList2
-			profileKeys.PushBack(key)
-		} else {
-			tmpList.PushBack(p)
+	for _, p := range ObjectPermissionNames() {
+		b.WriteString("<tr><td>[Object] " + p + "</td>")
+		for _, group := range groups {
+			b.WriteString("<td>" + strconv.FormatBool(group[0].Objects[sobject].Permission(p)) + "</td>")
 		}
+		b.WriteString("</tr>")
 	}
 
-	// Step 4: generate an HTML file that shows the various groups of profiles
-	// as well as their OLS and FLS
-	HTMLoutput := "<html><body>" +
-		"<table border=\"1\" style=\"border-collapse:collapse;\">" +
-		"<tr><td></td>"
-
-	for key := profileKeys.Front(); key != nil; key = key.Next() {
-		val := allProfiles[key.Value.(string)]
-		profileNames := ""
-		for v := val.Front(); v != nil; v = v.Next() {
-			if v.Value == nil {
-				continue
+	for _, f := range fields {
+		b.WriteString("<tr><td>" + html.EscapeString(f) + "</td>")
+		for _, group := range groups {
+			switch access := group[0].Fields[sobject+"."+f]; {
+			case access.Edit:
+				b.WriteString("<td>Yes</td>")
+			case access.Read:
+				b.WriteString("<td>Readonly</td>")
+			default:
+				b.WriteString("<td>-</td>")
 			}
-			theProfile := v.Value.(Profile)
-			profileNames += theProfile.name + "<br/>"
 		}
-		HTMLoutput += "<td>" + strings.Replace(profileNames, " ", "&nbsp;", -1) + "</td>"
+		b.WriteString("</tr>")
 	}
-	HTMLoutput += "</tr>"
-
-	OLSproperties := []string{"Allow Create", "Allow Delete", "Allow Edit", "Allow Read", "Modify All Records", "View All Records"}
+	b.WriteString("</table></body></html>")
 
-	for _, OLSproperty := range OLSproperties {
-		HTMLoutput += "<tr><td>[Object] " + OLSproperty + "</td>"
-
-		for key := profileKeys.Front(); key != nil; key = key.Next() {
-			val := allProfiles[key.Value.(string)]
-			theProfile := val.Front().Value.(Profile)
-			theOLS := theProfile.objectPermissions[theObject.objectName]
-			HTMLoutput += "<td>" + theOLS.getProperty(OLSproperty) + "</td>"
-		}
-		HTMLoutput += "</tr>"
+	wd, _ := os.Getwd()
+	file := filepath.Join(wd, "security.html")
+	if err := ioutil.WriteFile(file, []byte(b.String()), 0644); err != nil {
+		ErrorAndExit(err.Error())
 	}
+	desktop.Open(file)
+}
 
-	for idx := 0; idx < theObject.nbFields; idx++ {
-		fieldName := theObject.fieldNames[idx]
-		HTMLoutput += "<tr><td>" + fieldName + "</td>"
-		for key := profileKeys.Front(); key != nil; key = key.Next() {
-			val := allProfiles[key.Value.(string)]
-			theProfile := val.Front().Value.(Profile)
-			if theProfile.fieldPermissions[theObject.objectName+"."+fieldName].editable == "true" {
-				HTMLoutput += "<td>Yes</td>"
-			} else if theProfile.fieldPermissions[theObject.objectName+"."+fieldName].readable == "true" {
-				HTMLoutput += "<td>Readonly</td>"
-			} else {
-				HTMLoutput += "<td>-</td>"
-			}
-		}
-		HTMLoutput += "</tr>"
+func runSecurityCompare(a string, b string, objects []string, format string) {
+	if format == "" {
+		format = "table"
 	}
-
-	HTMLoutput += "</table></body></html>"
-
-	// Last step: write the file on disk and display it inside a Web browser
-	if err := ioutil.WriteFile(filepath.Join(root, "security.html"), []byte(HTMLoutput), 0644); err != nil {
+	if format != "table" && format != "csv" && format != "json" {
+		ErrorAndExit("Invalid format: %s", format)
+	}
+	grantA, err := force.GetSecurityGrant(a, objects)
+	if err != nil {
 		ErrorAndExit(err.Error())
 	}
+	grantB, err := force.GetSecurityGrant(b, objects)
+	if err != nil {
+		ErrorAndExit(err.Error())
+	}
+	differences := CompareSecurityGrants(grantA, grantB)
 
-	desktop.Open(filepath.Join(root, "security.html"))
+	switch format {
+	case "json":
+		out, err := json.MarshalIndent(differences, "", "  ")
+		if err != nil {
+			ErrorAndExit(err.Error())
+		}
+		fmt.Println(string(out))
+		return
+	case "csv":
+		w := csv.NewWriter(os.Stdout)
+		w.Write([]string{"Object", "Field", "Permission", grantA.Name, grantB.Name})
+		for _, d := range differences {
+			w.Write([]string{d.Object, d.Field, d.Permission, strconv.FormatBool(d.A), strconv.FormatBool(d.B)})
+		}
+		w.Flush()
+		return
+	}
+	if len(differences) == 0 {
+		fmt.Printf("%s and %s grant the same access\n", grantA.Name, grantB.Name)
+		return
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Object", "Field", "Permission", grantA.Name, grantB.Name})
+	table.SetAutoWrapText(false)
+	yesNo := map[bool]string{true: "Yes", false: "-"}
+	for _, d := range differences {
+		table.Append([]string{d.Object, d.Field, d.Permission, yesNo[d.A], yesNo[d.B]})
+	}
+	table.Render()
 }
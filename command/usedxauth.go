@@ -21,6 +21,10 @@ var useDXAuthCmd = &cobra.Command{
 	Short: "Authenticate with SFDX Scratch Org User",
 	Long: `
 Authenticate with SFDX Scratch Org User. If a user or alias is passed to the command then an attempt is made to find that user authentication info.  If no user or alias is passed an attempt is made to find the default user based on sfdx config.
+
+The auth info is read directly from the sf CLI's files in ~/.sfdx, decrypting
+the tokens with the key from ~/.sfdx/key.json or the OS keychain.  If the files
+can't be read, sfdx is run to get the auth info.
 `,
 	Example: `
   force usedxauth test-d1df0gyckgpr@dcarroll_company.net
@@ -38,6 +42,45 @@ Authenticate with SFDX Scratch Org User. If a user or alias is passed to the com
 }
 
 func runUseDXAuth(alias string) {
+	authData, err := readDXAuth(alias)
+	if err != nil {
+		Log.Info(fmt.Sprintf("Could not read SFDX auth files: %s", err.Error()))
+		authData = getDXAuthFromOrgList(alias)
+	}
+	UseSFDXSession(authData)
+	if len(authData.Alias) > 0 {
+		fmt.Printf("Now using DX credentials for %s (%s)\n", authData.Username, authData.Alias)
+	} else {
+		fmt.Printf("Now using DX credentials for %s\n", authData.Username)
+	}
+}
+
+// readDXAuth reads the auth info directly from the sf CLI's auth files.  If
+// no user or alias is passed, the default username from the sf CLI config is
+// used, preferring the default Dev Hub outside of a project directory.
+func readDXAuth(alias string) (authData SFDXAuth, err error) {
+	if len(alias) == 0 {
+		alias, err = SFDXDefaultUsername(!inProjectDir())
+		if err != nil {
+			alias, err = SFDXDefaultUsername(inProjectDir())
+		}
+		if err != nil {
+			return
+		}
+	}
+	authData, err = ReadSFDXAuth(alias)
+	if err != nil {
+		return
+	}
+	// The stored access token may have expired
+	if refreshed, err := RefreshSFDXAuth(alias); err == nil {
+		authData.AccessToken = refreshed.AccessToken
+		authData.InstanceUrl = refreshed.InstanceUrl
+	}
+	return
+}
+
+func getDXAuthFromOrgList(alias string) SFDXAuth {
 	var auth map[string]interface{}
 	var err error
 	if len(alias) == 0 {
@@ -57,25 +100,18 @@ func runUseDXAuth(alias string) {
 	// scratch org status
 	status := fmt.Sprintf("%s", auth["status"])
 	username := fmt.Sprintf("%s", auth["username"])
-	switch {
-	case connStatus == "Connected" || connStatus == "Unknown" || status == "Active":
-		authData, err := GetSFDXAuth(username)
-		if err != nil {
-			ErrorAndExit(err.Error())
-		}
-		if val, ok := auth["alias"]; ok {
-			authData.Alias = val.(string)
-		}
-		authData.Username = username
-		UseSFDXSession(authData)
-		if len(authData.Alias) > 0 {
-			fmt.Printf("Now using DX credentials for %s (%s)\n", username, authData.Alias)
-		} else {
-			fmt.Printf("Now using DX credentials for %s\n", username)
-		}
-	default:
+	if connStatus != "Connected" && connStatus != "Unknown" && status != "Active" {
 		ErrorAndExit("Could not determine connection status for %s", username)
 	}
+	authData, err := GetSFDXAuth(username)
+	if err != nil {
+		ErrorAndExit(err.Error())
+	}
+	if val, ok := auth["alias"]; ok {
+		authData.Alias = val.(string)
+	}
+	authData.Username = username
+	return authData
 }
 
 func inProjectDir() bool {
@@ -0,0 +1,270 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	. "github.com/ForceCLI/force/error"
+	. "github.com/ForceCLI/force/lib"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	for _, cmd := range []*cobra.Command{userCreateCmd, userCloneCmd} {
+		cmd.Flags().StringP("email", "e", "", "email address")
+		cmd.Flags().String("first-name", "", "first name")
+		cmd.Flags().String("last-name", "", "last name")
+		cmd.Flags().String("username", "", "username.  generated from the email address if not set")
+		cmd.Flags().String("alias", "", "alias.  generated from the name if not set")
+		cmd.Flags().StringP("profile", "p", "", "profile name")
+		cmd.Flags().StringP("role", "r", "", "role name")
+		cmd.Flags().StringSliceP("permission-set", "s", []string{}, "permission set or permission set group to assign.  may be repeated")
+		cmd.Flags().String("timezone", "", "time zone, e.g. America/New_York")
+		cmd.Flags().String("locale", "", "locale, e.g. en_US")
+		cmd.Flags().String("language", "", "language, e.g. en_US")
+	}
+	userCreateCmd.Flags().StringP("template", "t", "", "username of an existing user whose profile, role, permission sets, and locale settings are used as defaults")
+	userCreateCmd.Flags().String("from-csv", "", "CSV file of users to create")
+
+	for _, cmd := range []*cobra.Command{userFreezeCmd, userUnfreezeCmd, userDeactivateCmd} {
+		cmd.Flags().StringP("query", "q", "", "SOQL condition selecting users, e.g. \"LastLoginDate < LAST_N_DAYS:90\"")
+	}
+
+	userListCmd.Flags().Bool("all", false, "include inactive users")
+	userListCmd.Flags().StringP("query", "q", "", "SOQL condition selecting users")
+
+	userCmd.AddCommand(userCreateCmd)
+	userCmd.AddCommand(userCloneCmd)
+	userCmd.AddCommand(userFreezeCmd)
+	userCmd.AddCommand(userUnfreezeCmd)
+	userCmd.AddCommand(userDeactivateCmd)
+	userCmd.AddCommand(userListCmd)
+	RootCmd.AddCommand(userCmd)
+}
+
+var userCmd = &cobra.Command{
+	Use:   "user",
+	Short: "Create, freeze, and deactivate users",
+}
+
+var userCreateCmd = &cobra.Command{
+	Use:   "create [flags]",
+	Short: "Create users",
+	Long: `
+Create users from flags or from a CSV file.  LastName, Email, and Profile are
+required, either directly or from a template user.  Usernames are generated
+from the email address and the domain of the active user's username if not
+set, and aliases are generated from the name.
+
+CSV columns are FirstName, LastName, Email, Username, Alias, Profile, Role,
+PermissionSets, TimeZoneSidKey, LocaleSidKey, LanguageLocaleKey, and
+EmailEncodingKey.  Multiple PermissionSets are separated by semicolons.
+
+Locale settings not set explicitly or by a template are copied from the active
+user.  Use "force password reset" to set a password for a new user.
+`,
+	Example: `
+  force user create -e jane@example.com --first-name Jane --last-name Doe -p "Standard User" -s Sales_Ops
+  force user create --template template.user@example.com --from-csv new-hires.csv
+`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		template, _ := cmd.Flags().GetString("template")
+		csvFile, _ := cmd.Flags().GetString("from-csv")
+		var specs []UserSpec
+		if csvFile != "" {
+			f, err := os.Open(csvFile)
+			if err != nil {
+				ErrorAndExit(err.Error())
+			}
+			defer f.Close()
+			specs, err = ReadUserSpecs(f)
+			if err != nil {
+				ErrorAndExit(err.Error())
+			}
+		} else {
+			specs = []UserSpec{userSpecFromFlags(cmd)}
+		}
+		runUserCreate(specs, template)
+	},
+}
+
+var userCloneCmd = &cobra.Command{
+	Use:   "clone [flags] <username>",
+	Short: "Create a user with the profile, role, permission sets, and locale settings of an existing user",
+	Example: `
+  force user clone jane@example.com -e john@example.com --first-name John --last-name Smith
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runUserCreate([]UserSpec{userSpecFromFlags(cmd)}, args[0])
+	},
+}
+
+var userFreezeCmd = &cobra.Command{
+	Use:   "freeze [flags] [username]...",
+	Short: "Freeze users so they can't log in",
+	Example: `
+  force user freeze jane@example.com
+  force user freeze --query "Profile.Name = 'Contractor'"
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runUserUpdate(cmd, args, func(userIds map[string]string) ([]UserUpdateResult, error) {
+			return force.FreezeUsers(userIds, true)
+		})
+	},
+}
+
+var userUnfreezeCmd = &cobra.Command{
+	Use:   "unfreeze [flags] [username]...",
+	Short: "Unfreeze users",
+	Example: `
+  force user unfreeze jane@example.com
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runUserUpdate(cmd, args, func(userIds map[string]string) ([]UserUpdateResult, error) {
+			return force.FreezeUsers(userIds, false)
+		})
+	},
+}
+
+var userDeactivateCmd = &cobra.Command{
+	Use:   "deactivate [flags] [username]...",
+	Short: "Deactivate users",
+	Example: `
+  force user deactivate jane@example.com john@example.com
+  force user deactivate --query "IsActive = true AND LastLoginDate < LAST_N_DAYS:180"
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runUserUpdate(cmd, args, force.DeactivateUsers)
+	},
+}
+
+var userListCmd = &cobra.Command{
+	Use:   "list [flags]",
+	Short: "List users",
+	Example: `
+  force user list
+  force user list --all --query "Profile.Name = 'Standard User'"
+`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		all, _ := cmd.Flags().GetBool("all")
+		query, _ := cmd.Flags().GetString("query")
+		condition := query
+		if !all {
+			if condition != "" {
+				condition = "IsActive = true AND (" + condition + ")"
+			} else {
+				condition = "IsActive = true"
+			}
+		} else if condition == "" {
+			condition = "Id != null"
+		}
+		users, err := force.QueryUsers(condition)
+		if err != nil {
+			ErrorAndExit(err.Error())
+		}
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Username", "Name", "Profile", "Role", "Active", "Frozen", "Last Login"})
+		table.SetAutoWrapText(false)
+		for _, u := range users {
+			table.Append([]string{u.Username, u.Name, u.Profile, u.Role, strconv.FormatBool(u.IsActive), strconv.FormatBool(u.IsFrozen), u.LastLoginDate})
+		}
+		table.Render()
+	},
+}
+
+func userSpecFromFlags(cmd *cobra.Command) UserSpec {
+	var spec UserSpec
+	spec.Email, _ = cmd.Flags().GetString("email")
+	spec.FirstName, _ = cmd.Flags().GetString("first-name")
+	spec.LastName, _ = cmd.Flags().GetString("last-name")
+	spec.Username, _ = cmd.Flags().GetString("username")
+	spec.Alias, _ = cmd.Flags().GetString("alias")
+	spec.Profile, _ = cmd.Flags().GetString("profile")
+	spec.Role, _ = cmd.Flags().GetString("role")
+	spec.PermissionSets, _ = cmd.Flags().GetStringSlice("permission-set")
+	spec.TimeZoneSidKey, _ = cmd.Flags().GetString("timezone")
+	spec.LocaleSidKey, _ = cmd.Flags().GetString("locale")
+	spec.LanguageLocaleKey, _ = cmd.Flags().GetString("language")
+	return spec
+}
+
+func runUserCreate(specs []UserSpec, templateUsername string) {
+	var template UserSpec
+	var err error
+	switch {
+	case templateUsername != "":
+		template, err = force.GetUserTemplate(templateUsername)
+	case force.Credentials.UserInfo != nil:
+		// Only the locale settings of the active user are used as defaults
+		var me UserSpec
+		me, err = force.GetUserTemplate(force.Credentials.UserInfo.UserName)
+		template = UserSpec{
+			TimeZoneSidKey:    me.TimeZoneSidKey,
+			LocaleSidKey:      me.LocaleSidKey,
+			LanguageLocaleKey: me.LanguageLocaleKey,
+			EmailEncodingKey:  me.EmailEncodingKey,
+		}
+	}
+	if err != nil {
+		ErrorAndExit(err.Error())
+	}
+	for i := range specs {
+		specs[i] = specs[i].WithDefaults(template)
+	}
+	results, err := force.CreateUsers(specs)
+	if err != nil {
+		ErrorAndExit(err.Error())
+	}
+	failed := false
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Username", "Id", "Status"})
+	table.SetAutoWrapText(false)
+	for _, r := range results {
+		status := "created"
+		if r.Error != nil {
+			failed = true
+			status = r.Error.Error()
+		}
+		table.Append([]string{r.Username, r.Id, status})
+	}
+	table.Render()
+	if failed {
+		ErrorAndExit("Some users could not be created")
+	}
+}
+
+func runUserUpdate(cmd *cobra.Command, usernames []string, update func(map[string]string) ([]UserUpdateResult, error)) {
+	query, _ := cmd.Flags().GetString("query")
+	if len(usernames) == 0 && query == "" {
+		ErrorAndExit("Specify usernames or --query to select users")
+	}
+	userIds, err := force.QueryUserIds(usernames, query)
+	if err != nil {
+		ErrorAndExit(err.Error())
+	}
+	if len(userIds) == 0 {
+		fmt.Println("No matching users")
+		return
+	}
+	results, err := update(userIds)
+	if err != nil {
+		ErrorAndExit(err.Error())
+	}
+	failed := false
+	for _, r := range results {
+		if r.Error != nil {
+			failed = true
+			fmt.Printf("%s: %s: %s\n", r.Username, r.Status, r.Error)
+			continue
+		}
+		fmt.Printf("%s: %s\n", r.Username, r.Status)
+	}
+	if failed {
+		ErrorAndExit("Some users could not be updated")
+	}
+}
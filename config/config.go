@@ -24,10 +24,18 @@ func IsSourceDir(dir string) bool {
 }
 
 // GetSourceDir returns a rooted path name of the Salesforce source directory,
-// relative to the current directory. GetSourceDir will look for a source
-// directory in the nearest subdirectory. If no such directory exists, it will
-// look at its parents, assuming that it is within a source directory already.
+// relative to the current directory. If the project config lists source
+// directories, the first that exists is used. Otherwise, GetSourceDir will
+// look for a source directory in the nearest subdirectory. If no such
+// directory exists, it will look at its parents, assuming that it is within a
+// source directory already.
 func GetSourceDir() (dir string, err error) {
+	if project, _ := Project(); project != nil {
+		if dir = project.SourceDir(); dir != "" {
+			return
+		}
+	}
+
 	base, err := os.Getwd()
 	if err != nil {
 		return
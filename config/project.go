@@ -0,0 +1,112 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectConfigFiles are the names of the per-project configuration files, in
+// order of precedence
+var ProjectConfigFiles = []string{
+	".force.yaml",
+	".force.yml",
+	".force.json",
+}
+
+// ProjectConfig holds per-project defaults, typically committed with the
+// project's source so the team shares the same conventions.  Command-line
+// flags take precedence.
+type ProjectConfig struct {
+	// Account is the default account username, alias, or group
+	Account    string `yaml:"account" json:"account"`
+	ApiVersion string `yaml:"apiVersion" json:"apiVersion"`
+	// SourceDirs are the candidate source directories, relative to the
+	// directory containing the config file.  The first that exists is used.
+	SourceDirs []string `yaml:"sourceDirs" json:"sourceDirs"`
+	TestLevel  string   `yaml:"testLevel" json:"testLevel"`
+	// OutputFormat is the default output format for commands that support it
+	OutputFormat string `yaml:"outputFormat" json:"outputFormat"`
+	// ExportExclude are metadata types excluded by export
+	ExportExclude []string `yaml:"exportExclude" json:"exportExclude"`
+
+	// Path is the config file the project config was loaded from
+	Path string `yaml:"-" json:"-"`
+}
+
+var (
+	projectConfigOnce sync.Once
+	projectConfig     *ProjectConfig
+	projectConfigErr  error
+)
+
+// Project returns the project config found by FindProjectConfig, loading it
+// the first time it's called.  It returns nil if there is no project config.
+func Project() (*ProjectConfig, error) {
+	projectConfigOnce.Do(func() {
+		projectConfig, projectConfigErr = FindProjectConfig()
+	})
+	return projectConfig, projectConfigErr
+}
+
+// FindProjectConfig looks for a project config file in the current directory
+// and then its parents, returning nil if none is found
+func FindProjectConfig() (*ProjectConfig, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		for _, name := range ProjectConfigFiles {
+			path := filepath.Join(dir, name)
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				return LoadProjectConfig(path)
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil
+		}
+		dir = parent
+	}
+}
+
+// LoadProjectConfig reads a project config file, parsing it as JSON if it has
+// a .json extension and as YAML otherwise
+func LoadProjectConfig(path string) (*ProjectConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var project ProjectConfig
+	if filepath.Ext(path) == ".json" {
+		err = json.Unmarshal(data, &project)
+	} else {
+		err = yaml.Unmarshal(data, &project)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Could not parse %s: %w", path, err)
+	}
+	project.Path = path
+	return &project, nil
+}
+
+// SourceDir returns the first of the project's source directories that
+// exists, or an empty string if none do
+func (p *ProjectConfig) SourceDir() string {
+	base := filepath.Dir(p.Path)
+	for _, src := range p.SourceDirs {
+		dir := src
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(base, src)
+		}
+		if IsSourceDir(dir) {
+			return dir
+		}
+	}
+	return ""
+}
@@ -0,0 +1,47 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindProjectConfig(t *testing.T) {
+	dir, err := os.MkdirTemp("", "project")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	sub := filepath.Join(dir, "src", "classes")
+	if err = os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	configFile := filepath.Join(dir, ".force.yaml")
+	os.WriteFile(configFile, []byte("account: prod\ntestLevel: RunLocalTests\nsourceDirs: [missing, src]\n"), 0644)
+
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	os.Chdir(sub)
+
+	project, err := FindProjectConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if project == nil {
+		t.Fatal("expected project config")
+	}
+	if project.Account != "prod" || project.TestLevel != "RunLocalTests" {
+		t.Errorf("unexpected project config: %+v", project)
+	}
+	if got, _ := filepath.EvalSymlinks(project.SourceDir()); got != mustEvalSymlinks(t, filepath.Join(dir, "src")) {
+		t.Errorf("expected source dir %s, got %s", filepath.Join(dir, "src"), got)
+	}
+}
+
+func mustEvalSymlinks(t *testing.T, path string) string {
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resolved
+}
@@ -0,0 +1,199 @@
+package lib
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	. "github.com/ForceCLI/force/config"
+)
+
+// Aliases and groups are stored in the config alongside the saved logins.
+// An alias names a single saved login; a group names a list of saved logins
+// or aliases that can be targeted by commands supporting multiple accounts.
+//
+// A login can also be saved under an alias instead of its username by
+// setting SessionOptions.Alias, as usedxauth does for SFDX aliases.  Saved
+// logins take precedence over aliases with the same name: SetAlias won't
+// shadow a saved login, and saving a login under an alias replaces any alias
+// with that name.
+
+func loginExists(account string) bool {
+	_, err := Config.Load("accounts", account)
+	return err == nil
+}
+
+// loginAliases returns the saved logins that are saved under an alias, mapped
+// to their usernames
+func loginAliases() map[string]string {
+	aliases := make(map[string]string)
+	for _, account := range SavedLogins() {
+		creds, err := LoadLogin(account)
+		if err != nil || creds.SessionOptions == nil || creds.UserInfo == nil {
+			continue
+		}
+		if alias := creds.SessionOptions.Alias; alias == account && alias != creds.UserInfo.UserName {
+			aliases[alias] = creds.UserInfo.UserName
+		}
+	}
+	return aliases
+}
+
+// Aliases returns the aliases, mapped to the accounts they refer to.  Logins
+// saved under an alias are included, mapped to their usernames.
+func Aliases() map[string]string {
+	aliases := loginAliases()
+	names, _ := Config.List("aliases")
+	for _, name := range names {
+		if loginExists(name) {
+			continue
+		}
+		account, err := Config.Load("aliases", name)
+		if err == nil {
+			aliases[name] = strings.TrimSpace(account)
+		}
+	}
+	return aliases
+}
+
+// SetAlias saves an alias for a saved login
+func SetAlias(alias string, account string) error {
+	if err := validateAccountName(alias); err != nil {
+		return err
+	}
+	if username, ok := loginAliases()[alias]; ok {
+		return fmt.Errorf("%s is already the alias of the saved login for %s", alias, username)
+	}
+	switch {
+	case loginExists(alias):
+		return fmt.Errorf("%s is already a saved login", alias)
+	case isGroup(alias):
+		return fmt.Errorf("%s is already a group", alias)
+	}
+	account = ResolveAccount(account)
+	if !loginExists(account) {
+		return fmt.Errorf("No saved login for %s", account)
+	}
+	return Config.Save("aliases", alias, account)
+}
+
+// RemoveAlias removes a saved alias
+func RemoveAlias(alias string) error {
+	if _, ok := loginAliases()[alias]; ok {
+		return fmt.Errorf("%s is a saved login.  Log out to remove it.", alias)
+	}
+	if _, err := Config.Load("aliases", alias); err != nil {
+		return fmt.Errorf("No such alias %s", alias)
+	}
+	return Config.Delete("aliases", alias)
+}
+
+// ResolveAccount returns the account for an alias.  Saved logins take
+// precedence, and names that aren't aliases are returned unchanged.
+func ResolveAccount(name string) string {
+	if loginExists(name) {
+		return name
+	}
+	if account, err := Config.Load("aliases", name); err == nil {
+		return strings.TrimSpace(account)
+	}
+	return name
+}
+
+func isGroup(name string) bool {
+	_, err := Config.Load("groups", name)
+	return err == nil
+}
+
+// Groups returns the saved groups, mapped to their members
+func Groups() map[string][]string {
+	groups := make(map[string][]string)
+	names, _ := Config.List("groups")
+	for _, name := range names {
+		if members, err := GroupMembers(name); err == nil {
+			groups[name] = members
+		}
+	}
+	return groups
+}
+
+// GroupMembers returns the saved logins or aliases in a group
+func GroupMembers(group string) (members []string, err error) {
+	data, err := Config.Load("groups", group)
+	if err != nil {
+		return nil, fmt.Errorf("No such group %s", group)
+	}
+	if err = json.Unmarshal([]byte(data), &members); err != nil {
+		return nil, fmt.Errorf("Could not parse group %s: %w", group, err)
+	}
+	return
+}
+
+// SetGroup saves a group of saved logins or aliases, replacing any existing
+// group with the same name
+func SetGroup(group string, members []string) error {
+	if err := validateAccountName(group); err != nil {
+		return err
+	}
+	switch {
+	case loginExists(group):
+		return fmt.Errorf("%s is already a saved login", group)
+	case ResolveAccount(group) != group:
+		return fmt.Errorf("%s is already an alias", group)
+	case len(members) == 0:
+		return fmt.Errorf("A group must have at least one member")
+	}
+	for _, member := range members {
+		if !loginExists(ResolveAccount(member)) {
+			return fmt.Errorf("No saved login for %s", member)
+		}
+	}
+	data, err := json.Marshal(members)
+	if err != nil {
+		return err
+	}
+	return Config.Save("groups", group, string(data))
+}
+
+// RemoveGroup removes a saved group
+func RemoveGroup(group string) error {
+	if !isGroup(group) {
+		return fmt.Errorf("No such group %s", group)
+	}
+	return Config.Delete("groups", group)
+}
+
+// ResolveAccounts expands groups and resolves aliases in a list of account
+// names, removing duplicates
+func ResolveAccounts(names []string) ([]string, error) {
+	var accounts []string
+	seen := make(map[string]bool)
+	add := func(account string) {
+		if !seen[account] {
+			seen[account] = true
+			accounts = append(accounts, account)
+		}
+	}
+	for _, name := range names {
+		account := ResolveAccount(name)
+		if account != name || loginExists(name) || !isGroup(name) {
+			add(account)
+			continue
+		}
+		members, err := GroupMembers(name)
+		if err != nil {
+			return nil, err
+		}
+		for _, member := range members {
+			add(ResolveAccount(member))
+		}
+	}
+	return accounts, nil
+}
+
+func validateAccountName(name string) error {
+	if name == "" || strings.HasPrefix(name, ".") || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("Invalid name: %s", name)
+	}
+	return nil
+}
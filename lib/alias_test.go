@@ -0,0 +1,78 @@
+package lib_test
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/ForceCLI/config"
+	forceConfig "github.com/ForceCLI/force/config"
+	. "github.com/ForceCLI/force/lib"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Alias", func() {
+	var originalConfig *config.Config
+
+	BeforeEach(func() {
+		originalConfig = forceConfig.Config
+		forceConfig.Config = config.NewConfig("force-alias-test")
+		forceConfig.Config.Save("accounts", "admin@example.com", "{}")
+		forceConfig.Config.Save("accounts", "qa1@example.com", "{}")
+		forceConfig.Config.Save("accounts", "qa2@example.com", "{}")
+	})
+
+	AfterEach(func() {
+		forceConfig.Config = originalConfig
+		home, _ := os.UserHomeDir()
+		os.RemoveAll(filepath.Join(home, ".force-alias-test"))
+	})
+
+	It("should resolve aliases", func() {
+		Expect(SetAlias("prod", "admin@example.com")).To(Succeed())
+		Expect(ResolveAccount("prod")).To(Equal("admin@example.com"))
+		Expect(ResolveAccount("admin@example.com")).To(Equal("admin@example.com"))
+		Expect(ResolveAccount("other")).To(Equal("other"))
+		Expect(Aliases()).To(Equal(map[string]string{"prod": "admin@example.com"}))
+
+		Expect(RemoveAlias("prod")).To(Succeed())
+		Expect(ResolveAccount("prod")).To(Equal("prod"))
+	})
+
+	It("should not allow aliases for unknown logins or that shadow logins", func() {
+		Expect(SetAlias("prod", "unknown@example.com")).ToNot(Succeed())
+		Expect(SetAlias("qa1@example.com", "admin@example.com")).ToNot(Succeed())
+	})
+
+	It("should include logins saved under an alias", func() {
+		Expect(SetAlias("dev", "admin@example.com")).To(Succeed())
+		Expect(SaveLogin(ForceSession{
+			UserInfo:       &UserInfo{UserName: "dev@example.com"},
+			SessionOptions: &SessionOptions{Alias: "dev"},
+		})).To(Succeed())
+		Expect(ResolveAccount("dev")).To(Equal("dev"))
+		Expect(Aliases()).To(Equal(map[string]string{"dev": "dev@example.com"}))
+
+		Expect(SetAlias("dev", "qa1@example.com")).To(MatchError("dev is already the alias of the saved login for dev@example.com"))
+		Expect(RemoveAlias("dev")).ToNot(Succeed())
+	})
+
+	It("should expand groups", func() {
+		Expect(SetAlias("qa2", "qa2@example.com")).To(Succeed())
+		Expect(SetGroup("qa", []string{"qa1@example.com", "qa2"})).To(Succeed())
+		Expect(Groups()).To(Equal(map[string][]string{"qa": {"qa1@example.com", "qa2"}}))
+
+		accounts, err := ResolveAccounts([]string{"admin@example.com", "qa", "qa2"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(accounts).To(Equal([]string{"admin@example.com", "qa1@example.com", "qa2@example.com"}))
+
+		Expect(RemoveGroup("qa")).To(Succeed())
+		Expect(Groups()).To(BeEmpty())
+	})
+
+	It("should not allow groups with unknown members", func() {
+		Expect(SetGroup("qa", []string{"qa1@example.com", "unknown"})).ToNot(Succeed())
+		Expect(SetGroup("qa", nil)).ToNot(Succeed())
+	})
+})
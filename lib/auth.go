@@ -81,13 +81,21 @@ func (creds *ForceSession) SessionName() string {
 	return sessionName
 }
 
+// SaveLogin saves the session, encrypting it if a credentials passphrase is
+// configured
 func SaveLogin(creds ForceSession) (err error) {
-	body, err := json.Marshal(creds)
+	body, err := encodeCredentials(creds)
 	if err != nil {
 		return
 	}
 	sessionName := creds.SessionName()
-	err = Config.Save("accounts", sessionName, string(body))
+	if err = Config.Save("accounts", sessionName, body); err != nil {
+		return
+	}
+	if creds.SessionOptions.Alias != "" {
+		// The login replaces any alias with the same name
+		Config.Delete("aliases", sessionName)
+	}
 	return
 }
 
@@ -113,21 +121,41 @@ func ForceScratchLoginAndSave(output *os.File) (username string, err error) {
 }
 
 func ForceScratchCreateLoginAndSave(scratchUser string, output *os.File) (username string, err error) {
+	username, _, err = ForceScratchCreateFromDefinitionLoginAndSave(ScratchOrgDefinition{Username: scratchUser}, output)
+	return
+}
+
+// Create a new scratch org from an sfdx-style definition, login, deploy the
+// definition's settings, and make it active.  Returns the username and the
+// scratch org's expiration date.
+func ForceScratchCreateFromDefinitionLoginAndSave(definition ScratchOrgDefinition, output *os.File) (username string, expirationDate string, err error) {
 	force, err := ActiveForce()
 	if err != nil {
 		err = errors.New("You must be logged into a Dev Hub org to authenticate as a scratch org user.")
 		return
 	}
 	fmt.Fprintln(os.Stderr, "Creating new Scratch Org...")
-	scratchOrgId, err := force.CreateScratchOrgWithUser(scratchUser)
+	scratchOrgId, err := force.CreateScratchOrgWithDefinition(definition)
 	if err != nil {
 		return
 	}
-	session, err := force.ForceLoginNewScratch(scratchOrgId)
+	scratchOrg, err := force.getScratchOrg(scratchOrgId)
+	if err != nil {
+		return
+	}
+	expirationDate = scratchOrg.ExpirationDate
+	session, err := scratchOrg.login()
 	if err != nil {
 		return
 	}
 	username, err = ForceSaveLogin(session, output)
+	if err != nil {
+		return
+	}
+	if len(definition.Settings) > 0 {
+		fmt.Fprintln(os.Stderr, "Deploying scratch org settings...")
+		err = NewForce(&session).deployScratchOrgSettings(definition.Settings)
+	}
 	return
 }
 
@@ -162,6 +190,9 @@ func (f *Force) CopyCredentialAuthFields(creds *ForceSession) {
 	f.Credentials.IssuedAt = creds.IssuedAt
 	f.Credentials.InstanceUrl = creds.InstanceUrl
 	f.Credentials.Scope = creds.Scope
+	if creds.RefreshToken != "" {
+		f.Credentials.RefreshToken = creds.RefreshToken
+	}
 }
 
 func ActiveForce() (force *Force, err error) {
@@ -226,12 +257,13 @@ func upgradeCredentials(creds *ForceSession) (err error) {
 }
 
 func GetAccountCredentials(accountName string) (creds ForceSession, err error) {
+	accountName = ResolveAccount(accountName)
 	data, err := Config.Load("accounts", accountName)
 	if err != nil {
 		err = fmt.Errorf("Could not find account, %s.  Please log in first.", accountName)
 		return
 	}
-	err = json.Unmarshal([]byte(data), &creds)
+	creds, err = decodeCredentials(data)
 	if err != nil {
 		ErrorAndExit(err.Error())
 	}
@@ -274,6 +306,21 @@ func ActiveLogin() (account string, err error) {
 	return
 }
 
+// DeleteLogin removes a saved login.  If it was the active login, another
+// login is made active.
+func DeleteLogin(account string) (err error) {
+	err = Config.Delete("accounts", account)
+	// Even if the delete failed, don't leave the active login pointing at a
+	// login that doesn't exist
+	if active, _ := Config.Load("current", "account"); active == account {
+		if _, loadErr := Config.Load("accounts", account); loadErr != nil {
+			Config.Delete("current", "account")
+			SetActiveLoginDefault()
+		}
+	}
+	return
+}
+
 func SetActiveLoginDefault() (account string) {
 	accounts, _ := Config.List("accounts")
 	if len(accounts) > 0 {
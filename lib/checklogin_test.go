@@ -0,0 +1,79 @@
+package lib
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("checkSession", func() {
+	var server *httptest.Server
+	var tokenStatus int
+	var tokenResponse string
+	var creds ForceSession
+
+	BeforeEach(func() {
+		tokenStatus = 400
+		tokenResponse = `{"error":"invalid_grant","error_description":"expired access/refresh token"}`
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			switch r.URL.Path {
+			case "/services/oauth2/userinfo":
+				if r.Header.Get("Authorization") == "Bearer valid" {
+					w.Write([]byte(`{"preferred_username":"user@example.com"}`))
+					return
+				}
+				w.WriteHeader(401)
+				w.Write([]byte(`[{"errorCode":"INVALID_SESSION_ID","message":"Session expired or invalid"}]`))
+			case "/services/oauth2/token":
+				w.WriteHeader(tokenStatus)
+				w.Write([]byte(tokenResponse))
+			default:
+				w.WriteHeader(404)
+			}
+		}))
+		creds = ForceSession{
+			AccessToken:    "expired",
+			RefreshToken:   "refresh",
+			InstanceUrl:    server.URL,
+			EndpointUrl:    server.URL,
+			SessionOptions: &SessionOptions{RefreshMethod: RefreshOauth},
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should report a valid session", func() {
+		creds.AccessToken = "valid"
+		check := checkSession(LoginCheck{Account: "user@example.com"}, creds)
+		Expect(check.Status).To(Equal(LoginValid))
+	})
+
+	It("should report the login as expired if the refresh token is rejected", func() {
+		check := checkSession(LoginCheck{Account: "user@example.com"}, creds)
+		Expect(check.Status).To(Equal(LoginExpired))
+		Expect(check.Error).To(Equal(SessionRefreshError))
+		Expect(check.Prunable()).To(BeTrue())
+	})
+
+	It("should report an error if the token endpoint fails", func() {
+		tokenStatus = 503
+		tokenResponse = `{"error":"unavailable"}`
+		check := checkSession(LoginCheck{Account: "user@example.com"}, creds)
+		Expect(check.Status).To(Equal(LoginError))
+		Expect(check.Prunable()).To(BeFalse())
+	})
+
+	It("should report an error if the token endpoint can't be reached", func() {
+		unreachable := httptest.NewServer(http.NotFoundHandler())
+		unreachable.Close()
+		creds.EndpointUrl = unreachable.URL
+		check := checkSession(LoginCheck{Account: "user@example.com"}, creds)
+		Expect(check.Status).To(Equal(LoginError))
+		Expect(check.Prunable()).To(BeFalse())
+	})
+})
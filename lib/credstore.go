@@ -0,0 +1,215 @@
+package lib
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	. "github.com/ForceCLI/force/config"
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// CredentialsPassphraseEnv names the environment variable holding the
+	// passphrase used to encrypt saved logins
+	CredentialsPassphraseEnv = "FORCE_CREDENTIALS_PASSPHRASE"
+	// CredentialsKeyFileEnv names the environment variable holding the path
+	// to a file whose contents are used as the passphrase
+	CredentialsKeyFileEnv = "FORCE_CREDENTIALS_KEY_FILE"
+
+	encryptedCredentialsPrefix = "force-encrypted:v1:"
+	credentialsSaltLength      = 16
+	credentialsNonceLength     = 24
+)
+
+var CredentialsPassphraseRequiredError = fmt.Errorf("Saved login is encrypted.  Set %s or %s to decrypt it.", CredentialsPassphraseEnv, CredentialsKeyFileEnv)
+var CredentialsDecryptionError = errors.New("Could not decrypt saved login.  Check the passphrase.")
+
+// CredentialsPassphrase returns the passphrase used to encrypt saved logins
+// from the environment, or an empty string if encryption isn't configured
+func CredentialsPassphrase() (string, error) {
+	if passphrase := os.Getenv(CredentialsPassphraseEnv); passphrase != "" {
+		return passphrase, nil
+	}
+	if keyFile := os.Getenv(CredentialsKeyFileEnv); keyFile != "" {
+		data, err := os.ReadFile(keyFile)
+		if err != nil {
+			return "", fmt.Errorf("Could not read credentials key file: %w", err)
+		}
+		passphrase := strings.TrimSpace(string(data))
+		if passphrase == "" {
+			return "", fmt.Errorf("Credentials key file %s is empty", keyFile)
+		}
+		return passphrase, nil
+	}
+	return "", nil
+}
+
+func credentialsKey(passphrase string, salt []byte) (*[32]byte, error) {
+	derived, err := scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, err
+	}
+	var key [32]byte
+	copy(key[:], derived)
+	return &key, nil
+}
+
+// IsEncryptedCredentials returns true if the saved login data is encrypted
+func IsEncryptedCredentials(data string) bool {
+	return strings.HasPrefix(data, encryptedCredentialsPrefix)
+}
+
+// EncryptCredentials encrypts saved login data with a key derived from the
+// passphrase using scrypt, sealed with NaCl secretbox
+func EncryptCredentials(plaintext string, passphrase string) (string, error) {
+	salt := make([]byte, credentialsSaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	var nonce [credentialsNonceLength]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return "", err
+	}
+	key, err := credentialsKey(passphrase, salt)
+	if err != nil {
+		return "", err
+	}
+	sealed := append(salt, nonce[:]...)
+	sealed = secretbox.Seal(sealed, []byte(plaintext), &nonce, key)
+	return encryptedCredentialsPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// DecryptCredentials decrypts saved login data encrypted by
+// EncryptCredentials
+func DecryptCredentials(data string, passphrase string) (string, error) {
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(data, encryptedCredentialsPrefix))
+	if err != nil || len(sealed) < credentialsSaltLength+credentialsNonceLength+secretbox.Overhead {
+		return "", CredentialsDecryptionError
+	}
+	salt := sealed[:credentialsSaltLength]
+	var nonce [credentialsNonceLength]byte
+	copy(nonce[:], sealed[credentialsSaltLength:credentialsSaltLength+credentialsNonceLength])
+	key, err := credentialsKey(passphrase, salt)
+	if err != nil {
+		return "", err
+	}
+	plaintext, ok := secretbox.Open(nil, sealed[credentialsSaltLength+credentialsNonceLength:], &nonce, key)
+	if !ok {
+		return "", CredentialsDecryptionError
+	}
+	return string(plaintext), nil
+}
+
+// encodeCredentials returns the saved login data for a session, encrypted if
+// a passphrase is configured
+func encodeCredentials(creds ForceSession) (string, error) {
+	body, err := json.Marshal(creds)
+	if err != nil {
+		return "", err
+	}
+	passphrase, err := CredentialsPassphrase()
+	if err != nil || passphrase == "" {
+		return string(body), err
+	}
+	return EncryptCredentials(string(body), passphrase)
+}
+
+// decodeCredentials parses saved login data, decrypting it if needed
+func decodeCredentials(data string) (creds ForceSession, err error) {
+	if IsEncryptedCredentials(data) {
+		var passphrase string
+		passphrase, err = CredentialsPassphrase()
+		if err != nil {
+			return
+		}
+		if passphrase == "" {
+			err = CredentialsPassphraseRequiredError
+			return
+		}
+		data, err = DecryptCredentials(data, passphrase)
+		if err != nil {
+			return
+		}
+	}
+	err = json.Unmarshal([]byte(data), &creds)
+	return
+}
+
+// LoadLogin returns a saved login without refreshing or upgrading it
+func LoadLogin(account string) (creds ForceSession, err error) {
+	data, err := Config.Load("accounts", account)
+	if err != nil {
+		return
+	}
+	return decodeCredentials(data)
+}
+
+// EncryptLogins encrypts all saved logins that aren't already encrypted
+// using the configured passphrase.  It returns the accounts encrypted.
+func EncryptLogins() ([]string, error) {
+	passphrase, err := CredentialsPassphrase()
+	if err != nil {
+		return nil, err
+	}
+	if passphrase == "" {
+		return nil, fmt.Errorf("Set %s or %s to encrypt saved logins", CredentialsPassphraseEnv, CredentialsKeyFileEnv)
+	}
+	return rewriteLogins(func(data string) (string, bool, error) {
+		if IsEncryptedCredentials(data) {
+			return data, false, nil
+		}
+		encrypted, err := EncryptCredentials(data, passphrase)
+		return encrypted, true, err
+	})
+}
+
+// DecryptLogins decrypts all encrypted saved logins using the configured
+// passphrase.  It returns the accounts decrypted.
+func DecryptLogins() ([]string, error) {
+	passphrase, err := CredentialsPassphrase()
+	if err != nil {
+		return nil, err
+	}
+	if passphrase == "" {
+		return nil, CredentialsPassphraseRequiredError
+	}
+	return rewriteLogins(func(data string) (string, bool, error) {
+		if !IsEncryptedCredentials(data) {
+			return data, false, nil
+		}
+		decrypted, err := DecryptCredentials(data, passphrase)
+		return decrypted, true, err
+	})
+}
+
+func rewriteLogins(rewrite func(data string) (string, bool, error)) ([]string, error) {
+	accounts, _ := Config.List("accounts")
+	var changed []string
+	for _, account := range accounts {
+		if strings.HasPrefix(account, ".") {
+			continue
+		}
+		data, err := Config.Load("accounts", account)
+		if err != nil {
+			return changed, fmt.Errorf("Could not load %s: %w", account, err)
+		}
+		updated, ok, err := rewrite(data)
+		if err != nil {
+			return changed, fmt.Errorf("Could not update %s: %w", account, err)
+		}
+		if !ok {
+			continue
+		}
+		if err = Config.Save("accounts", account, updated); err != nil {
+			return changed, fmt.Errorf("Could not save %s: %w", account, err)
+		}
+		changed = append(changed, account)
+	}
+	return changed, nil
+}
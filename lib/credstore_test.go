@@ -0,0 +1,39 @@
+package lib_test
+
+import (
+	. "github.com/ForceCLI/force/lib"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Credential Store", func() {
+	plaintext := `{"access_token":"00D...","RefreshToken":"5Aep..."}`
+
+	It("should decrypt encrypted credentials", func() {
+		encrypted, err := EncryptCredentials(plaintext, "passphrase")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(IsEncryptedCredentials(encrypted)).To(BeTrue())
+		Expect(encrypted).ToNot(ContainSubstring("access_token"))
+
+		decrypted, err := DecryptCredentials(encrypted, "passphrase")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(decrypted).To(Equal(plaintext))
+	})
+
+	It("should use a new salt and nonce each time", func() {
+		a, _ := EncryptCredentials(plaintext, "passphrase")
+		b, _ := EncryptCredentials(plaintext, "passphrase")
+		Expect(a).ToNot(Equal(b))
+	})
+
+	It("should fail with the wrong passphrase", func() {
+		encrypted, _ := EncryptCredentials(plaintext, "passphrase")
+		_, err := DecryptCredentials(encrypted, "wrong")
+		Expect(err).To(Equal(CredentialsDecryptionError))
+	})
+
+	It("should not treat plain JSON as encrypted", func() {
+		Expect(IsEncryptedCredentials(plaintext)).To(BeFalse())
+	})
+})
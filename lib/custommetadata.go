@@ -0,0 +1,240 @@
+package lib
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CustomMetadataField describes a field of a custom metadata type
+type CustomMetadataField struct {
+	Name           string
+	Type           string
+	Length         int
+	PicklistValues []string
+}
+
+// CustomMetadataType describes the fields of a custom metadata type, e.g.
+// Setting__mdt
+type CustomMetadataType struct {
+	Name   string
+	Fields []CustomMetadataField
+}
+
+// Fields that identify a custom metadata record, rather than holding values
+var customMetadataRecordColumns = []string{"DeveloperName", "MasterLabel"}
+
+// Fields that are queryable, but not set when importing records
+var customMetadataIgnoredColumns = []string{"Id", "Label", "Language", "NamespacePrefix", "QualifiedApiName", "SystemModstamp"}
+
+// DescribeCustomMetadataType returns the custom fields of a custom metadata
+// type
+func (f *Force) DescribeCustomMetadataType(name string) (CustomMetadataType, error) {
+	if !strings.HasSuffix(strings.ToLower(name), "__mdt") {
+		return CustomMetadataType{}, fmt.Errorf("%s is not a custom metadata type", name)
+	}
+	sobject, err := f.GetSobject(name)
+	if err != nil {
+		return CustomMetadataType{}, fmt.Errorf("Could not describe %s: %w", name, err)
+	}
+	return NewCustomMetadataType(sobject)
+}
+
+// NewCustomMetadataType extracts the custom fields from the describe result
+// of a custom metadata type
+func NewCustomMetadataType(sobject ForceSobject) (CustomMetadataType, error) {
+	name, _ := sobject["name"].(string)
+	fields, ok := sobject["fields"].([]interface{})
+	if name == "" || !ok {
+		return CustomMetadataType{}, fmt.Errorf("Invalid describe result")
+	}
+	t := CustomMetadataType{Name: name}
+	for _, f := range fields {
+		field, ok := f.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if custom, _ := field["custom"].(bool); !custom {
+			continue
+		}
+		cf := CustomMetadataField{}
+		cf.Name, _ = field["name"].(string)
+		cf.Type, _ = field["type"].(string)
+		if length, ok := field["length"].(float64); ok {
+			cf.Length = int(length)
+		}
+		values, _ := field["picklistValues"].([]interface{})
+		for _, v := range values {
+			if value, ok := v.(map[string]interface{}); ok {
+				if s, ok := value["value"].(string); ok {
+					cf.PicklistValues = append(cf.PicklistValues, s)
+				}
+			}
+		}
+		t.Fields = append(t.Fields, cf)
+	}
+	return t, nil
+}
+
+// Columns returns the names of the columns used when exporting records
+func (t CustomMetadataType) Columns() []string {
+	columns := append([]string{}, customMetadataRecordColumns...)
+	for _, f := range t.Fields {
+		columns = append(columns, f.Name)
+	}
+	return columns
+}
+
+// Query returns the SOQL query used to export records
+func (t CustomMetadataType) Query() string {
+	return fmt.Sprintf("SELECT %s FROM %s ORDER BY DeveloperName", strings.Join(t.Columns(), ", "), t.Name)
+}
+
+// Field returns the field with the given name, ignoring case
+func (t CustomMetadataType) Field(name string) (CustomMetadataField, bool) {
+	for _, f := range t.Fields {
+		if strings.EqualFold(f.Name, name) {
+			return f, true
+		}
+	}
+	return CustomMetadataField{}, false
+}
+
+// RecordFiles generates a customMetadata/<Type>.<DeveloperName>.md file for
+// each row of values, along with a package.xml.  The header must include a
+// DeveloperName column; other columns must be custom fields of the type.
+// Values are validated against the field types.
+func (t CustomMetadataType) RecordFiles(header []string, rows [][]string) (ForceMetadataFiles, error) {
+	typeName := t.Name[:len(t.Name)-len("__mdt")]
+	developerNameColumn, labelColumn := -1, -1
+	fields := make(map[int]CustomMetadataField)
+	for i, column := range header {
+		column = strings.TrimSpace(column)
+		switch {
+		case strings.EqualFold(column, "DeveloperName"):
+			developerNameColumn = i
+		case strings.EqualFold(column, "MasterLabel"):
+			labelColumn = i
+		case containsFold(customMetadataIgnoredColumns, column):
+		default:
+			field, ok := t.Field(column)
+			if !ok {
+				return nil, fmt.Errorf("%s is not a field of %s", column, t.Name)
+			}
+			fields[i] = field
+		}
+	}
+	if developerNameColumn == -1 {
+		return nil, fmt.Errorf("Missing DeveloperName column")
+	}
+
+	files := make(ForceMetadataFiles)
+	pb := NewPushBuilder()
+	var problems []string
+	for r, row := range rows {
+		if len(row) != len(header) {
+			problems = append(problems, fmt.Sprintf("row %d: expected %d values, found %d", r+1, len(header), len(row)))
+			continue
+		}
+		developerName := strings.TrimSpace(row[developerNameColumn])
+		if developerName == "" {
+			problems = append(problems, fmt.Sprintf("row %d: missing DeveloperName", r+1))
+			continue
+		}
+		label := developerName
+		if labelColumn != -1 && row[labelColumn] != "" {
+			label = row[labelColumn]
+		}
+		var b strings.Builder
+		b.WriteString(xml.Header)
+		b.WriteString(`<CustomMetadata xmlns="http://soap.sforce.com/2006/04/metadata" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xmlns:xsd="http://www.w3.org/2001/XMLSchema">` + "\n")
+		fmt.Fprintf(&b, "    <label>%s</label>\n", xmlEscape(label))
+		b.WriteString("    <protected>false</protected>\n")
+		for i := range header {
+			field, ok := fields[i]
+			if !ok {
+				continue
+			}
+			value, err := customMetadataValueXml(field, row[i])
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("row %d (%s): %s", r+1, developerName, err.Error()))
+				continue
+			}
+			b.WriteString("    <values>\n")
+			fmt.Fprintf(&b, "        <field>%s</field>\n", field.Name)
+			fmt.Fprintf(&b, "        %s\n", value)
+			b.WriteString("    </values>\n")
+		}
+		b.WriteString("</CustomMetadata>\n")
+		fullName := typeName + "." + developerName
+		files["customMetadata/"+fullName+".md"] = []byte(b.String())
+		pb.AddMetaToPackage("CustomMetadata", fullName)
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("Invalid %s records:\n%s", t.Name, strings.Join(problems, "\n"))
+	}
+	files["package.xml"] = pb.PackageXml()
+	return files, nil
+}
+
+func customMetadataValueXml(field CustomMetadataField, value string) (string, error) {
+	if value == "" {
+		return `<value xsi:nil="true"/>`, nil
+	}
+	xsdType := "xsd:string"
+	switch strings.ToLower(field.Type) {
+	case "boolean":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", fmt.Errorf("%s must be true or false", field.Name)
+		}
+		xsdType, value = "xsd:boolean", strconv.FormatBool(b)
+	case "double", "int", "percent", "currency":
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return "", fmt.Errorf("%s must be a number", field.Name)
+		}
+		xsdType = "xsd:double"
+	case "date":
+		if _, err := time.Parse("2006-01-02", value); err != nil {
+			return "", fmt.Errorf("%s must be a date in YYYY-MM-DD format", field.Name)
+		}
+		xsdType = "xsd:date"
+	case "datetime":
+		if _, err := time.Parse(time.RFC3339, value); err != nil {
+			if _, err = time.Parse("2006-01-02T15:04:05.000-0700", value); err != nil {
+				return "", fmt.Errorf("%s must be a date and time in ISO 8601 format", field.Name)
+			}
+		}
+		xsdType = "xsd:dateTime"
+	case "email":
+		if _, err := mail.ParseAddress(value); err != nil {
+			return "", fmt.Errorf("%s must be an email address", field.Name)
+		}
+	case "picklist":
+		if len(field.PicklistValues) > 0 && !containsFold(field.PicklistValues, value) {
+			return "", fmt.Errorf("%s must be one of %s", field.Name, strings.Join(field.PicklistValues, ", "))
+		}
+	}
+	if field.Length > 0 && len([]rune(value)) > field.Length {
+		return "", fmt.Errorf("%s must be at most %d characters", field.Name, field.Length)
+	}
+	return fmt.Sprintf(`<value xsi:type="%s">%s</value>`, xsdType, xmlEscape(value)), nil
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+package lib_test
+
+import (
+	. "github.com/ForceCLI/force/lib"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CustomMetadataType", func() {
+	var settingType CustomMetadataType
+
+	BeforeEach(func() {
+		var err error
+		settingType, err = NewCustomMetadataType(ForceSobject{
+			"name": "Setting__mdt",
+			"fields": []interface{}{
+				map[string]interface{}{"name": "DeveloperName", "type": "string", "custom": false},
+				map[string]interface{}{"name": "Enabled__c", "type": "boolean", "custom": true},
+				map[string]interface{}{"name": "Limit__c", "type": "double", "custom": true},
+				map[string]interface{}{"name": "Value__c", "type": "string", "custom": true, "length": float64(10)},
+				map[string]interface{}{"name": "Mode__c", "type": "picklist", "custom": true, "picklistValues": []interface{}{
+					map[string]interface{}{"value": "Fast"},
+					map[string]interface{}{"value": "Slow"},
+				}},
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should only include custom fields", func() {
+		Expect(settingType.Columns()).To(Equal([]string{"DeveloperName", "MasterLabel", "Enabled__c", "Limit__c", "Value__c", "Mode__c"}))
+	})
+
+	It("should generate a file for each record", func() {
+		files, err := settingType.RecordFiles(
+			[]string{"DeveloperName", "MasterLabel", "Enabled__c", "Value__c"},
+			[][]string{{"Default", "Default & More", "TRUE", ""}},
+		)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(files).To(HaveKey("package.xml"))
+		Expect(string(files["package.xml"])).To(ContainSubstring("<members>Setting.Default</members>"))
+		md := string(files["customMetadata/Setting.Default.md"])
+		Expect(md).To(ContainSubstring("<label>Default &amp; More</label>"))
+		Expect(md).To(ContainSubstring(`<value xsi:type="xsd:boolean">true</value>`))
+		Expect(md).To(ContainSubstring(`<value xsi:nil="true"/>`))
+	})
+
+	It("should reject unknown columns", func() {
+		_, err := settingType.RecordFiles([]string{"DeveloperName", "Other__c"}, [][]string{{"Default", "x"}})
+		Expect(err).To(MatchError(ContainSubstring("Other__c is not a field")))
+	})
+
+	It("should validate values against field types", func() {
+		_, err := settingType.RecordFiles(
+			[]string{"DeveloperName", "Enabled__c", "Limit__c", "Value__c", "Mode__c"},
+			[][]string{{"Default", "maybe", "ten", "more than ten characters", "Medium"}},
+		)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Enabled__c must be true or false"))
+		Expect(err.Error()).To(ContainSubstring("Limit__c must be a number"))
+		Expect(err.Error()).To(ContainSubstring("Value__c must be at most 10 characters"))
+		Expect(err.Error()).To(ContainSubstring("Mode__c must be one of Fast, Slow"))
+	})
+})
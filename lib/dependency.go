@@ -0,0 +1,266 @@
+package lib
+
+import (
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MetadataComponent identifies a component as reported by the Tooling API's
+// MetadataComponentDependency object.
+type MetadataComponent struct {
+	Id        string `json:"id,omitempty"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	Type      string `json:"type"`
+}
+
+type MetadataComponentDependency struct {
+	Component    MetadataComponent
+	RefComponent MetadataComponent
+}
+
+type DependencyDirection int
+
+const (
+	// DependencyUses follows the components that a component references
+	DependencyUses DependencyDirection = iota
+	// DependencyUsedBy follows the components that reference a component
+	DependencyUsedBy
+)
+
+func (d DependencyDirection) String() string {
+	if d == DependencyUsedBy {
+		return "used by"
+	}
+	return "uses"
+}
+
+// DependencyNode is a node in a tree of metadata component dependencies.
+// Cycle is set when the component already appears among the node's
+// ancestors; its children are not expanded.
+type DependencyNode struct {
+	MetadataComponent
+	Children []*DependencyNode `json:"children,omitempty"`
+	Cycle    bool              `json:"cycle,omitempty"`
+}
+
+// DependencyLookup returns the components adjacent to a component in one
+// direction of the dependency graph.
+type DependencyLookup func(MetadataComponent) ([]MetadataComponent, error)
+
+func (c MetadataComponent) String() string {
+	name := c.Name
+	if c.Namespace != "" {
+		name = c.Namespace + "__" + name
+	}
+	return fmt.Sprintf("%s:%s", c.Type, name)
+}
+
+func (c MetadataComponent) key() string {
+	return strings.ToLower(c.String())
+}
+
+func dependencyFromRecord(record ForceRecord) MetadataComponentDependency {
+	str := func(field string) string {
+		s, _ := record[field].(string)
+		return s
+	}
+	return MetadataComponentDependency{
+		Component: MetadataComponent{
+			Id:        str("MetadataComponentId"),
+			Name:      str("MetadataComponentName"),
+			Namespace: str("MetadataComponentNamespace"),
+			Type:      str("MetadataComponentType"),
+		},
+		RefComponent: MetadataComponent{
+			Id:        str("RefMetadataComponentId"),
+			Name:      str("RefMetadataComponentName"),
+			Namespace: str("RefMetadataComponentNamespace"),
+			Type:      str("RefMetadataComponentType"),
+		},
+	}
+}
+
+// QueryMetadataComponentDependencies queries MetadataComponentDependency
+// records matching the given WHERE clause criteria.
+func (f *Force) QueryMetadataComponentDependencies(criteria string) (dependencies []MetadataComponentDependency, err error) {
+	soql := `SELECT MetadataComponentId, MetadataComponentName, MetadataComponentNamespace, MetadataComponentType,
+	RefMetadataComponentId, RefMetadataComponentName, RefMetadataComponentNamespace, RefMetadataComponentType
+	FROM MetadataComponentDependency`
+	if criteria != "" {
+		soql += " WHERE " + criteria
+	}
+	result, err := f.Query(soql, func(options *QueryOptions) {
+		options.IsTooling = true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Could not query dependencies: %w", err)
+	}
+	for _, record := range result.Records {
+		dependencies = append(dependencies, dependencyFromRecord(record))
+	}
+	return dependencies, nil
+}
+
+func dependencyCriteria(prefix string, c MetadataComponent) string {
+	if c.Id != "" {
+		return fmt.Sprintf("%sMetadataComponentId = %s", prefix, soqlQuote(c.Id))
+	}
+	criteria := fmt.Sprintf("%sMetadataComponentType = %s AND %sMetadataComponentName = %s", prefix, soqlQuote(c.Type), prefix, soqlQuote(c.Name))
+	if c.Namespace != "" {
+		criteria += fmt.Sprintf(" AND %sMetadataComponentNamespace = %s", prefix, soqlQuote(c.Namespace))
+	}
+	return criteria
+}
+
+// ComponentDependencies returns the components referenced by c.
+func (f *Force) ComponentDependencies(c MetadataComponent) ([]MetadataComponent, error) {
+	dependencies, err := f.QueryMetadataComponentDependencies(dependencyCriteria("", c))
+	if err != nil {
+		return nil, err
+	}
+	var components []MetadataComponent
+	for _, d := range dependencies {
+		components = append(components, d.RefComponent)
+	}
+	return components, nil
+}
+
+// ComponentDependents returns the components that reference c.
+func (f *Force) ComponentDependents(c MetadataComponent) ([]MetadataComponent, error) {
+	dependencies, err := f.QueryMetadataComponentDependencies(dependencyCriteria("Ref", c))
+	if err != nil {
+		return nil, err
+	}
+	var components []MetadataComponent
+	for _, d := range dependencies {
+		components = append(components, d.Component)
+	}
+	return components, nil
+}
+
+// DependencyTree builds the tree of dependencies of root in the given
+// direction, up to depth levels deep.  A depth less than 1 is unlimited.
+func (f *Force) DependencyTree(root MetadataComponent, direction DependencyDirection, depth int) (*DependencyNode, error) {
+	lookup := f.ComponentDependencies
+	if direction == DependencyUsedBy {
+		lookup = f.ComponentDependents
+	}
+	return BuildDependencyTree(root, depth, lookup)
+}
+
+// BuildDependencyTree builds a dependency tree from root using lookup to find
+// each component's neighbors.  Lookups are cached so that components
+// appearing in several branches are only queried once.
+func BuildDependencyTree(root MetadataComponent, depth int, lookup DependencyLookup) (*DependencyNode, error) {
+	cache := make(map[string][]MetadataComponent)
+	var build func(c MetadataComponent, level int, ancestors map[string]bool) (*DependencyNode, error)
+	build = func(c MetadataComponent, level int, ancestors map[string]bool) (*DependencyNode, error) {
+		node := &DependencyNode{MetadataComponent: c}
+		key := c.key()
+		if ancestors[key] {
+			node.Cycle = true
+			return node, nil
+		}
+		if depth > 0 && level >= depth {
+			return node, nil
+		}
+		neighbors, ok := cache[key]
+		if !ok {
+			var err error
+			neighbors, err = lookup(c)
+			if err != nil {
+				return nil, err
+			}
+			sort.Slice(neighbors, func(i, j int) bool {
+				return neighbors[i].key() < neighbors[j].key()
+			})
+			cache[key] = neighbors
+		}
+		ancestors[key] = true
+		defer delete(ancestors, key)
+		for _, n := range neighbors {
+			child, err := build(n, level+1, ancestors)
+			if err != nil {
+				return nil, err
+			}
+			node.Children = append(node.Children, child)
+		}
+		return node, nil
+	}
+	return build(root, 0, make(map[string]bool))
+}
+
+// Edges returns the distinct parent/child pairs in the tree.
+func (n *DependencyNode) Edges() [][2]MetadataComponent {
+	var edges [][2]MetadataComponent
+	seen := make(map[string]bool)
+	var walk func(node *DependencyNode)
+	walk = func(node *DependencyNode) {
+		for _, child := range node.Children {
+			key := node.key() + "->" + child.key()
+			if !seen[key] {
+				seen[key] = true
+				edges = append(edges, [2]MetadataComponent{node.MetadataComponent, child.MetadataComponent})
+			}
+			walk(child)
+		}
+	}
+	walk(n)
+	return edges
+}
+
+// PackageComponents returns the components listed in a package.xml file's
+// contents.  Wildcard members are skipped since they can't be looked up in
+// MetadataComponentDependency.
+func PackageComponents(packageXml []byte) ([]MetadataComponent, error) {
+	var p Package
+	if err := xml.Unmarshal(packageXml, &p); err != nil {
+		return nil, fmt.Errorf("Could not parse package.xml: %w", err)
+	}
+	var components []MetadataComponent
+	for _, t := range p.Types {
+		for _, m := range t.Members {
+			if m == "*" {
+				continue
+			}
+			components = append(components, NewMetadataComponent(t.Name, m))
+		}
+	}
+	return components, nil
+}
+
+// Child components are listed in package.xml as Object.Name, but
+// MetadataComponentDependency only reports their own name
+var objectChildTypes = map[string]bool{
+	"CustomField":    true,
+	"CompactLayout":  true,
+	"FieldSet":       true,
+	"ListView":       true,
+	"RecordType":     true,
+	"ValidationRule": true,
+	"WebLink":        true,
+}
+
+// NewMetadataComponent returns the component with the given metadata type and
+// package.xml member name, converting the name to the form reported by
+// MetadataComponentDependency.  For example, the CustomField
+// Account.ns__Region__c becomes Region in namespace ns.
+func NewMetadataComponent(metadataType string, name string) MetadataComponent {
+	c := MetadataComponent{Type: metadataType, Name: name}
+	if !objectChildTypes[metadataType] {
+		return c
+	}
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		c.Name = name[i+1:]
+	}
+	if metadataType == "CustomField" && strings.HasSuffix(c.Name, "__c") {
+		c.Name = strings.TrimSuffix(c.Name, "__c")
+		if parts := strings.SplitN(c.Name, "__", 2); len(parts) == 2 {
+			c.Namespace, c.Name = parts[0], parts[1]
+		}
+	}
+	return c
+}
@@ -0,0 +1,41 @@
+package lib_test
+
+import (
+	. "github.com/ForceCLI/force/lib"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Dependency", func() {
+	Describe("PackageComponents", func() {
+		It("should match the names reported by MetadataComponentDependency", func() {
+			components, err := PackageComponents([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<Package xmlns="http://soap.sforce.com/2006/04/metadata">
+	<types>
+		<members>Invoice</members>
+		<name>ApexClass</name>
+	</types>
+	<types>
+		<members>*</members>
+		<members>Account.Region__c</members>
+		<members>Account.ns__Tier__c</members>
+		<members>Account.Industry</members>
+		<name>CustomField</name>
+	</types>
+	<types>
+		<members>Invoice__c.Require_Amount</members>
+		<name>ValidationRule</name>
+	</types>
+</Package>`))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(components).To(Equal([]MetadataComponent{
+				{Type: "ApexClass", Name: "Invoice"},
+				{Type: "CustomField", Name: "Region"},
+				{Type: "CustomField", Name: "Tier", Namespace: "ns"},
+				{Type: "CustomField", Name: "Industry"},
+				{Type: "ValidationRule", Name: "Require_Amount"},
+			}))
+		})
+	})
+})
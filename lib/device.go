@@ -0,0 +1,90 @@
+package lib
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+)
+
+// DeviceAuthorization is the response to a device flow authorization
+// request.  The user visits VerificationUri and enters UserCode while the CLI
+// polls for the token using DeviceCode.
+type DeviceAuthorization struct {
+	DeviceCode      string `json:"device_code"`
+	UserCode        string `json:"user_code"`
+	VerificationUri string `json:"verification_uri"`
+	// Interval is the minimum number of seconds between polls
+	Interval int `json:"interval"`
+}
+
+const defaultDevicePollInterval = 5
+
+// RequestDeviceAuthorization starts the OAuth device flow
+func RequestDeviceAuthorization(endpoint string, clientId string) (auth DeviceAuthorization, err error) {
+	attrs := url.Values{}
+	attrs.Set("response_type", "device_code")
+	attrs.Set("client_id", clientId)
+	body, status, err := oauthTokenRequest(endpoint, attrs)
+	if err != nil {
+		return
+	}
+	if status != 200 {
+		err = fmt.Errorf("Could not start device login: %w", oauthError(body, status))
+		return
+	}
+	if err = json.Unmarshal(body, &auth); err != nil {
+		err = fmt.Errorf("Could not parse device authorization: %w", err)
+	}
+	return
+}
+
+// PollDeviceToken polls the token endpoint until the user approves or denies
+// the device authorization, or the code expires
+func PollDeviceToken(endpoint string, clientId string, auth DeviceAuthorization) (creds ForceSession, err error) {
+	interval := auth.Interval
+	if interval <= 0 {
+		interval = defaultDevicePollInterval
+	}
+	attrs := url.Values{}
+	attrs.Set("grant_type", "device")
+	attrs.Set("client_id", clientId)
+	attrs.Set("code", auth.DeviceCode)
+	for {
+		body, status, err := oauthTokenRequest(endpoint, attrs)
+		if err != nil {
+			return creds, err
+		}
+		if status == 200 {
+			return sessionFromTokenResponse(body, endpoint, clientId)
+		}
+		var token oauthTokenResponse
+		json.Unmarshal(body, &token)
+		switch token.Error {
+		case "authorization_pending":
+		case "slow_down":
+			interval += defaultDevicePollInterval
+		default:
+			return creds, fmt.Errorf("Device login failed: %w", oauthError(body, status))
+		}
+		time.Sleep(time.Duration(interval) * time.Second)
+	}
+}
+
+// ForceLoginAtEndpointAndSaveDevice logs in using the OAuth device flow,
+// which doesn't require a browser on the local machine
+func ForceLoginAtEndpointAndSaveDevice(endpoint string, output *os.File) (username string, err error) {
+	auth, err := RequestDeviceAuthorization(endpoint, ClientId)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(output, "To log in, visit %s and enter code %s\n", auth.VerificationUri, auth.UserCode)
+	fmt.Fprintln(output, "Waiting for approval...")
+	creds, err := PollDeviceToken(endpoint, ClientId, auth)
+	if err != nil {
+		return
+	}
+	username, err = ForceSaveLogin(creds, output)
+	return
+}
@@ -0,0 +1,72 @@
+package lib_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/ForceCLI/force/lib"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Device", func() {
+	var server *httptest.Server
+	var responses []string
+
+	BeforeEach(func() {
+		responses = nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.URL.Path).To(Equal("/services/oauth2/token"))
+			r.ParseForm()
+			w.Header().Set("Content-Type", "application/json")
+			switch r.Form.Get("response_type") {
+			case "device_code":
+				w.Write([]byte(`{"device_code":"dc","user_code":"ABCD-1234","verification_uri":"https://login.salesforce.com/setup/connect","interval":5}`))
+				return
+			}
+			Expect(r.Form.Get("grant_type")).To(Equal("device"))
+			Expect(r.Form.Get("code")).To(Equal("dc"))
+			response := responses[0]
+			responses = responses[1:]
+			if response != "" && response[0] == '!' {
+				w.WriteHeader(400)
+				response = response[1:]
+			}
+			w.Write([]byte(response))
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should request a device authorization", func() {
+		auth, err := RequestDeviceAuthorization(server.URL, "client")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(auth).To(Equal(DeviceAuthorization{
+			DeviceCode:      "dc",
+			UserCode:        "ABCD-1234",
+			VerificationUri: "https://login.salesforce.com/setup/connect",
+			Interval:        5,
+		}))
+	})
+
+	It("should return a session with a refresh token once approved", func() {
+		responses = []string{`{"access_token":"token","refresh_token":"refresh","instance_url":"https://example.my.salesforce.com"}`}
+		creds, err := PollDeviceToken(server.URL, "client", DeviceAuthorization{DeviceCode: "dc"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(creds.AccessToken).To(Equal("token"))
+		Expect(creds.RefreshToken).To(Equal("refresh"))
+		Expect(creds.InstanceUrl).To(Equal("https://example.my.salesforce.com"))
+		Expect(creds.EndpointUrl).To(Equal(server.URL))
+		Expect(creds.ClientId).To(Equal("client"))
+		Expect(creds.SessionOptions.RefreshMethod).To(Equal(RefreshMethod(RefreshOauth)))
+	})
+
+	It("should fail if the user denies access", func() {
+		responses = []string{`!{"error":"access_denied","error_description":"end-user denied authorization"}`}
+		_, err := PollDeviceToken(server.URL, "client", DeviceAuthorization{DeviceCode: "dc"})
+		Expect(err).To(MatchError("Device login failed: access_denied: end-user denied authorization"))
+	})
+})
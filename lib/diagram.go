@@ -0,0 +1,232 @@
+package lib
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SchemaDiagram is an entity-relationship diagram of a set of objects
+type SchemaDiagram struct {
+	Objects       []DiagramObject
+	Relationships []DiagramRelationship
+}
+
+// DiagramObject is an object in a schema diagram, with its key fields if
+// requested
+type DiagramObject struct {
+	Name   string
+	Label  string
+	Fields []DiagramField
+}
+
+// DiagramField is a key field of an object.  Key is PK, FK, or UK.
+type DiagramField struct {
+	Name string
+	Type string
+	Key  string
+}
+
+// DiagramRelationship is a lookup or master-detail relationship from a child
+// object to a parent object
+type DiagramRelationship struct {
+	Child        string
+	Field        string
+	Parent       string
+	MasterDetail bool
+	Required     bool
+}
+
+// DescribeSObjects describes each of the given objects
+func (f *Force) DescribeSObjects(names []string) ([]ForceSobject, error) {
+	var describes []ForceSobject
+	for _, name := range names {
+		body, err := f.DescribeSObject(name)
+		if err != nil {
+			return nil, fmt.Errorf("Could not describe %s: %w", name, err)
+		}
+		var sobject ForceSobject
+		if err = json.Unmarshal([]byte(body), &sobject); err != nil {
+			return nil, fmt.Errorf("Could not parse describe of %s: %w", name, err)
+		}
+		describes = append(describes, sobject)
+	}
+	return describes, nil
+}
+
+// NewSchemaDiagram builds a diagram of the relationships between the
+// described objects.  Relationships to objects that weren't described are
+// omitted.  If keyFields is true, the Id, name, reference, and unique fields
+// of each object are included.
+func NewSchemaDiagram(describes []ForceSobject, keyFields bool) SchemaDiagram {
+	var diagram SchemaDiagram
+	included := make(map[string]bool)
+	for _, d := range describes {
+		name, _ := d["name"].(string)
+		included[strings.ToLower(name)] = true
+	}
+	for _, d := range describes {
+		o := DiagramObject{}
+		o.Name, _ = d["name"].(string)
+		o.Label, _ = d["label"].(string)
+		fields, _ := d["fields"].([]interface{})
+		for _, f := range fields {
+			field, ok := f.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, _ := field["name"].(string)
+			fieldType, _ := field["type"].(string)
+			nameField, _ := field["nameField"].(bool)
+			unique, _ := field["unique"].(bool)
+			externalId, _ := field["externalId"].(bool)
+			var key string
+			switch {
+			case fieldType == "id":
+				key = "PK"
+			case fieldType == "reference":
+				key = "FK"
+			case unique || externalId:
+				key = "UK"
+			}
+
+			if fieldType == "reference" {
+				references, _ := field["referenceTo"].([]interface{})
+				masterDetail, _ := field["cascadeDelete"].(bool)
+				nillable, _ := field["nillable"].(bool)
+				for _, r := range references {
+					parent, _ := r.(string)
+					if !included[strings.ToLower(parent)] {
+						continue
+					}
+					diagram.Relationships = append(diagram.Relationships, DiagramRelationship{
+						Child:        o.Name,
+						Field:        name,
+						Parent:       parent,
+						MasterDetail: masterDetail,
+						Required:     !nillable,
+					})
+				}
+			}
+			if keyFields && (key != "" || nameField) {
+				o.Fields = append(o.Fields, DiagramField{Name: name, Type: fieldType, Key: key})
+			}
+		}
+		diagram.Objects = append(diagram.Objects, o)
+	}
+	sort.SliceStable(diagram.Relationships, func(i, j int) bool {
+		a, b := diagram.Relationships[i], diagram.Relationships[j]
+		if a.Child != b.Child {
+			return a.Child < b.Child
+		}
+		return a.Field < b.Field
+	})
+	return diagram
+}
+
+// crowsFoot returns the crow's foot notation for a relationship, used by
+// both Mermaid and PlantUML
+func (r DiagramRelationship) crowsFoot() string {
+	if r.Required || r.MasterDetail {
+		return "}o--||"
+	}
+	return "}o--o|"
+}
+
+// Mermaid renders the diagram as a Mermaid erDiagram
+func (d SchemaDiagram) Mermaid() string {
+	var b strings.Builder
+	b.WriteString("erDiagram\n")
+	for _, o := range d.Objects {
+		if len(o.Fields) == 0 {
+			fmt.Fprintf(&b, "    %s\n", o.Name)
+			continue
+		}
+		fmt.Fprintf(&b, "    %s {\n", o.Name)
+		for _, f := range o.Fields {
+			line := fmt.Sprintf("        %s %s", f.Type, f.Name)
+			if f.Key != "" {
+				line += " " + f.Key
+			}
+			b.WriteString(line + "\n")
+		}
+		b.WriteString("    }\n")
+	}
+	for _, r := range d.Relationships {
+		fmt.Fprintf(&b, "    %s %s %s : %s\n", r.Child, r.crowsFoot(), r.Parent, r.Field)
+	}
+	return b.String()
+}
+
+// Dot renders the diagram as a Graphviz digraph
+func (d SchemaDiagram) Dot() string {
+	var b strings.Builder
+	b.WriteString("digraph schema {\n")
+	b.WriteString("    rankdir=LR;\n")
+	b.WriteString("    node [shape=record];\n")
+	for _, o := range d.Objects {
+		label := dotEscape(o.Name)
+		if len(o.Fields) > 0 {
+			var fields []string
+			for _, f := range o.Fields {
+				field := dotEscape(f.Name) + " : " + dotEscape(f.Type)
+				if f.Key != "" {
+					field += " (" + f.Key + ")"
+				}
+				fields = append(fields, field+"\\l")
+			}
+			label = "{" + label + "|" + strings.Join(fields, "") + "}"
+		}
+		fmt.Fprintf(&b, "    %q [label=\"%s\"];\n", o.Name, label)
+	}
+	for _, r := range d.Relationships {
+		style := "dashed"
+		if r.MasterDetail {
+			style = "bold"
+		} else if r.Required {
+			style = "solid"
+		}
+		fmt.Fprintf(&b, "    %q -> %q [label=%q, style=%s];\n", r.Child, r.Parent, r.Field, style)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func dotEscape(s string) string {
+	return strings.NewReplacer(`{`, `\{`, `}`, `\}`, `|`, `\|`, `<`, `\<`, `>`, `\>`, `"`, `\"`).Replace(s)
+}
+
+// PlantUML renders the diagram as a PlantUML entity diagram
+func (d SchemaDiagram) PlantUML() string {
+	var b strings.Builder
+	b.WriteString("@startuml\n")
+	for _, o := range d.Objects {
+		if len(o.Fields) == 0 {
+			fmt.Fprintf(&b, "entity %s\n", o.Name)
+			continue
+		}
+		fmt.Fprintf(&b, "entity %s {\n", o.Name)
+		for _, f := range o.Fields {
+			if f.Key == "PK" {
+				fmt.Fprintf(&b, "    * %s : %s <<PK>>\n", f.Name, f.Type)
+			}
+		}
+		b.WriteString("    --\n")
+		for _, f := range o.Fields {
+			switch f.Key {
+			case "PK":
+			case "":
+				fmt.Fprintf(&b, "    %s : %s\n", f.Name, f.Type)
+			default:
+				fmt.Fprintf(&b, "    %s : %s <<%s>>\n", f.Name, f.Type, f.Key)
+			}
+		}
+		b.WriteString("}\n")
+	}
+	for _, r := range d.Relationships {
+		fmt.Fprintf(&b, "%s %s %s : %s\n", r.Child, r.crowsFoot(), r.Parent, r.Field)
+	}
+	b.WriteString("@enduml\n")
+	return b.String()
+}
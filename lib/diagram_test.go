@@ -0,0 +1,76 @@
+package lib_test
+
+import (
+	. "github.com/ForceCLI/force/lib"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SchemaDiagram", func() {
+	describes := []ForceSobject{
+		{
+			"name": "Account",
+			"fields": []interface{}{
+				map[string]interface{}{"name": "Id", "type": "id", "nillable": false},
+				map[string]interface{}{"name": "Name", "type": "string", "nameField": true},
+				map[string]interface{}{"name": "OwnerId", "type": "reference", "referenceTo": []interface{}{"User"}},
+			},
+		},
+		{
+			"name": "Invoice__c",
+			"fields": []interface{}{
+				map[string]interface{}{"name": "Id", "type": "id", "nillable": false},
+				map[string]interface{}{"name": "Account__c", "type": "reference", "referenceTo": []interface{}{"Account"}, "nillable": true},
+				map[string]interface{}{"name": "Number__c", "type": "string", "externalId": true},
+				map[string]interface{}{"name": "Notes__c", "type": "textarea"},
+			},
+		},
+		{
+			"name": "Invoice_Line__c",
+			"fields": []interface{}{
+				map[string]interface{}{"name": "Invoice__c", "type": "reference", "referenceTo": []interface{}{"Invoice__c"}, "cascadeDelete": true, "nillable": false},
+			},
+		},
+	}
+
+	It("should only include relationships between the described objects", func() {
+		diagram := NewSchemaDiagram(describes, false)
+		Expect(diagram.Relationships).To(Equal([]DiagramRelationship{
+			{Child: "Invoice_Line__c", Field: "Invoice__c", Parent: "Invoice__c", MasterDetail: true, Required: true},
+			{Child: "Invoice__c", Field: "Account__c", Parent: "Account"},
+		}))
+		Expect(diagram.Objects[0].Fields).To(BeEmpty())
+	})
+
+	It("should include key fields", func() {
+		diagram := NewSchemaDiagram(describes, true)
+		Expect(diagram.Objects[1].Fields).To(Equal([]DiagramField{
+			{Name: "Id", Type: "id", Key: "PK"},
+			{Name: "Account__c", Type: "reference", Key: "FK"},
+			{Name: "Number__c", Type: "string", Key: "UK"},
+		}))
+	})
+
+	It("should render Mermaid", func() {
+		mermaid := NewSchemaDiagram(describes, true).Mermaid()
+		Expect(mermaid).To(HavePrefix("erDiagram\n"))
+		Expect(mermaid).To(ContainSubstring("        string Name\n"))
+		Expect(mermaid).To(ContainSubstring("    Invoice_Line__c }o--|| Invoice__c : Invoice__c\n"))
+		Expect(mermaid).To(ContainSubstring("    Invoice__c }o--o| Account : Account__c\n"))
+	})
+
+	It("should render Graphviz", func() {
+		dot := NewSchemaDiagram(describes, false).Dot()
+		Expect(dot).To(ContainSubstring(`"Invoice_Line__c" -> "Invoice__c" [label="Invoice__c", style=bold];`))
+		Expect(dot).To(ContainSubstring(`"Invoice__c" -> "Account" [label="Account__c", style=dashed];`))
+	})
+
+	It("should render PlantUML", func() {
+		uml := NewSchemaDiagram(describes, true).PlantUML()
+		Expect(uml).To(HavePrefix("@startuml\n"))
+		Expect(uml).To(ContainSubstring("    * Id : id <<PK>>\n"))
+		Expect(uml).To(ContainSubstring("    Number__c : string <<UK>>\n"))
+		Expect(uml).To(HaveSuffix("@enduml\n"))
+	})
+})
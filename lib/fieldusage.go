@@ -0,0 +1,225 @@
+package lib
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// FieldUsage reports how many records of an object have a value for a field
+type FieldUsage struct {
+	Name  string
+	Label string
+	Type  string
+	// Countable is false for fields that can't be counted or filtered in
+	// SOQL, such as long text areas
+	Countable bool
+	Populated int
+	Total     int
+	// LastPopulated is the most recent LastModifiedDate of a record with a
+	// value for the field
+	LastPopulated string
+	// FieldLastModified is when a custom field's definition was last
+	// modified
+	FieldLastModified string
+}
+
+// Percent returns the percentage of records with a value for the field.
+// Checkbox fields are populated if they are checked.
+func (u FieldUsage) Percent() float64 {
+	if u.Total == 0 {
+		return 0
+	}
+	return float64(u.Populated) / float64(u.Total) * 100
+}
+
+// FieldUsageOptions control how field usage is measured
+type FieldUsageOptions struct {
+	// LastPopulated runs a query per field to find the most recently
+	// modified record with a value for the field
+	LastPopulated bool
+	// CustomOnly limits the analysis to custom fields
+	CustomOnly bool
+}
+
+const fieldUsageChunkSize = 50
+
+// FieldUsageCountQuery returns an aggregate query counting the non-null
+// values of each field, aliased as c0, c1, etc.
+func FieldUsageCountQuery(object string, fields []string) string {
+	var counts []string
+	for i, f := range fields {
+		counts = append(counts, fmt.Sprintf("COUNT(%s) c%d", f, i))
+	}
+	return fmt.Sprintf("SELECT %s FROM %s", strings.Join(counts, ", "), object)
+}
+
+// FieldUsageFilter returns the WHERE condition matching records where the
+// field is populated
+func FieldUsageFilter(field string, fieldType string) string {
+	if fieldType == "boolean" {
+		return fmt.Sprintf("%s = true", field)
+	}
+	return fmt.Sprintf("%s != null", field)
+}
+
+// SortFieldUsage sorts fields from least to most populated, with fields that
+// couldn't be counted last
+func SortFieldUsage(usage []FieldUsage) {
+	sort.SliceStable(usage, func(i, j int) bool {
+		a, b := usage[i], usage[j]
+		if a.Countable != b.Countable {
+			return a.Countable
+		}
+		if a.Populated != b.Populated {
+			return a.Populated < b.Populated
+		}
+		return a.Name < b.Name
+	})
+}
+
+// FieldUsage measures how many records of an object have a value for each
+// field, sorted from least to most populated
+func (f *Force) FieldUsage(object string, options FieldUsageOptions) ([]FieldUsage, error) {
+	sobject, err := f.GetSobject(object)
+	if err != nil {
+		return nil, fmt.Errorf("Could not describe %s: %w", object, err)
+	}
+	total, err := f.Query(fmt.Sprintf("SELECT COUNT() FROM %s", object))
+	if err != nil {
+		return nil, fmt.Errorf("Could not count %s records: %w", object, err)
+	}
+
+	var usage []FieldUsage
+	var aggregatable []int
+	hasLastModifiedDate := false
+	fields, _ := sobject["fields"].([]interface{})
+	for _, fi := range fields {
+		field, ok := fi.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		u := FieldUsage{Total: total.TotalSize}
+		u.Name, _ = field["name"].(string)
+		u.Label, _ = field["label"].(string)
+		u.Type, _ = field["type"].(string)
+		if u.Name == "LastModifiedDate" {
+			hasLastModifiedDate = true
+		}
+		custom, _ := field["custom"].(bool)
+		if options.CustomOnly && !custom {
+			continue
+		}
+		canAggregate, _ := field["aggregatable"].(bool)
+		filterable, _ := field["filterable"].(bool)
+		switch {
+		case canAggregate && u.Type != "boolean":
+			aggregatable = append(aggregatable, len(usage))
+			u.Countable = true
+		case filterable:
+			u.Countable = true
+			result, err := f.Query(fmt.Sprintf("SELECT COUNT() FROM %s WHERE %s", object, FieldUsageFilter(u.Name, u.Type)))
+			if err != nil {
+				return nil, fmt.Errorf("Could not count %s: %w", u.Name, err)
+			}
+			u.Populated = result.TotalSize
+		}
+		usage = append(usage, u)
+	}
+
+	for start := 0; start < len(aggregatable); start += fieldUsageChunkSize {
+		end := start + fieldUsageChunkSize
+		if end > len(aggregatable) {
+			end = len(aggregatable)
+		}
+		var names []string
+		for _, i := range aggregatable[start:end] {
+			names = append(names, usage[i].Name)
+		}
+		result, err := f.Query(FieldUsageCountQuery(object, names))
+		if err != nil {
+			return nil, fmt.Errorf("Could not count %s fields: %w", object, err)
+		}
+		if len(result.Records) == 0 {
+			continue
+		}
+		for n, i := range aggregatable[start:end] {
+			count, _ := result.Records[0][fmt.Sprintf("c%d", n)].(float64)
+			usage[i].Populated = int(count)
+		}
+	}
+
+	if options.LastPopulated && hasLastModifiedDate {
+		for i, u := range usage {
+			if !u.Countable || u.Populated == 0 {
+				continue
+			}
+			result, err := f.Query(fmt.Sprintf("SELECT MAX(LastModifiedDate) m FROM %s WHERE %s", object, FieldUsageFilter(u.Name, u.Type)))
+			if err != nil {
+				return nil, fmt.Errorf("Could not query last modified date for %s: %w", u.Name, err)
+			}
+			if len(result.Records) > 0 && result.Records[0]["m"] != nil {
+				usage[i].LastPopulated = fmt.Sprint(result.Records[0]["m"])
+			}
+		}
+	}
+
+	modified, err := f.customFieldLastModifiedDates(object)
+	if err != nil {
+		return nil, err
+	}
+	for i, u := range usage {
+		usage[i].FieldLastModified = modified[strings.ToLower(u.Name)]
+	}
+
+	SortFieldUsage(usage)
+	return usage, nil
+}
+
+// customFieldLastModifiedDates returns when each custom field of an object
+// was last modified, keyed by lowercase field name
+func (f *Force) customFieldLastModifiedDates(object string) (map[string]string, error) {
+	modified := make(map[string]string)
+	tableEnumOrId := object
+	if strings.HasSuffix(object, "__c") {
+		namespace, developerName := splitNamespace(strings.TrimSuffix(object, "__c"))
+		soql := fmt.Sprintf("SELECT Id FROM CustomObject WHERE DeveloperName = %s", soqlQuote(developerName))
+		if namespace != "" {
+			soql += fmt.Sprintf(" AND NamespacePrefix = %s", soqlQuote(namespace))
+		}
+		var objects []struct {
+			Id string
+		}
+		if err := f.QueryInto(soql, &objects, isTooling); err != nil {
+			return nil, fmt.Errorf("Could not query custom object %s: %w", object, err)
+		}
+		if len(objects) == 0 {
+			return modified, nil
+		}
+		tableEnumOrId = objects[0].Id
+	}
+	var fields []struct {
+		DeveloperName    string
+		NamespacePrefix  string
+		LastModifiedDate string
+	}
+	soql := fmt.Sprintf("SELECT DeveloperName, NamespacePrefix, LastModifiedDate FROM CustomField WHERE TableEnumOrId = %s", soqlQuote(tableEnumOrId))
+	if err := f.QueryInto(soql, &fields, isTooling); err != nil {
+		return nil, fmt.Errorf("Could not query custom fields of %s: %w", object, err)
+	}
+	for _, field := range fields {
+		name := field.DeveloperName + "__c"
+		if field.NamespacePrefix != "" {
+			name = field.NamespacePrefix + "__" + name
+		}
+		modified[strings.ToLower(name)] = field.LastModifiedDate
+	}
+	return modified, nil
+}
+
+func splitNamespace(name string) (namespace string, developerName string) {
+	if parts := strings.SplitN(name, "__", 2); len(parts) == 2 {
+		return parts[0], parts[1]
+	}
+	return "", name
+}
@@ -0,0 +1,39 @@
+package lib_test
+
+import (
+	. "github.com/ForceCLI/force/lib"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FieldUsage", func() {
+	It("should count fields with aliases", func() {
+		Expect(FieldUsageCountQuery("Account", []string{"Industry", "Region__c"})).To(Equal("SELECT COUNT(Industry) c0, COUNT(Region__c) c1 FROM Account"))
+	})
+
+	It("should treat checked checkboxes as populated", func() {
+		Expect(FieldUsageFilter("Active__c", "boolean")).To(Equal("Active__c = true"))
+		Expect(FieldUsageFilter("Region__c", "string")).To(Equal("Region__c != null"))
+	})
+
+	It("should calculate the percent populated", func() {
+		Expect(FieldUsage{Populated: 1, Total: 4}.Percent()).To(Equal(25.0))
+		Expect(FieldUsage{}.Percent()).To(Equal(0.0))
+	})
+
+	It("should sort least populated first, with uncountable fields last", func() {
+		usage := []FieldUsage{
+			{Name: "Notes__c"},
+			{Name: "Industry", Countable: true, Populated: 10},
+			{Name: "Region__c", Countable: true, Populated: 0},
+			{Name: "Active__c", Countable: true, Populated: 0},
+		}
+		SortFieldUsage(usage)
+		var names []string
+		for _, u := range usage {
+			names = append(names, u.Name)
+		}
+		Expect(names).To(Equal([]string{"Active__c", "Region__c", "Industry", "Notes__c"}))
+	})
+})
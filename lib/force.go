@@ -30,8 +30,20 @@ var forceQuery = query.Query
 var (
 	ClientId    = "3MVG9ytVT1SanXDnX_hOa9Ys5NxVp5C26JlyQjwr.xTJtUqoKonXY.M8CcjoEknMrV4YUvPvXLiMyzI.Aw23C"
 	RedirectUri = "http://localhost:3835/oauth/callback"
+	// CallbackPort is the local port that receives the OAuth callback
+	CallbackPort = DefaultCallbackPort
 )
 
+const DefaultCallbackPort = 3835
+
+// redirectUri returns the OAuth callback URL for CallbackPort
+func redirectUri() string {
+	if CallbackPort == DefaultCallbackPort {
+		return RedirectUri
+	}
+	return fmt.Sprintf("http://localhost:%d/oauth/callback", CallbackPort)
+}
+
 var Timeout int64 = 0
 var CustomEndpoint = ``
 var SessionExpiredError = errors.New("Session expired")
@@ -77,6 +89,10 @@ type SessionOptions struct {
 	ApiVersion    string
 	Alias         string
 	RefreshMethod RefreshMethod
+	// SharedRefreshToken is set when the refresh token was imported from
+	// another tool, e.g. with an SFDX auth URL, so logging out shouldn't
+	// revoke it.
+	SharedRefreshToken bool `json:",omitempty"`
 	// RefreshFunc can be set to support refreshing of additional session/refresh types than
 	// available in RefreshMethod. It should return an error on failure.
 	// On success, it is responsible for updating the given Force's credentials.
@@ -99,6 +115,7 @@ type ForceSession struct {
 	Scope          string `json:"scope"`
 	Id             string `json:"id"`
 	ClientId       string
+	ClientSecret   string `json:",omitempty"`
 	RefreshToken   string
 	ForceEndpoint  ForceEndpoint
 	EndpointUrl    string `json:"endpoint_url"`
@@ -390,9 +407,12 @@ func ForceLogin(endpoint ForceEndpoint) (creds ForceSession, err error) {
 func ForceLoginAtEndpointWithPrompt(endpoint string, prompt string) (creds ForceSession, err error) {
 	ch := make(chan ForceSession)
 	port, err := startLocalHttpServer(ch)
+	if err != nil {
+		return creds, fmt.Errorf("Could not listen for OAuth callback: %w", err)
+	}
 	var url string
 
-	Redir := RedirectUri
+	Redir := redirectUri()
 
 	url = fmt.Sprintf("%s/services/oauth2/authorize?response_type=token&client_id=%s&redirect_uri=%s&state=%d&prompt=%s", endpoint, ClientId, Redir, port, prompt)
 
@@ -768,6 +788,24 @@ func (f *Force) Query(qs string, options ...func(*QueryOptions)) (ForceQueryResu
 	return result, err
 }
 
+func isTooling(options *QueryOptions) {
+	options.IsTooling = true
+}
+
+// QueryInto runs a query and unmarshals the records into out, which should be
+// a pointer to a slice of structs with fields matching the queried fields.
+func (f *Force) QueryInto(qs string, out interface{}, options ...func(*QueryOptions)) error {
+	result, err := f.Query(qs, options...)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(result.Records)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
 func (f *Force) QueryOptions() []query.Option {
 	instUrl := ""
 	if f.Credentials != nil {
@@ -1514,7 +1552,7 @@ func (result *ForceQueryResult) Update(other ForceQueryResult, force *Force) {
 }
 
 func startLocalHttpServer(ch chan ForceSession) (port int, err error) {
-	listener, err := net.Listen("tcp", ":3835")
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", CallbackPort))
 	if err != nil {
 		return
 	}
@@ -23,6 +23,13 @@ import (
 	"time"
 )
 
+// TestSuites is a collection of test suites.
+type TestSuites struct {
+	XMLName xml.Name `xml:"testsuites"`
+
+	Suites []TestSuite `xml:"testsuite"`
+}
+
 // TestSuite is a top-level test suite containing test cases.
 type TestSuite struct {
 	XMLName xml.Name `xml:"testsuite"`
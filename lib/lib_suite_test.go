@@ -30,6 +30,7 @@ func mustRead(r io.Reader) []byte {
 }
 
 var XmlHeaders = map[string][]string{"Content-Type": {"application/xml"}}
+var JsonHeaders = map[string][]string{"Content-Type": {"application/json"}}
 
 const loginFaultBody = `<?xml version="1.0" encoding="UTF-8"?>
 <error xmlns="http://www.force.com/2009/06/asyncapi/dataload">
@@ -0,0 +1,171 @@
+package lib
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	. "github.com/ForceCLI/force/config"
+)
+
+const (
+	LoginValid     = "valid"
+	LoginRefreshed = "refreshed"
+	LoginExpired   = "expired"
+	LoginInvalid   = "invalid"
+	LoginError     = "error"
+)
+
+// LoginCheck is the result of validating a saved login
+type LoginCheck struct {
+	Account string
+	// Status is valid, refreshed, expired, invalid, or error
+	Status string
+	Error  error
+}
+
+// Prunable returns true if the saved login can't be used and should be
+// removed.  Logins that couldn't be checked, e.g. because of a network error,
+// are not prunable.
+func (c LoginCheck) Prunable() bool {
+	return c.Status == LoginExpired || c.Status == LoginInvalid
+}
+
+// RevokeToken revokes an OAuth access or refresh token.  Revoking a refresh
+// token also revokes the access tokens issued with it.
+func RevokeToken(endpoint string, token string) error {
+	attrs := url.Values{}
+	attrs.Set("token", token)
+	body, status, err := oauthRequest(fmt.Sprintf("%s/services/oauth2/revoke", endpoint), attrs)
+	if err != nil {
+		return err
+	}
+	if status != 200 {
+		var response oauthTokenResponse
+		if jsonErr := json.Unmarshal(body, &response); jsonErr == nil && response.Error == "invalid_token" {
+			// Already expired or revoked
+			return nil
+		}
+		return fmt.Errorf("Could not revoke token: %w", oauthError(body, status))
+	}
+	return nil
+}
+
+// RevokeSession revokes the session's refresh token and access token.
+// Sessions refreshed through SFDX or imported from an SFDX auth URL are left
+// alone since their tokens are shared with other tools.
+func (f *Force) RevokeSession() error {
+	creds := f.Credentials
+	if options := creds.SessionOptions; options != nil && (options.RefreshMethod == RefreshSFDX || options.SharedRefreshToken) {
+		return nil
+	}
+	endpoint := creds.InstanceUrl
+	if endpoint == "" {
+		endpoint = creds.EndpointUrl
+	}
+	var errs []error
+	// Revoke the access token even if revoking the refresh token fails
+	for _, token := range []string{creds.RefreshToken, creds.AccessToken} {
+		if token == "" {
+			continue
+		}
+		if err := RevokeToken(endpoint, token); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// CheckLogin validates a saved login by requesting the user's info,
+// refreshing the session if needed.  A refreshed session is saved without
+// changing the active login.
+func CheckLogin(account string) LoginCheck {
+	check := LoginCheck{Account: account}
+	creds, err := LoadLogin(account)
+	switch {
+	case err == CredentialsPassphraseRequiredError || err == CredentialsDecryptionError:
+		check.Status, check.Error = LoginError, err
+		return check
+	case err != nil:
+		check.Status, check.Error = LoginInvalid, err
+		return check
+	}
+	return checkSession(check, creds)
+}
+
+func checkSession(check LoginCheck, creds ForceSession) LoginCheck {
+	// Refresh the session explicitly rather than when the request fails so
+	// a rejected refresh token can be told apart from other failures
+	options := SessionOptions{}
+	if creds.SessionOptions != nil {
+		options = *creds.SessionOptions
+	}
+	options.RefreshFunc = func(*Force) error {
+		return SessionRefreshUnavailable
+	}
+	creds.SessionOptions = &options
+	f := NewForce(&creds)
+	_, err := f.UserInfo()
+	if err == SessionExpiredError {
+		var refreshed ForceSession
+		refreshed, err = f.refreshedSession()
+		switch {
+		case err == SessionRefreshError || err == SessionRefreshUnavailable:
+			check.Status, check.Error = LoginExpired, err
+			return check
+		case err != nil:
+			check.Status, check.Error = LoginError, err
+			return check
+		}
+		f.CopyCredentialAuthFields(&refreshed)
+		if err = SaveLogin(*f.Credentials); err != nil {
+			check.Status, check.Error = LoginError, err
+			return check
+		}
+		check.Status = LoginRefreshed
+		_, err = f.UserInfo()
+	}
+	switch {
+	case err == SessionExpiredError:
+		check.Status, check.Error = LoginExpired, err
+	case err != nil:
+		check.Status, check.Error = LoginError, err
+	case check.Status == "":
+		check.Status = LoginValid
+	}
+	return check
+}
+
+// CheckLogins validates the saved logins concurrently
+func CheckLogins(accounts []string) []LoginCheck {
+	const concurrency = 8
+	checks := make([]LoginCheck, len(accounts))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, account := range accounts {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, account string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			checks[i] = CheckLogin(account)
+		}(i, account)
+	}
+	wg.Wait()
+	return checks
+}
+
+// SavedLogins returns the names of the saved logins
+func SavedLogins() []string {
+	var logins []string
+	accounts, _ := Config.List("accounts")
+	for _, account := range accounts {
+		if !strings.HasPrefix(account, ".") {
+			logins = append(logins, account)
+		}
+	}
+	return logins
+}
@@ -0,0 +1,139 @@
+package lib_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ForceCLI/config"
+	forceConfig "github.com/ForceCLI/force/config"
+	. "github.com/ForceCLI/force/lib"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Logins", func() {
+	Describe("DeleteLogin", func() {
+		var originalConfig *config.Config
+
+		BeforeEach(func() {
+			originalConfig = forceConfig.Config
+			forceConfig.Config = config.NewConfig("force-delete-login-test")
+			forceConfig.Config.Save("accounts", "admin@example.com", "{}")
+			forceConfig.Config.Save("accounts", "qa@example.com", "{}")
+		})
+
+		AfterEach(func() {
+			forceConfig.Config = originalConfig
+			home, _ := os.UserHomeDir()
+			os.RemoveAll(filepath.Join(home, ".force-delete-login-test"))
+		})
+
+		It("should change the active login when it is deleted", func() {
+			Expect(SetActiveLogin("qa@example.com")).To(Succeed())
+			Expect(DeleteLogin("qa@example.com")).To(Succeed())
+			Expect(forceConfig.Config.Load("current", "account")).To(Equal("admin@example.com"))
+		})
+
+		It("should reset the active login if it points to a missing login", func() {
+			Expect(SetActiveLogin("gone@example.com")).To(Succeed())
+			Expect(DeleteLogin("gone@example.com")).ToNot(Succeed())
+			Expect(forceConfig.Config.Load("current", "account")).To(Equal("admin@example.com"))
+		})
+
+		It("should keep the active login when another login is deleted", func() {
+			Expect(SetActiveLogin("admin@example.com")).To(Succeed())
+			Expect(DeleteLogin("qa@example.com")).To(Succeed())
+			Expect(forceConfig.Config.Load("current", "account")).To(Equal("admin@example.com"))
+		})
+	})
+
+	Describe("RevokeToken", func() {
+		var server *httptest.Server
+		var revoked []string
+		var response string
+
+		BeforeEach(func() {
+			revoked = nil
+			response = ""
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				Expect(r.URL.Path).To(Equal("/services/oauth2/revoke"))
+				r.ParseForm()
+				revoked = append(revoked, r.Form.Get("token"))
+				if response != "" {
+					w.Header().Set("Content-Type", "application/json")
+					w.WriteHeader(400)
+					w.Write([]byte(response))
+				}
+			}))
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("should revoke the token", func() {
+			Expect(RevokeToken(server.URL, "token")).To(Succeed())
+			Expect(revoked).To(Equal([]string{"token"}))
+		})
+
+		It("should ignore tokens that are already invalid", func() {
+			response = `{"error":"invalid_token","error_description":"invalid token"}`
+			Expect(RevokeToken(server.URL, "token")).To(Succeed())
+		})
+
+		It("should return other errors", func() {
+			response = `{"error":"unsupported_token_type","error_description":"unsupported token type"}`
+			err := RevokeToken(server.URL, "token")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("unsupported token type"))
+		})
+
+		It("should revoke the refresh token and access token of a session", func() {
+			f := NewForce(&ForceSession{
+				InstanceUrl:  server.URL,
+				AccessToken:  "access",
+				RefreshToken: "refresh",
+			})
+			Expect(f.RevokeSession()).To(Succeed())
+			Expect(revoked).To(Equal([]string{"refresh", "access"}))
+		})
+
+		It("should revoke the access token if revoking the refresh token fails", func() {
+			response = `{"error":"unsupported_token_type","error_description":"unsupported token type"}`
+			f := NewForce(&ForceSession{
+				InstanceUrl:  server.URL,
+				AccessToken:  "access",
+				RefreshToken: "refresh",
+			})
+			err := f.RevokeSession()
+			Expect(err).To(HaveOccurred())
+			Expect(strings.Count(err.Error(), "unsupported token type")).To(Equal(2))
+			Expect(revoked).To(Equal([]string{"refresh", "access"}))
+		})
+
+		It("should not revoke tokens imported from an SFDX auth URL", func() {
+			f := NewForce(&ForceSession{
+				InstanceUrl:    server.URL,
+				AccessToken:    "access",
+				RefreshToken:   "refresh",
+				SessionOptions: &SessionOptions{RefreshMethod: RefreshOauth, SharedRefreshToken: true},
+			})
+			Expect(f.RevokeSession()).To(Succeed())
+			Expect(revoked).To(BeEmpty())
+		})
+	})
+
+	Describe("LoginCheck", func() {
+		It("should only prune expired and invalid logins", func() {
+			Expect(LoginCheck{Status: LoginExpired}.Prunable()).To(BeTrue())
+			Expect(LoginCheck{Status: LoginInvalid}.Prunable()).To(BeTrue())
+			Expect(LoginCheck{Status: LoginValid}.Prunable()).To(BeFalse())
+			Expect(LoginCheck{Status: LoginRefreshed}.Prunable()).To(BeFalse())
+			Expect(LoginCheck{Status: LoginError}.Prunable()).To(BeFalse())
+		})
+	})
+})
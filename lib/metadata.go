@@ -1217,17 +1217,37 @@ func (fm *ForceMetadata) Deploy(files ForceMetadataFiles, options ForceDeployOpt
 	return
 }
 
+// deployMetadataFiles deploys files as a single package, rolling back on any
+// error, and returns an error listing the failures if the deploy fails
+func (f *Force) deployMetadataFiles(files ForceMetadataFiles, description string) error {
+	result, err := f.Metadata.Deploy(files, ForceDeployOptions{RollbackOnError: true, SinglePackage: true})
+	if err != nil {
+		return fmt.Errorf("Could not deploy %s: %w", description, err)
+	}
+	if !result.Success {
+		var problems []string
+		for _, f := range result.Details.ComponentFailures {
+			problems = append(problems, fmt.Sprintf("%s: %s", f.FullName, f.Problem))
+		}
+		if result.ErrorMessage != "" {
+			problems = append(problems, result.ErrorMessage)
+		}
+		return fmt.Errorf("Could not deploy %s:\n%s", description, strings.Join(problems, "\n"))
+	}
+	return nil
+}
+
 // Start a deployment of metadata and return the deploy id
 func (fm *ForceMetadata) StartDeploy(files ForceMetadataFiles, options ForceDeployOptions) (string, error) {
 	zipfile, err := fm.MakeZip(files)
 	if err != nil {
 		return "", err
 	}
-	return fm.startDeployZipFile(zipfile, options)
+	return fm.StartDeployZipFile(zipfile, options)
 }
 
 func (fm *ForceMetadata) DeployZipFile(zipfile []byte, options ForceDeployOptions) (results ForceCheckDeploymentStatusResult, err error) {
-	deployId, err := fm.startDeployZipFile(zipfile, options)
+	deployId, err := fm.StartDeployZipFile(zipfile, options)
 	if err != nil {
 		return results, err
 	}
@@ -1254,7 +1274,8 @@ func deploySoapBody(zipfile []byte, options ForceDeployOptions) string {
 	return soapBody.String()
 }
 
-func (fm *ForceMetadata) startDeployZipFile(zipfile []byte, options ForceDeployOptions) (string, error) {
+// Start a deployment of a zip file and return the deploy id
+func (fm *ForceMetadata) StartDeployZipFile(zipfile []byte, options ForceDeployOptions) (string, error) {
 	body, err := fm.soapExecute("deploy", deploySoapBody(zipfile, options))
 	if err != nil {
 		return "", err
@@ -0,0 +1,80 @@
+package lib
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+)
+
+// oauthTokenResponse is the response from the OAuth token endpoint.
+// ForceSession doesn't map refresh_token, so it's parsed separately.
+type oauthTokenResponse struct {
+	RefreshToken     string `json:"refresh_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// oauthTokenRequest posts to the OAuth token endpoint, returning the response
+// body and status code
+func oauthTokenRequest(endpoint string, attrs url.Values) (body []byte, status int, err error) {
+	return oauthRequest(tokenURL(endpoint), attrs)
+}
+
+func oauthRequest(requestUrl string, attrs url.Values) (body []byte, status int, err error) {
+	req, err := httpRequest("POST", requestUrl, bytes.NewReader([]byte(attrs.Encode())))
+	if err != nil {
+		return
+	}
+	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Add("Accept", "application/json")
+	res, err := doRequest(req, redirectPostOn302)
+	if err != nil {
+		return
+	}
+	defer res.Body.Close()
+	body, err = io.ReadAll(res.Body)
+	status = res.StatusCode
+	return
+}
+
+// sessionFromTokenResponse builds a session from a successful response from
+// the token endpoint, enabling OAuth refresh if a refresh token was issued
+func sessionFromTokenResponse(body []byte, endpoint string, clientId string) (creds ForceSession, err error) {
+	if err = json.Unmarshal(body, &creds); err != nil {
+		return creds, fmt.Errorf("Could not parse token response: %w", err)
+	}
+	var token oauthTokenResponse
+	json.Unmarshal(body, &token)
+	creds.RefreshToken = token.RefreshToken
+	creds.SessionOptions = &SessionOptions{}
+	if creds.RefreshToken != "" {
+		creds.SessionOptions.RefreshMethod = RefreshOauth
+	}
+	creds.EndpointUrl = endpoint
+	creds.ClientId = clientId
+	return
+}
+
+// oauthError returns the error from a failed token request
+func oauthError(body []byte, status int) error {
+	var token oauthTokenResponse
+	if err := json.Unmarshal(body, &token); err != nil || token.Error == "" {
+		return fmt.Errorf("Token request failed with status %d", status)
+	}
+	if token.ErrorDescription != "" {
+		return fmt.Errorf("%s: %s", token.Error, token.ErrorDescription)
+	}
+	return fmt.Errorf("%s", token.Error)
+}
+
+// refreshTokenError returns the error from a failed refresh token request,
+// returning SessionRefreshError if the refresh token was rejected
+func refreshTokenError(body []byte, status int) error {
+	var token oauthTokenResponse
+	if err := json.Unmarshal(body, &token); err == nil && token.Error == "invalid_grant" {
+		return SessionRefreshError
+	}
+	return fmt.Errorf("Could not refresh session: %w", oauthError(body, status))
+}
@@ -0,0 +1,337 @@
+package lib
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Package2 is a second-generation package on a Dev Hub
+type Package2 struct {
+	Id               string
+	Name             string
+	NamespacePrefix  string
+	ContainerOptions string
+	Description      string
+}
+
+// Package2Version is a version of a second-generation package
+type Package2Version struct {
+	Id                         string
+	Package2Id                 string
+	SubscriberPackageVersionId string
+	Name                       string
+	Description                string
+	MajorVersion               int
+	MinorVersion               int
+	PatchVersion               int
+	BuildNumber                int
+	IsReleased                 bool
+	Tag                        string
+	Branch                     string
+	ValidationSkipped          bool
+	HasPassedCodeCoverageCheck bool
+	CodeCoverage               *struct {
+		ApexCodeCoveragePercentage float64 `json:"apexCodeCoveragePercentage"`
+	}
+	CreatedDate string
+}
+
+// Package2VersionCreateRequest tracks the asynchronous creation of a package
+// version
+type Package2VersionCreateRequest struct {
+	Id                string
+	Package2Id        string
+	Package2VersionId string
+	Status            string
+	Tag               string
+	Branch            string
+	CreatedDate       string
+}
+
+// Package2VersionCreateOptions are the options used to create a package
+// version.  VersionNumber may end in NEXT to use the next build number.
+type Package2VersionCreateOptions struct {
+	Package2Id            string
+	VersionName           string
+	VersionNumber         string
+	VersionDescription    string
+	AncestorId            string
+	Tag                   string
+	Branch                string
+	InstallationKey       string
+	CalculateCodeCoverage bool
+	SkipValidation        bool
+	Dependencies          []string
+}
+
+type package2Descriptor struct {
+	Id                 string                      `json:"id"`
+	VersionName        string                      `json:"versionName,omitempty"`
+	VersionNumber      string                      `json:"versionNumber"`
+	VersionDescription string                      `json:"versionDescription,omitempty"`
+	AncestorId         string                      `json:"ancestorId,omitempty"`
+	Dependencies       []package2DescriptorVersion `json:"dependencies,omitempty"`
+}
+
+type package2DescriptorVersion struct {
+	SubscriberPackageVersionId string `json:"subscriberPackageVersionId"`
+}
+
+const (
+	Package2VersionCreateQueued     = "Queued"
+	Package2VersionCreateInProgress = "InProgress"
+	Package2VersionCreateSuccess    = "Success"
+	Package2VersionCreateError      = "Error"
+)
+
+const package2VersionFields = `Id, Package2Id, SubscriberPackageVersionId, Name, Description, MajorVersion,
+	MinorVersion, PatchVersion, BuildNumber, IsReleased, Tag, Branch, ValidationSkipped,
+	HasPassedCodeCoverageCheck, CodeCoverage, CreatedDate`
+
+// VersionNumber returns the version in major.minor.patch.build format
+func (v Package2Version) VersionNumber() string {
+	return fmt.Sprintf("%d.%d.%d.%d", v.MajorVersion, v.MinorVersion, v.PatchVersion, v.BuildNumber)
+}
+
+// GetPackage2 finds a package by name or 0Ho id
+func (f *Force) GetPackage2(nameOrId string) (Package2, error) {
+	var packages []Package2
+	criteria := "Name = " + soqlQuote(nameOrId)
+	if strings.HasPrefix(nameOrId, "0Ho") {
+		criteria = "Id = " + soqlQuote(nameOrId)
+	}
+	err := f.QueryInto("SELECT Id, Name, NamespacePrefix, ContainerOptions, Description FROM Package2 WHERE "+criteria, &packages, isTooling)
+	if err != nil {
+		return Package2{}, fmt.Errorf("Could not query packages: %w", err)
+	}
+	if len(packages) == 0 {
+		return Package2{}, fmt.Errorf("Package %s not found", nameOrId)
+	}
+	return packages[0], nil
+}
+
+// MakePackage2VersionInfo builds the zip file used as the VersionInfo of a
+// Package2VersionCreateRequest.  It contains the package descriptor and the
+// package's metadata, zipped.
+func MakePackage2VersionInfo(files ForceMetadataFiles, options Package2VersionCreateOptions) ([]byte, error) {
+	packageZip, err := zipFiles(files)
+	if err != nil {
+		return nil, err
+	}
+	descriptor := package2Descriptor{
+		Id:                 options.Package2Id,
+		VersionName:        options.VersionName,
+		VersionNumber:      options.VersionNumber,
+		VersionDescription: options.VersionDescription,
+		AncestorId:         options.AncestorId,
+	}
+	for _, d := range options.Dependencies {
+		descriptor.Dependencies = append(descriptor.Dependencies, package2DescriptorVersion{SubscriberPackageVersionId: d})
+	}
+	descriptorJson, err := json.Marshal(descriptor)
+	if err != nil {
+		return nil, err
+	}
+	return zipFiles(map[string][]byte{
+		"package2-descriptor.json": descriptorJson,
+		"package.zip":              packageZip,
+	})
+}
+
+func zipFiles(files map[string][]byte) ([]byte, error) {
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	buf := new(bytes.Buffer)
+	zipper := zip.NewWriter(buf)
+	for _, name := range names {
+		w, err := zipper.Create(filepath.ToSlash(name))
+		if err != nil {
+			return nil, err
+		}
+		if _, err = w.Write(files[name]); err != nil {
+			return nil, err
+		}
+	}
+	if err := zipper.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// CreatePackage2Version starts the creation of a new package version from
+// files and returns the id of the Package2VersionCreateRequest.
+func (f *Force) CreatePackage2Version(files ForceMetadataFiles, options Package2VersionCreateOptions) (string, error) {
+	versionInfo, err := MakePackage2VersionInfo(files, options)
+	if err != nil {
+		return "", fmt.Errorf("Could not create version info: %w", err)
+	}
+	request := map[string]interface{}{
+		"Package2Id":            options.Package2Id,
+		"VersionInfo":           base64.StdEncoding.EncodeToString(versionInfo),
+		"CalculateCodeCoverage": options.CalculateCodeCoverage,
+		"SkipValidation":        options.SkipValidation,
+	}
+	if options.Tag != "" {
+		request["Tag"] = options.Tag
+	}
+	if options.Branch != "" {
+		request["Branch"] = options.Branch
+	}
+	if options.InstallationKey != "" {
+		request["InstallKey"] = options.InstallationKey
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return "", err
+	}
+	response, err := f.PostREST("tooling/sobjects/Package2VersionCreateRequest", string(body))
+	if err != nil {
+		return "", fmt.Errorf("Could not create package version: %w", err)
+	}
+	var result ForceCreateRecordResult
+	if err = json.Unmarshal([]byte(response), &result); err != nil {
+		return "", err
+	}
+	return result.Id, nil
+}
+
+// GetPackage2VersionCreateRequest returns the status of a package version
+// create request
+func (f *Force) GetPackage2VersionCreateRequest(id string) (Package2VersionCreateRequest, error) {
+	var requests []Package2VersionCreateRequest
+	err := f.QueryInto(fmt.Sprintf("SELECT Id, Package2Id, Package2VersionId, Status, Tag, Branch, CreatedDate FROM Package2VersionCreateRequest WHERE Id = %s", soqlQuote(id)), &requests, isTooling)
+	if err != nil {
+		return Package2VersionCreateRequest{}, fmt.Errorf("Could not query package version create request: %w", err)
+	}
+	if len(requests) == 0 {
+		return Package2VersionCreateRequest{}, fmt.Errorf("Package version create request %s not found", id)
+	}
+	return requests[0], nil
+}
+
+// Package2VersionCreateRequestErrors returns the error messages of a failed
+// package version create request
+func (f *Force) Package2VersionCreateRequestErrors(id string) ([]string, error) {
+	result, err := f.Query(fmt.Sprintf("SELECT Message FROM Package2VersionCreateRequestError WHERE ParentRequestId = %s", soqlQuote(id)), isTooling)
+	if err != nil {
+		return nil, err
+	}
+	var messages []string
+	for _, r := range result.Records {
+		if m, ok := r["Message"].(string); ok {
+			messages = append(messages, m)
+		}
+	}
+	return messages, nil
+}
+
+// WaitForPackage2Version polls a package version create request until it
+// completes or ctx is done, calling progress with each status, and returns the
+// new version.
+func (f *Force) WaitForPackage2Version(ctx context.Context, requestId string, interval time.Duration, progress func(Package2VersionCreateRequest)) (Package2Version, error) {
+	for {
+		request, err := f.GetPackage2VersionCreateRequest(requestId)
+		if err != nil {
+			return Package2Version{}, err
+		}
+		if progress != nil {
+			progress(request)
+		}
+		switch request.Status {
+		case Package2VersionCreateSuccess:
+			return f.GetPackage2Version(request.Package2VersionId)
+		case Package2VersionCreateError:
+			messages, err := f.Package2VersionCreateRequestErrors(requestId)
+			if err != nil {
+				return Package2Version{}, fmt.Errorf("Package version creation failed")
+			}
+			return Package2Version{}, fmt.Errorf("Package version creation failed:\n%s", strings.Join(messages, "\n"))
+		}
+		select {
+		case <-ctx.Done():
+			return Package2Version{}, fmt.Errorf("Stopped waiting for package version create request %s: %w", requestId, ctx.Err())
+		case <-time.After(interval):
+		}
+	}
+}
+
+// GetPackage2Version returns a package version by its 05i id or its
+// subscriber package version (04t) id
+func (f *Force) GetPackage2Version(id string) (Package2Version, error) {
+	field := "Id"
+	if strings.HasPrefix(id, "04t") {
+		field = "SubscriberPackageVersionId"
+	}
+	var versions []Package2Version
+	err := f.QueryInto(fmt.Sprintf("SELECT %s FROM Package2Version WHERE %s = %s", package2VersionFields, field, soqlQuote(id)), &versions, isTooling)
+	if err != nil {
+		return Package2Version{}, fmt.Errorf("Could not query package version: %w", err)
+	}
+	if len(versions) == 0 {
+		return Package2Version{}, fmt.Errorf("Package version %s not found", id)
+	}
+	return versions[0], nil
+}
+
+// QueryPackage2Versions returns the versions of a package, newest first
+func (f *Force) QueryPackage2Versions(package2Id string, releasedOnly bool) ([]Package2Version, error) {
+	soql := fmt.Sprintf("SELECT %s FROM Package2Version WHERE Package2Id = %s", package2VersionFields, soqlQuote(package2Id))
+	if releasedOnly {
+		soql += " AND IsReleased = true"
+	}
+	soql += " ORDER BY MajorVersion DESC, MinorVersion DESC, PatchVersion DESC, BuildNumber DESC"
+	var versions []Package2Version
+	if err := f.QueryInto(soql, &versions, isTooling); err != nil {
+		return nil, fmt.Errorf("Could not query package versions: %w", err)
+	}
+	return versions, nil
+}
+
+// PromotePackage2Version marks a package version as released so it can be
+// installed in production orgs
+func (f *Force) PromotePackage2Version(id string) error {
+	version, err := f.GetPackage2Version(id)
+	if err != nil {
+		return err
+	}
+	_, err = f.PatchREST("tooling/sobjects/Package2Version/"+version.Id, `{"IsReleased": true}`)
+	if err != nil {
+		return fmt.Errorf("Could not promote package version: %w", err)
+	}
+	return nil
+}
+
+// SubscriberPackageVersionDependencies returns the 04t ids of the package
+// versions that a package version depends on
+func (f *Force) SubscriberPackageVersionDependencies(subscriberPackageVersionId string) ([]string, error) {
+	var versions []struct {
+		Dependencies *struct {
+			Ids []package2DescriptorVersion `json:"ids"`
+		}
+	}
+	err := f.QueryInto(fmt.Sprintf("SELECT Dependencies FROM SubscriberPackageVersion WHERE Id = %s", soqlQuote(subscriberPackageVersionId)), &versions, isTooling)
+	if err != nil {
+		return nil, fmt.Errorf("Could not query package dependencies: %w", err)
+	}
+	var ids []string
+	for _, v := range versions {
+		if v.Dependencies == nil {
+			continue
+		}
+		for _, d := range v.Dependencies.Ids {
+			ids = append(ids, d.SubscriberPackageVersionId)
+		}
+	}
+	return ids, nil
+}
@@ -0,0 +1,59 @@
+package lib_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+
+	. "github.com/ForceCLI/force/lib"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func readZip(data []byte) map[string][]byte {
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	Expect(err).ToNot(HaveOccurred())
+	files := make(map[string][]byte)
+	for _, f := range r.File {
+		rc, err := f.Open()
+		Expect(err).ToNot(HaveOccurred())
+		files[f.Name], err = ioutil.ReadAll(rc)
+		Expect(err).ToNot(HaveOccurred())
+		rc.Close()
+	}
+	return files
+}
+
+var _ = Describe("Package2", func() {
+	Describe("MakePackage2VersionInfo", func() {
+		It("should include the descriptor and zipped metadata", func() {
+			files := ForceMetadataFiles{
+				"package.xml":              []byte("<Package/>"),
+				"classes/Foo.cls":          []byte("public class Foo {}"),
+				"classes/Foo.cls-meta.xml": []byte("<ApexClass/>"),
+			}
+			versionInfo, err := MakePackage2VersionInfo(files, Package2VersionCreateOptions{
+				Package2Id:    "0Ho000000000001",
+				VersionNumber: "1.2.0.NEXT",
+				Dependencies:  []string{"04t000000000001"},
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			contents := readZip(versionInfo)
+			Expect(contents).To(HaveKey("package2-descriptor.json"))
+			Expect(contents).To(HaveKey("package.zip"))
+
+			var descriptor map[string]interface{}
+			Expect(json.Unmarshal(contents["package2-descriptor.json"], &descriptor)).To(Succeed())
+			Expect(descriptor["id"]).To(Equal("0Ho000000000001"))
+			Expect(descriptor["versionNumber"]).To(Equal("1.2.0.NEXT"))
+			Expect(descriptor["dependencies"]).To(HaveLen(1))
+
+			metadata := readZip(contents["package.zip"])
+			Expect(metadata).To(HaveKeyWithValue("classes/Foo.cls", []byte("public class Foo {}")))
+			Expect(metadata).To(HaveKey("package.xml"))
+		})
+	})
+})
@@ -0,0 +1,225 @@
+package lib
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// PackageInstallOptions are the options used when installing a package
+// version with a PackageInstallRequest
+type PackageInstallOptions struct {
+	Password string
+	// SecurityType is Full to grant access to all users or None for admins
+	// only
+	SecurityType string
+	// UpgradeType is mixed-mode, deprecate-only, or delete-only
+	UpgradeType     string
+	ApexCompileType string
+	EnableRss       bool
+}
+
+// PackageInstallRequest tracks the asynchronous installation of a package
+// version
+type PackageInstallRequest struct {
+	Id                          string
+	SubscriberPackageVersionKey string
+	Status                      string
+	Errors                      *struct {
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+}
+
+const (
+	PackageInstallInProgress = "IN_PROGRESS"
+	PackageInstallSuccess    = "SUCCESS"
+	PackageInstallError      = "ERROR"
+	PackageInstallUnknown    = "UNKNOWN"
+)
+
+// ErrorMessages returns the errors reported for a failed install
+func (r PackageInstallRequest) ErrorMessages() []string {
+	var messages []string
+	if r.Errors != nil {
+		for _, e := range r.Errors.Errors {
+			messages = append(messages, e.Message)
+		}
+	}
+	return messages
+}
+
+// StartPackageInstall creates a PackageInstallRequest for a subscriber
+// package version (04t) id and returns the request's id
+func (f *Force) StartPackageInstall(subscriberPackageVersionId string, options PackageInstallOptions) (string, error) {
+	request := map[string]interface{}{
+		"SubscriberPackageVersionKey": subscriberPackageVersionId,
+		"NameConflictResolution":      "Block",
+		"EnableRss":                   options.EnableRss,
+	}
+	if options.Password != "" {
+		request["Password"] = options.Password
+	}
+	if options.SecurityType != "" {
+		request["SecurityType"] = options.SecurityType
+	}
+	if options.UpgradeType != "" {
+		request["UpgradeType"] = options.UpgradeType
+	}
+	if options.ApexCompileType != "" {
+		request["ApexCompileType"] = options.ApexCompileType
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return "", err
+	}
+	response, err := f.PostREST("tooling/sobjects/PackageInstallRequest", string(body))
+	if err != nil {
+		return "", fmt.Errorf("Could not install package: %w", err)
+	}
+	var result ForceCreateRecordResult
+	if err = json.Unmarshal([]byte(response), &result); err != nil {
+		return "", err
+	}
+	return result.Id, nil
+}
+
+// GetPackageInstallRequest returns the status of a package install request
+func (f *Force) GetPackageInstallRequest(id string) (PackageInstallRequest, error) {
+	var requests []PackageInstallRequest
+	err := f.QueryInto(fmt.Sprintf("SELECT Id, SubscriberPackageVersionKey, Status, Errors FROM PackageInstallRequest WHERE Id = %s", soqlQuote(id)), &requests, isTooling)
+	if err != nil {
+		return PackageInstallRequest{}, fmt.Errorf("Could not query package install request: %w", err)
+	}
+	if len(requests) == 0 {
+		return PackageInstallRequest{}, fmt.Errorf("Package install request %s not found", id)
+	}
+	return requests[0], nil
+}
+
+// WaitForPackageInstall polls a package install request until it completes or
+// ctx is done, calling progress with each status.
+func (f *Force) WaitForPackageInstall(ctx context.Context, requestId string, interval time.Duration, progress func(PackageInstallRequest)) error {
+	for {
+		request, err := f.GetPackageInstallRequest(requestId)
+		if err != nil {
+			return err
+		}
+		if progress != nil {
+			progress(request)
+		}
+		switch request.Status {
+		case PackageInstallSuccess:
+			return nil
+		case PackageInstallError, PackageInstallUnknown:
+			return fmt.Errorf("Package installation failed:\n%s", strings.Join(request.ErrorMessages(), "\n"))
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("Stopped waiting for package install request %s: %w", requestId, ctx.Err())
+		case <-time.After(interval):
+		}
+	}
+}
+
+// InstalledPackage is a package installed in an org
+type InstalledPackage struct {
+	Id                  string
+	SubscriberPackageId string
+	SubscriberPackage   struct {
+		Name            string
+		NamespacePrefix string
+	}
+	SubscriberPackageVersion struct {
+		Id           string
+		Name         string
+		MajorVersion int
+		MinorVersion int
+		PatchVersion int
+		BuildNumber  int
+	}
+}
+
+// VersionNumber returns the installed version in major.minor.patch.build
+// format
+func (p InstalledPackage) VersionNumber() string {
+	v := p.SubscriberPackageVersion
+	return fmt.Sprintf("%d.%d.%d.%d", v.MajorVersion, v.MinorVersion, v.PatchVersion, v.BuildNumber)
+}
+
+// QueryInstalledPackages returns the packages installed in the org
+func (f *Force) QueryInstalledPackages() ([]InstalledPackage, error) {
+	soql := `SELECT Id, SubscriberPackageId, SubscriberPackage.Name, SubscriberPackage.NamespacePrefix,
+	SubscriberPackageVersion.Id, SubscriberPackageVersion.Name, SubscriberPackageVersion.MajorVersion,
+	SubscriberPackageVersion.MinorVersion, SubscriberPackageVersion.PatchVersion,
+	SubscriberPackageVersion.BuildNumber
+	FROM InstalledSubscriberPackage
+	ORDER BY SubscriberPackage.NamespacePrefix, SubscriberPackage.Name`
+	var packages []InstalledPackage
+	if err := f.QueryInto(soql, &packages, isTooling); err != nil {
+		return nil, fmt.Errorf("Could not query installed packages: %w", err)
+	}
+	return packages, nil
+}
+
+const (
+	PackageUninstallQueued     = "Queued"
+	PackageUninstallInProgress = "InProgress"
+	PackageUninstallSuccess    = "Success"
+	PackageUninstallError      = "Error"
+)
+
+// StartPackageUninstall creates a SubscriberPackageVersionUninstallRequest for
+// an installed package version (04t) and returns the request's id
+func (f *Force) StartPackageUninstall(subscriberPackageVersionId string) (string, error) {
+	body, err := json.Marshal(map[string]string{
+		"SubscriberPackageVersionId": subscriberPackageVersionId,
+	})
+	if err != nil {
+		return "", err
+	}
+	response, err := f.PostREST("tooling/sobjects/SubscriberPackageVersionUninstallRequest", string(body))
+	if err != nil {
+		return "", fmt.Errorf("Could not uninstall package: %w", err)
+	}
+	var result ForceCreateRecordResult
+	if err = json.Unmarshal([]byte(response), &result); err != nil {
+		return "", err
+	}
+	return result.Id, nil
+}
+
+// WaitForPackageUninstall polls a package uninstall request until it
+// completes or ctx is done, calling progress with each status.
+func (f *Force) WaitForPackageUninstall(ctx context.Context, requestId string, interval time.Duration, progress func(status string)) error {
+	for {
+		var requests []struct {
+			Status string
+		}
+		err := f.QueryInto(fmt.Sprintf("SELECT Id, Status FROM SubscriberPackageVersionUninstallRequest WHERE Id = %s", soqlQuote(requestId)), &requests, isTooling)
+		if err != nil {
+			return fmt.Errorf("Could not query package uninstall request: %w", err)
+		}
+		if len(requests) == 0 {
+			return fmt.Errorf("Package uninstall request %s not found", requestId)
+		}
+		status := requests[0].Status
+		if progress != nil {
+			progress(status)
+		}
+		switch status {
+		case PackageUninstallSuccess:
+			return nil
+		case PackageUninstallError:
+			return fmt.Errorf("Package uninstall failed")
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("Stopped waiting for package uninstall request %s: %w", requestId, ctx.Err())
+		case <-time.After(interval):
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package lib_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	. "github.com/ForceCLI/force/lib"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/ghttp"
+)
+
+// verifyToolingQuery verifies a Tooling API query against the given object
+func verifyToolingQuery(object string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		Expect(r.Method).To(Equal("GET"))
+		Expect(strings.TrimSuffix(r.URL.Path, "/")).To(HaveSuffix("/tooling/query"))
+		Expect(r.URL.Query().Get("q")).To(ContainSubstring("FROM " + object))
+	}
+}
+
+func queryResponse(records string) string {
+	return `{"totalSize":1,"done":true,"records":[` + records + `]}`
+}
+
+var _ = Describe("PackageInstall", func() {
+	var sfServer *Server
+	var f *Force
+
+	BeforeEach(func() {
+		sfServer = NewServer()
+		f = NewForce(&ForceSession{InstanceUrl: sfServer.URL()})
+	})
+	AfterEach(func() {
+		sfServer.Close()
+	})
+
+	Describe("QueryInstalledPackages", func() {
+		It("should return the installed packages and their versions", func() {
+			sfServer.AppendHandlers(
+				CombineHandlers(
+					verifyToolingQuery("InstalledSubscriberPackage"),
+					RespondWith(200, queryResponse(`{
+						"Id": "0A3000000000001",
+						"SubscriberPackageId": "033000000000001",
+						"SubscriberPackage": {"Name": "Billing", "NamespacePrefix": "bill"},
+						"SubscriberPackageVersion": {"Id": "04t000000000001", "Name": "Spring", "MajorVersion": 1, "MinorVersion": 2, "PatchVersion": 0, "BuildNumber": 3}
+					}`), JsonHeaders),
+				),
+			)
+			packages, err := f.QueryInstalledPackages()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(packages).To(HaveLen(1))
+			Expect(packages[0].SubscriberPackage.NamespacePrefix).To(Equal("bill"))
+			Expect(packages[0].SubscriberPackageVersion.Id).To(Equal("04t000000000001"))
+			Expect(packages[0].VersionNumber()).To(Equal("1.2.0.3"))
+		})
+	})
+
+	Describe("StartPackageUninstall", func() {
+		It("should create an uninstall request for the package version", func() {
+			sfServer.AppendHandlers(
+				CombineHandlers(
+					VerifyRequest("POST", "/services/data/"+ApiVersion()+"/tooling/sobjects/SubscriberPackageVersionUninstallRequest"),
+					func(w http.ResponseWriter, r *http.Request) {
+						var request map[string]string
+						Expect(json.Unmarshal(mustRead(r.Body), &request)).To(Succeed())
+						Expect(request).To(Equal(map[string]string{"SubscriberPackageVersionId": "04t000000000001"}))
+					},
+					RespondWith(201, `{"id":"06y000000000001","success":true,"errors":[]}`, JsonHeaders),
+				),
+			)
+			id, err := f.StartPackageUninstall("04t000000000001")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(id).To(Equal("06y000000000001"))
+		})
+	})
+
+	Describe("WaitForPackageUninstall", func() {
+		uninstallStatus := func(status string) http.HandlerFunc {
+			return CombineHandlers(
+				verifyToolingQuery("SubscriberPackageVersionUninstallRequest"),
+				RespondWith(200, queryResponse(`{"Id":"06y000000000001","Status":"`+status+`"}`), JsonHeaders),
+			)
+		}
+
+		It("should poll until the uninstall succeeds", func() {
+			sfServer.AppendHandlers(
+				uninstallStatus(PackageUninstallQueued),
+				uninstallStatus(PackageUninstallInProgress),
+				uninstallStatus(PackageUninstallSuccess),
+			)
+			var statuses []string
+			err := f.WaitForPackageUninstall(context.Background(), "06y000000000001", 0, func(status string) {
+				statuses = append(statuses, status)
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(statuses).To(Equal([]string{PackageUninstallQueued, PackageUninstallInProgress, PackageUninstallSuccess}))
+		})
+
+		It("should return an error if the uninstall fails", func() {
+			sfServer.AppendHandlers(uninstallStatus(PackageUninstallError))
+			Expect(f.WaitForPackageUninstall(context.Background(), "06y000000000001", 0, nil)).To(MatchError("Package uninstall failed"))
+		})
+
+		It("should stop waiting when the context is done", func() {
+			sfServer.AppendHandlers(uninstallStatus(PackageUninstallInProgress))
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			err := f.WaitForPackageUninstall(ctx, "06y000000000001", time.Minute, nil)
+			Expect(err).To(MatchError(context.Canceled))
+			Expect(sfServer.ReceivedRequests()).To(HaveLen(1))
+		})
+	})
+})
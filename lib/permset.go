@@ -0,0 +1,245 @@
+package lib
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	PermissionSetTypePermissionSet = "PermissionSet"
+	PermissionSetTypeGroup         = "PermissionSetGroup"
+)
+
+// PermissionSet is a permission set or a permission set group
+type PermissionSet struct {
+	Id          string
+	Name        string
+	Label       string
+	Description string
+	// Type is PermissionSet or PermissionSetGroup
+	Type    string
+	License string
+}
+
+// PermissionSetAssignment is the assignment of a permission set or
+// permission set group to a user
+type PermissionSetAssignment struct {
+	Id         string
+	AssigneeId string
+	Username   string
+	Name       string
+}
+
+// PermissionSetAssignmentResult is the outcome of assigning or unassigning
+// a permission set for a user
+type PermissionSetAssignmentResult struct {
+	Username string
+	// Status is assigned, unassigned, unchanged, or failed
+	Status string
+	Error  error
+}
+
+// QueryPermissionSets returns the permission sets not owned by profiles and
+// the permission set groups in the org
+func (f *Force) QueryPermissionSets() ([]PermissionSet, error) {
+	return f.queryPermissionSets("", "")
+}
+
+// GetPermissionSet returns the permission set or permission set group with
+// the given API name
+func (f *Force) GetPermissionSet(name string) (PermissionSet, error) {
+	permissionSets, err := f.queryPermissionSets("Name = "+soqlQuote(name), "DeveloperName = "+soqlQuote(name))
+	if err != nil {
+		return PermissionSet{}, err
+	}
+	if len(permissionSets) == 0 {
+		return PermissionSet{}, fmt.Errorf("No permission set or permission set group named %s", name)
+	}
+	return permissionSets[0], nil
+}
+
+// queryPermissionSets returns the permission sets and permission set groups
+// matching the given conditions, or all of them if the conditions are empty
+func (f *Force) queryPermissionSets(permissionSetCondition string, groupCondition string) ([]PermissionSet, error) {
+	var permissionSets []struct {
+		Id          string
+		Name        string
+		Label       string
+		Description string
+		License     *struct {
+			Name string
+		}
+	}
+	soql := `SELECT Id, Name, Label, Description, License.Name
+	FROM PermissionSet
+	WHERE IsOwnedByProfile = false AND PermissionSetGroupId = null`
+	if permissionSetCondition != "" {
+		soql += " AND " + permissionSetCondition
+	}
+	soql += " ORDER BY Name"
+	if err := f.QueryInto(soql, &permissionSets); err != nil {
+		return nil, fmt.Errorf("Could not query permission sets: %w", err)
+	}
+	var groups []struct {
+		Id            string
+		DeveloperName string
+		MasterLabel   string
+		Description   string
+	}
+	soql = `SELECT Id, DeveloperName, MasterLabel, Description
+	FROM PermissionSetGroup`
+	if groupCondition != "" {
+		soql += " WHERE " + groupCondition
+	}
+	soql += " ORDER BY DeveloperName"
+	if err := f.QueryInto(soql, &groups); err != nil {
+		return nil, fmt.Errorf("Could not query permission set groups: %w", err)
+	}
+
+	var results []PermissionSet
+	for _, p := range permissionSets {
+		ps := PermissionSet{Id: p.Id, Name: p.Name, Label: p.Label, Description: p.Description, Type: PermissionSetTypePermissionSet}
+		if p.License != nil {
+			ps.License = p.License.Name
+		}
+		results = append(results, ps)
+	}
+	for _, g := range groups {
+		results = append(results, PermissionSet{Id: g.Id, Name: g.DeveloperName, Label: g.MasterLabel, Description: g.Description, Type: PermissionSetTypeGroup})
+	}
+	return results, nil
+}
+
+func (ps PermissionSet) assignmentField() string {
+	if ps.Type == PermissionSetTypeGroup {
+		return "PermissionSetGroupId"
+	}
+	return "PermissionSetId"
+}
+
+// QueryPermissionSetAssignments returns the users assigned a permission set
+// or permission set group
+func (f *Force) QueryPermissionSetAssignments(ps PermissionSet) ([]PermissionSetAssignment, error) {
+	var assignments []struct {
+		Id         string
+		AssigneeId string
+		Assignee   struct {
+			Username string
+			Name     string
+		}
+	}
+	soql := fmt.Sprintf(`SELECT Id, AssigneeId, Assignee.Username, Assignee.Name
+	FROM PermissionSetAssignment
+	WHERE %s = %s
+	ORDER BY Assignee.Username`, ps.assignmentField(), soqlQuote(ps.Id))
+	if err := f.QueryInto(soql, &assignments); err != nil {
+		return nil, fmt.Errorf("Could not query assignments of %s: %w", ps.Name, err)
+	}
+	var results []PermissionSetAssignment
+	for _, a := range assignments {
+		results = append(results, PermissionSetAssignment{Id: a.Id, AssigneeId: a.AssigneeId, Username: a.Assignee.Username, Name: a.Assignee.Name})
+	}
+	return results, nil
+}
+
+// QueryUserIds returns the ids of users, keyed by username.  Users can be
+// selected by username, by a SOQL condition, or both.
+func (f *Force) QueryUserIds(usernames []string, condition string) (map[string]string, error) {
+	var conditions []string
+	if len(usernames) > 0 {
+		var quoted []string
+		for _, u := range usernames {
+			quoted = append(quoted, soqlQuote(u))
+		}
+		conditions = append(conditions, fmt.Sprintf("Username IN (%s)", strings.Join(quoted, ", ")))
+	}
+	if condition != "" {
+		conditions = append(conditions, "("+condition+")")
+	}
+	if len(conditions) == 0 {
+		return map[string]string{}, nil
+	}
+	var users []struct {
+		Id       string
+		Username string
+	}
+	soql := fmt.Sprintf("SELECT Id, Username FROM User WHERE %s", strings.Join(conditions, " OR "))
+	if err := f.QueryInto(soql, &users); err != nil {
+		return nil, fmt.Errorf("Could not query users: %w", err)
+	}
+	ids := make(map[string]string)
+	found := make(map[string]bool)
+	for _, u := range users {
+		ids[u.Username] = u.Id
+		found[strings.ToLower(u.Username)] = true
+	}
+	for _, u := range usernames {
+		if !found[strings.ToLower(u)] {
+			return nil, fmt.Errorf("No user with username %s", u)
+		}
+	}
+	return ids, nil
+}
+
+// AssignPermissionSet assigns a permission set or permission set group to
+// users, keyed by username.  Users who are already assigned are left
+// unchanged.
+func (f *Force) AssignPermissionSet(ps PermissionSet, userIds map[string]string) ([]PermissionSetAssignmentResult, error) {
+	existing, err := f.QueryPermissionSetAssignments(ps)
+	if err != nil {
+		return nil, err
+	}
+	assigned := make(map[string]bool)
+	for _, a := range existing {
+		assigned[a.AssigneeId] = true
+	}
+	var results []PermissionSetAssignmentResult
+	for _, username := range sortedStringKeys(userIds) {
+		userId := userIds[username]
+		if assigned[userId] {
+			results = append(results, PermissionSetAssignmentResult{Username: username, Status: "unchanged"})
+			continue
+		}
+		_, err, messages := f.CreateRecord("PermissionSetAssignment", map[string]string{
+			"AssigneeId":         userId,
+			ps.assignmentField(): ps.Id,
+		})
+		if err != nil {
+			if len(messages) > 0 {
+				err = ForceErrors(messages)
+			}
+			results = append(results, PermissionSetAssignmentResult{Username: username, Status: "failed", Error: err})
+			continue
+		}
+		results = append(results, PermissionSetAssignmentResult{Username: username, Status: "assigned"})
+	}
+	return results, nil
+}
+
+// UnassignPermissionSet removes the assignment of a permission set or
+// permission set group from users, keyed by username.  Users who aren't
+// assigned are left unchanged.
+func (f *Force) UnassignPermissionSet(ps PermissionSet, userIds map[string]string) ([]PermissionSetAssignmentResult, error) {
+	existing, err := f.QueryPermissionSetAssignments(ps)
+	if err != nil {
+		return nil, err
+	}
+	assignments := make(map[string]string)
+	for _, a := range existing {
+		assignments[a.AssigneeId] = a.Id
+	}
+	var results []PermissionSetAssignmentResult
+	for _, username := range sortedStringKeys(userIds) {
+		assignmentId, ok := assignments[userIds[username]]
+		if !ok {
+			results = append(results, PermissionSetAssignmentResult{Username: username, Status: "unchanged"})
+			continue
+		}
+		if err := f.DeleteRecord("PermissionSetAssignment", assignmentId); err != nil {
+			results = append(results, PermissionSetAssignmentResult{Username: username, Status: "failed", Error: err})
+			continue
+		}
+		results = append(results, PermissionSetAssignmentResult{Username: username, Status: "unassigned"})
+	}
+	return results, nil
+}
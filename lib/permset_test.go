@@ -0,0 +1,66 @@
+package lib_test
+
+import (
+	"net/http"
+
+	. "github.com/ForceCLI/force/lib"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("PermissionSet", func() {
+	var sfServer *Server
+	var f *Force
+
+	BeforeEach(func() {
+		sfServer = NewServer()
+		f = NewForce(&ForceSession{InstanceUrl: sfServer.URL()})
+	})
+
+	AfterEach(func() {
+		sfServer.Close()
+	})
+
+	Describe("GetPermissionSet", func() {
+		verifyCondition := func(condition string) http.HandlerFunc {
+			return func(w http.ResponseWriter, r *http.Request) {
+				Expect(r.URL.Query().Get("q")).To(ContainSubstring(condition))
+			}
+		}
+
+		It("should query permission sets and groups by name", func() {
+			sfServer.AppendHandlers(
+				CombineHandlers(
+					verifyQuery("PermissionSet"),
+					verifyCondition(`AND Name = 'Sales\'s_Ops'`),
+					RespondWith(200, `{"totalSize":0,"done":true,"records":[]}`, JsonHeaders),
+				),
+				CombineHandlers(
+					verifyQuery("PermissionSetGroup"),
+					verifyCondition(`WHERE DeveloperName = 'Sales\'s_Ops'`),
+					RespondWith(200, queryResponse(`{"Id":"0PG000000000001","DeveloperName":"Sales_Ops","MasterLabel":"Sales Ops"}`), JsonHeaders),
+				),
+			)
+			ps, err := f.GetPermissionSet("Sales's_Ops")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ps).To(Equal(PermissionSet{Id: "0PG000000000001", Name: "Sales_Ops", Label: "Sales Ops", Type: PermissionSetTypeGroup}))
+		})
+
+		It("should return an error if there is no match", func() {
+			sfServer.AppendHandlers(
+				CombineHandlers(
+					verifyQuery("PermissionSet"),
+					RespondWith(200, `{"totalSize":0,"done":true,"records":[]}`, JsonHeaders),
+				),
+				CombineHandlers(
+					verifyQuery("PermissionSetGroup"),
+					RespondWith(200, `{"totalSize":0,"done":true,"records":[]}`, JsonHeaders),
+				),
+			)
+			_, err := f.GetPermissionSet("Missing")
+			Expect(err).To(MatchError("No permission set or permission set group named Missing"))
+		})
+	})
+})
@@ -0,0 +1,150 @@
+package lib
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/ForceCLI/force/desktop"
+)
+
+// PkceChallenge returns the S256 code challenge for a PKCE code verifier
+func PkceChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func randomUrlSafeString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// AuthorizationCodeURL returns the URL of the authorization page for the
+// OAuth web server flow with PKCE
+func AuthorizationCodeURL(endpoint string, clientId string, redirectUri string, challenge string, state string, prompt string) string {
+	attrs := url.Values{}
+	attrs.Set("response_type", "code")
+	attrs.Set("client_id", clientId)
+	attrs.Set("redirect_uri", redirectUri)
+	attrs.Set("code_challenge", challenge)
+	attrs.Set("code_challenge_method", "S256")
+	attrs.Set("state", state)
+	if prompt != "" {
+		attrs.Set("prompt", prompt)
+	}
+	return fmt.Sprintf("%s/services/oauth2/authorize?%s", endpoint, attrs.Encode())
+}
+
+// ExchangeAuthorizationCode exchanges an authorization code and PKCE code
+// verifier for a session.  The client secret is optional.
+func ExchangeAuthorizationCode(endpoint string, clientId string, clientSecret string, redirectUri string, code string, verifier string) (creds ForceSession, err error) {
+	attrs := url.Values{}
+	attrs.Set("grant_type", "authorization_code")
+	attrs.Set("code", code)
+	attrs.Set("client_id", clientId)
+	attrs.Set("redirect_uri", redirectUri)
+	attrs.Set("code_verifier", verifier)
+	if clientSecret != "" {
+		attrs.Set("client_secret", clientSecret)
+	}
+	body, status, err := oauthTokenRequest(endpoint, attrs)
+	if err != nil {
+		return
+	}
+	if status != 200 {
+		err = fmt.Errorf("Could not get token: %w", oauthError(body, status))
+		return
+	}
+	creds, err = sessionFromTokenResponse(body, endpoint, clientId)
+	creds.ClientSecret = clientSecret
+	return
+}
+
+type authorizationCodeResult struct {
+	code string
+	err  error
+}
+
+// startAuthorizationCodeServer listens on CallbackPort for the redirect back
+// from the authorization page
+func startAuthorizationCodeServer(state string, ch chan authorizationCodeResult) (err error) {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", CallbackPort))
+	if err != nil {
+		return
+	}
+	h := http.NewServeMux()
+	h.HandleFunc("/oauth/callback", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("error") != "" {
+			oauthCallbackError(w, query.Get("error"), query.Get("error_description"))
+			ch <- authorizationCodeResult{err: fmt.Errorf("%s: %s", query.Get("error"), query.Get("error_description"))}
+		} else if query.Get("state") != state {
+			oauthCallbackError(w, "invalid_state", "The state parameter did not match")
+			ch <- authorizationCodeResult{err: fmt.Errorf("OAuth callback state did not match")}
+		} else {
+			io.WriteString(w, authorizationCodeCallbackHtml)
+			ch <- authorizationCodeResult{code: query.Get("code")}
+		}
+		listener.Close()
+	})
+	go http.Serve(listener, h)
+	return
+}
+
+const authorizationCodeCallbackHtml = `
+<!doctype html>
+<html>
+  <head>
+	  <title>Force CLI OAuth Callback</title>
+  </head>
+  <body>
+	  <h1>OAuth Callback</h1>
+	  <p id="status">Complete! You may now close this window</p>
+  </body>
+</html>`
+
+// ForceLoginAtEndpointWithPKCE logs in using the OAuth web server flow with
+// PKCE, opening the authorization page in a browser and receiving the
+// authorization code on CallbackPort
+func ForceLoginAtEndpointWithPKCE(endpoint string, clientSecret string, prompt string) (creds ForceSession, err error) {
+	verifier, err := randomUrlSafeString(64)
+	if err != nil {
+		return
+	}
+	state, err := randomUrlSafeString(16)
+	if err != nil {
+		return
+	}
+	ch := make(chan authorizationCodeResult, 1)
+	if err = startAuthorizationCodeServer(state, ch); err != nil {
+		return creds, fmt.Errorf("Could not listen for OAuth callback: %w", err)
+	}
+	redirect := redirectUri()
+	authUrl := AuthorizationCodeURL(endpoint, ClientId, redirect, PkceChallenge(verifier), state, prompt)
+	if err = desktop.Open(authUrl); err != nil {
+		fmt.Fprintf(os.Stderr, "Open %s in a browser to log in\n", authUrl)
+	}
+	result := <-ch
+	if result.err != nil {
+		return creds, fmt.Errorf("Login failed: %w", result.err)
+	}
+	return ExchangeAuthorizationCode(endpoint, ClientId, clientSecret, redirect, result.code, verifier)
+}
+
+func ForceLoginAtEndpointAndSavePKCE(endpoint string, clientSecret string, output *os.File) (username string, err error) {
+	creds, err := ForceLoginAtEndpointWithPKCE(endpoint, clientSecret, "login")
+	if err != nil {
+		return
+	}
+	username, err = ForceSaveLogin(creds, output)
+	return
+}
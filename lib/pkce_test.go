@@ -0,0 +1,53 @@
+package lib_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	. "github.com/ForceCLI/force/lib"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PKCE", func() {
+	It("should compute the S256 code challenge", func() {
+		// Example from RFC 7636, Appendix B
+		Expect(PkceChallenge("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk")).To(Equal("E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"))
+	})
+
+	It("should build the authorization URL", func() {
+		authUrl := AuthorizationCodeURL("https://login.salesforce.com", "client", "http://localhost:1717/oauth/callback", "challenge", "state", "login")
+		u, err := url.Parse(authUrl)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(u.Path).To(Equal("/services/oauth2/authorize"))
+		Expect(u.Query()).To(Equal(url.Values{
+			"response_type":         {"code"},
+			"client_id":             {"client"},
+			"redirect_uri":          {"http://localhost:1717/oauth/callback"},
+			"code_challenge":        {"challenge"},
+			"code_challenge_method": {"S256"},
+			"state":                 {"state"},
+			"prompt":                {"login"},
+		}))
+	})
+
+	It("should exchange the authorization code for a session", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.ParseForm()
+			Expect(r.Form.Get("grant_type")).To(Equal("authorization_code"))
+			Expect(r.Form.Get("code")).To(Equal("code"))
+			Expect(r.Form.Get("code_verifier")).To(Equal("verifier"))
+			Expect(r.Form.Get("client_secret")).To(Equal("secret"))
+			w.Write([]byte(`{"access_token":"token","refresh_token":"refresh","instance_url":"https://example.my.salesforce.com"}`))
+		}))
+		defer server.Close()
+		creds, err := ExchangeAuthorizationCode(server.URL, "client", "secret", "http://localhost:3835/oauth/callback", "code", "verifier")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(creds.AccessToken).To(Equal("token"))
+		Expect(creds.RefreshToken).To(Equal("refresh"))
+		Expect(creds.ClientId).To(Equal("client"))
+		Expect(creds.ClientSecret).To(Equal("secret"))
+	})
+})
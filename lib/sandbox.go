@@ -0,0 +1,287 @@
+package lib
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SandboxInfo is the definition of a sandbox in a production org
+type SandboxInfo struct {
+	Id           string `json:",omitempty"`
+	SandboxName  string `json:",omitempty"`
+	LicenseType  string `json:",omitempty"`
+	Description  string `json:",omitempty"`
+	TemplateId   string `json:",omitempty"`
+	ApexClassId  string `json:",omitempty"`
+	SourceId     string `json:",omitempty"`
+	HistoryDays  int    `json:",omitempty"`
+	CopyChatter  bool
+	AutoActivate bool
+}
+
+// SandboxProcess tracks the creation, refresh, or cloning of a sandbox
+type SandboxProcess struct {
+	Id                  string
+	SandboxInfoId       string
+	SandboxName         string
+	LicenseType         string
+	Status              string
+	CopyProgress        int
+	SandboxOrganization string
+	Description         string
+	StartDate           string
+	EndDate             string
+}
+
+// SandboxOptions are the settings used when creating, refreshing, or cloning a
+// sandbox.  Settings left empty or nil keep the sandbox's current value when
+// refreshing.
+type SandboxOptions struct {
+	// LicenseType is DEVELOPER, DEVELOPER_PRO, PARTIAL, or FULL
+	LicenseType  string
+	Description  string
+	TemplateId   string
+	ApexClassId  string
+	HistoryDays  int
+	CopyChatter  *bool
+	AutoActivate *bool
+}
+
+const (
+	SandboxStatusCompleted = "Completed"
+	SandboxStatusStopped   = "Stopped"
+	SandboxStatusDiscarded = "Discarded"
+	SandboxStatusDeleted   = "Deleted"
+)
+
+// Done returns true if the sandbox process is no longer running
+func (p SandboxProcess) Done() bool {
+	switch p.Status {
+	case SandboxStatusCompleted, SandboxStatusStopped, SandboxStatusDiscarded, SandboxStatusDeleted:
+		return true
+	}
+	return false
+}
+
+// SandboxLicenseType converts a license type such as "developer-pro" or
+// "Partial" to the value used by SandboxInfo
+func SandboxLicenseType(licenseType string) (string, error) {
+	normalized := strings.ToUpper(strings.NewReplacer("-", "_", " ", "_").Replace(licenseType))
+	switch normalized {
+	case "DEVELOPER", "DEVELOPER_PRO", "PARTIAL", "FULL":
+		return normalized, nil
+	}
+	return "", fmt.Errorf("Invalid sandbox license type: %s", licenseType)
+}
+
+// SandboxUsername returns the username of a production user's copy in a
+// sandbox
+func SandboxUsername(username string, sandboxName string) string {
+	return username + "." + strings.ToLower(sandboxName)
+}
+
+// apply returns the sandbox definition with the settings that are set in the
+// options replaced
+func (options SandboxOptions) apply(info SandboxInfo) SandboxInfo {
+	if options.LicenseType != "" {
+		info.LicenseType = options.LicenseType
+	}
+	if options.Description != "" {
+		info.Description = options.Description
+	}
+	if options.TemplateId != "" {
+		info.TemplateId = options.TemplateId
+	}
+	if options.ApexClassId != "" {
+		info.ApexClassId = options.ApexClassId
+	}
+	if options.HistoryDays != 0 {
+		info.HistoryDays = options.HistoryDays
+	}
+	if options.CopyChatter != nil {
+		info.CopyChatter = *options.CopyChatter
+	}
+	if options.AutoActivate != nil {
+		info.AutoActivate = *options.AutoActivate
+	}
+	return info
+}
+
+// CreateSandbox starts the creation of a new sandbox and returns the id of
+// its SandboxInfo.  New sandboxes are activated when the copy completes
+// unless AutoActivate is false.
+func (f *Force) CreateSandbox(name string, options SandboxOptions) (string, error) {
+	return f.createSandboxInfo(options.apply(SandboxInfo{SandboxName: name, AutoActivate: true}))
+}
+
+// CloneSandbox starts the creation of a new sandbox copied from an existing
+// sandbox and returns the id of its SandboxInfo
+func (f *Force) CloneSandbox(source string, name string, options SandboxOptions) (string, error) {
+	sourceInfo, err := f.GetSandboxInfo(source)
+	if err != nil {
+		return "", err
+	}
+	return f.createSandboxInfo(options.apply(SandboxInfo{
+		SandboxName:  name,
+		SourceId:     sourceInfo.Id,
+		LicenseType:  sourceInfo.LicenseType,
+		AutoActivate: true,
+	}))
+}
+
+func (f *Force) createSandboxInfo(info SandboxInfo) (string, error) {
+	body, err := json.Marshal(info)
+	if err != nil {
+		return "", err
+	}
+	response, err := f.PostREST("tooling/sobjects/SandboxInfo", string(body))
+	if err != nil {
+		return "", fmt.Errorf("Could not create sandbox: %w", err)
+	}
+	var result ForceCreateRecordResult
+	if err = json.Unmarshal([]byte(response), &result); err != nil {
+		return "", err
+	}
+	return result.Id, nil
+}
+
+// RefreshSandbox starts the refresh of an existing sandbox and returns the id
+// of its SandboxInfo.  Settings not set in the options are left unchanged.
+func (f *Force) RefreshSandbox(name string, options SandboxOptions) (string, error) {
+	existing, err := f.GetSandboxInfo(name)
+	if err != nil {
+		return "", err
+	}
+	info := options.apply(SandboxInfo{
+		LicenseType:  existing.LicenseType,
+		Description:  existing.Description,
+		TemplateId:   existing.TemplateId,
+		ApexClassId:  existing.ApexClassId,
+		HistoryDays:  existing.HistoryDays,
+		CopyChatter:  existing.CopyChatter,
+		AutoActivate: existing.AutoActivate,
+	})
+	body, err := json.Marshal(info)
+	if err != nil {
+		return "", err
+	}
+	_, err = f.PatchREST("tooling/sobjects/SandboxInfo/"+existing.Id, string(body))
+	if err != nil {
+		return "", fmt.Errorf("Could not refresh sandbox: %w", err)
+	}
+	return existing.Id, nil
+}
+
+// QuerySandboxInfos returns the sandboxes defined in the org
+func (f *Force) QuerySandboxInfos() ([]SandboxInfo, error) {
+	soql := `SELECT Id, SandboxName, LicenseType, Description, TemplateId, ApexClassId,
+	SourceId, HistoryDays, CopyChatter, AutoActivate
+	FROM SandboxInfo
+	ORDER BY SandboxName`
+	var sandboxes []SandboxInfo
+	if err := f.QueryInto(soql, &sandboxes, isTooling); err != nil {
+		return nil, fmt.Errorf("Could not query sandboxes: %w", err)
+	}
+	return sandboxes, nil
+}
+
+// GetSandboxInfo returns the definition of a sandbox by name
+func (f *Force) GetSandboxInfo(name string) (SandboxInfo, error) {
+	var sandboxes []SandboxInfo
+	soql := fmt.Sprintf(`SELECT Id, SandboxName, LicenseType, Description, TemplateId, ApexClassId,
+	SourceId, HistoryDays, CopyChatter, AutoActivate
+	FROM SandboxInfo
+	WHERE SandboxName = %s`, soqlQuote(name))
+	if err := f.QueryInto(soql, &sandboxes, isTooling); err != nil {
+		return SandboxInfo{}, fmt.Errorf("Could not query sandbox: %w", err)
+	}
+	if len(sandboxes) == 0 {
+		return SandboxInfo{}, fmt.Errorf("Sandbox %s not found", name)
+	}
+	return sandboxes[0], nil
+}
+
+// QuerySandboxProcesses returns the most recent process for each sandbox in
+// the org
+func (f *Force) QuerySandboxProcesses() ([]SandboxProcess, error) {
+	soql := `SELECT Id, SandboxInfoId, SandboxName, LicenseType, Status, CopyProgress,
+	SandboxOrganization, Description, StartDate, EndDate
+	FROM SandboxProcess
+	ORDER BY SandboxName, CreatedDate DESC`
+	var processes []SandboxProcess
+	if err := f.QueryInto(soql, &processes, isTooling); err != nil {
+		return nil, fmt.Errorf("Could not query sandbox processes: %w", err)
+	}
+	var latest []SandboxProcess
+	seen := make(map[string]bool)
+	for _, p := range processes {
+		if seen[p.SandboxInfoId] {
+			continue
+		}
+		seen[p.SandboxInfoId] = true
+		latest = append(latest, p)
+	}
+	return latest, nil
+}
+
+// GetSandboxProcess returns the most recent process for the sandbox with the
+// given SandboxInfo id
+func (f *Force) GetSandboxProcess(sandboxInfoId string) (SandboxProcess, error) {
+	soql := fmt.Sprintf(`SELECT Id, SandboxInfoId, SandboxName, LicenseType, Status, CopyProgress,
+	SandboxOrganization, Description, StartDate, EndDate
+	FROM SandboxProcess
+	WHERE SandboxInfoId = %s
+	ORDER BY CreatedDate DESC
+	LIMIT 1`, soqlQuote(sandboxInfoId))
+	var processes []SandboxProcess
+	if err := f.QueryInto(soql, &processes, isTooling); err != nil {
+		return SandboxProcess{}, fmt.Errorf("Could not query sandbox process: %w", err)
+	}
+	if len(processes) == 0 {
+		return SandboxProcess{}, fmt.Errorf("No sandbox process found for %s", sandboxInfoId)
+	}
+	return processes[0], nil
+}
+
+// WaitForSandbox polls the most recent process for a sandbox until it is
+// done or the context is done, calling progress with each update.
+func (f *Force) WaitForSandbox(ctx context.Context, sandboxInfoId string, interval time.Duration, progress func(SandboxProcess)) (SandboxProcess, error) {
+	for {
+		process, err := f.GetSandboxProcess(sandboxInfoId)
+		if err != nil {
+			return process, err
+		}
+		if progress != nil {
+			progress(process)
+		}
+		if process.Done() {
+			if process.Status != SandboxStatusCompleted {
+				return process, fmt.Errorf("Sandbox %s %s", process.SandboxName, strings.ToLower(process.Status))
+			}
+			return process, nil
+		}
+		select {
+		case <-ctx.Done():
+			return process, fmt.Errorf("Stopped waiting for sandbox %s: %w", process.SandboxName, ctx.Err())
+		case <-time.After(interval):
+		}
+	}
+}
+
+// GetApexClassId returns the id of an Apex class by name
+func (f *Force) GetApexClassId(name string) (string, error) {
+	var classes []struct {
+		Id string
+	}
+	err := f.QueryInto(fmt.Sprintf("SELECT Id FROM ApexClass WHERE Name = %s", soqlQuote(name)), &classes)
+	if err != nil {
+		return "", fmt.Errorf("Could not query Apex class: %w", err)
+	}
+	if len(classes) == 0 {
+		return "", fmt.Errorf("Apex class %s not found", name)
+	}
+	return classes[0].Id, nil
+}
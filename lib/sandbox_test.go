@@ -0,0 +1,100 @@
+package lib_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	. "github.com/ForceCLI/force/lib"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("Sandbox", func() {
+	Describe("SandboxLicenseType", func() {
+		It("should normalize license types", func() {
+			Expect(SandboxLicenseType("Developer")).To(Equal("DEVELOPER"))
+			Expect(SandboxLicenseType("developer-pro")).To(Equal("DEVELOPER_PRO"))
+			Expect(SandboxLicenseType("Developer Pro")).To(Equal("DEVELOPER_PRO"))
+			Expect(SandboxLicenseType("FULL")).To(Equal("FULL"))
+		})
+
+		It("should reject unknown license types", func() {
+			_, err := SandboxLicenseType("enterprise")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("SandboxUsername", func() {
+		It("should append the lowercase sandbox name", func() {
+			Expect(SandboxUsername("admin@example.com", "QA")).To(Equal("admin@example.com.qa"))
+		})
+	})
+
+	Describe("SandboxProcess", func() {
+		It("should be done when completed or stopped", func() {
+			Expect(SandboxProcess{Status: "Processing"}.Done()).To(BeFalse())
+			Expect(SandboxProcess{Status: "Completed"}.Done()).To(BeTrue())
+			Expect(SandboxProcess{Status: "Stopped"}.Done()).To(BeTrue())
+		})
+	})
+
+	Describe("with an org", func() {
+		var sfServer *Server
+		var f *Force
+
+		BeforeEach(func() {
+			sfServer = NewServer()
+			f = NewForce(&ForceSession{InstanceUrl: sfServer.URL()})
+		})
+		AfterEach(func() {
+			sfServer.Close()
+		})
+
+		It("should keep the current settings of a refreshed sandbox unless set", func() {
+			copyChatter := true
+			sfServer.AppendHandlers(
+				CombineHandlers(
+					verifyToolingQuery("SandboxInfo"),
+					func(w http.ResponseWriter, r *http.Request) {
+						Expect(r.URL.Query().Get("q")).To(ContainSubstring("SandboxName = 'qa'"))
+					},
+					RespondWith(200, queryResponse(`{"Id":"0GQ000000000001","SandboxName":"qa","LicenseType":"DEVELOPER","Description":"QA","HistoryDays":0,"CopyChatter":false,"AutoActivate":false}`), JsonHeaders),
+				),
+				CombineHandlers(
+					VerifyRequest("PATCH", "/services/data/"+ApiVersion()+"/tooling/sobjects/SandboxInfo/0GQ000000000001"),
+					func(w http.ResponseWriter, r *http.Request) {
+						var info map[string]interface{}
+						Expect(json.Unmarshal(mustRead(r.Body), &info)).To(Succeed())
+						Expect(info).To(Equal(map[string]interface{}{
+							"LicenseType":  "DEVELOPER",
+							"Description":  "QA",
+							"CopyChatter":  true,
+							"AutoActivate": false,
+						}))
+					},
+					RespondWith(204, ""),
+				),
+			)
+			id, err := f.RefreshSandbox("qa", SandboxOptions{CopyChatter: &copyChatter})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(id).To(Equal("0GQ000000000001"))
+		})
+
+		It("should stop waiting for a sandbox when the context is done", func() {
+			sfServer.AppendHandlers(
+				CombineHandlers(
+					verifyToolingQuery("SandboxProcess"),
+					RespondWith(200, queryResponse(`{"Id":"0GR000000000001","SandboxName":"qa","Status":"Processing","CopyProgress":10}`), JsonHeaders),
+				),
+			)
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+			_, err := f.WaitForSandbox(ctx, "0GQ000000000001", time.Minute, nil)
+			Expect(err).To(MatchError(ContainSubstring("Stopped waiting for sandbox qa")))
+		})
+	})
+})
@@ -0,0 +1,697 @@
+package lib
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ForceCLI/inflect"
+	"gopkg.in/yaml.v3"
+)
+
+// SchemaSpec declares the objects, fields, and field-level security that
+// should exist in an org
+type SchemaSpec struct {
+	Objects []SchemaObject `yaml:"objects"`
+}
+
+// SchemaObject declares a standard or custom object.  Custom objects are
+// created if they don't exist.  Only the declared fields of standard objects
+// are managed.
+type SchemaObject struct {
+	Name         string          `yaml:"name"`
+	Label        string          `yaml:"label"`
+	PluralLabel  string          `yaml:"pluralLabel"`
+	Description  string          `yaml:"description"`
+	SharingModel string          `yaml:"sharingModel"`
+	NameField    SchemaNameField `yaml:"nameField"`
+	Fields       []SchemaField   `yaml:"fields"`
+}
+
+// SchemaNameField declares the Name field of a custom object
+type SchemaNameField struct {
+	Label         string `yaml:"label"`
+	Type          string `yaml:"type"`
+	DisplayFormat string `yaml:"displayFormat"`
+}
+
+// SchemaField declares a custom field.  Permissions maps profile names to
+// read or edit access.
+type SchemaField struct {
+	Name              string            `yaml:"name"`
+	Label             string            `yaml:"label"`
+	Type              string            `yaml:"type"`
+	Description       string            `yaml:"description"`
+	InlineHelpText    string            `yaml:"inlineHelpText"`
+	Length            int               `yaml:"length"`
+	Precision         int               `yaml:"precision"`
+	Scale             int               `yaml:"scale"`
+	VisibleLines      int               `yaml:"visibleLines"`
+	Required          bool              `yaml:"required"`
+	Unique            bool              `yaml:"unique"`
+	ExternalId        bool              `yaml:"externalId"`
+	DefaultValue      string            `yaml:"defaultValue"`
+	Values            []string          `yaml:"values"`
+	ReferenceTo       string            `yaml:"referenceTo"`
+	RelationshipName  string            `yaml:"relationshipName"`
+	RelationshipLabel string            `yaml:"relationshipLabel"`
+	DeleteConstraint  string            `yaml:"deleteConstraint"`
+	Permissions       map[string]string `yaml:"permissions"`
+}
+
+const (
+	SchemaAccessNone = "none"
+	SchemaAccessRead = "read"
+	SchemaAccessEdit = "edit"
+)
+
+// Field types that can be declared, keyed by lowercase name or alias, with
+// the metadata type and the type reported by describe
+var schemaFieldTypes = map[string]struct{ metadataType, describeType string }{
+	"text":                {"Text", "string"},
+	"string":              {"Text", "string"},
+	"textarea":            {"TextArea", "textarea"},
+	"longtextarea":        {"LongTextArea", "textarea"},
+	"html":                {"Html", "textarea"},
+	"richtextarea":        {"Html", "textarea"},
+	"number":              {"Number", "double"},
+	"double":              {"Number", "double"},
+	"int":                 {"Number", "double"},
+	"currency":            {"Currency", "currency"},
+	"percent":             {"Percent", "percent"},
+	"checkbox":            {"Checkbox", "boolean"},
+	"boolean":             {"Checkbox", "boolean"},
+	"date":                {"Date", "date"},
+	"datetime":            {"DateTime", "datetime"},
+	"email":               {"Email", "email"},
+	"phone":               {"Phone", "phone"},
+	"url":                 {"Url", "url"},
+	"picklist":            {"Picklist", "picklist"},
+	"multiselectpicklist": {"MultiselectPicklist", "multipicklist"},
+	"multipicklist":       {"MultiselectPicklist", "multipicklist"},
+	"lookup":              {"Lookup", "reference"},
+	"masterdetail":        {"MasterDetail", "reference"},
+}
+
+// ParseSchemaSpec parses and validates a YAML schema spec, filling in
+// defaults
+func ParseSchemaSpec(data []byte) (SchemaSpec, error) {
+	var spec SchemaSpec
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		return spec, fmt.Errorf("Invalid schema: %w", err)
+	}
+	var problems []string
+	for i := range spec.Objects {
+		o := &spec.Objects[i]
+		if o.Name == "" {
+			problems = append(problems, fmt.Sprintf("object %d: missing name", i+1))
+			continue
+		}
+		if o.isCustom() {
+			base := strings.Replace(strings.TrimSuffix(o.Name, "__c"), "_", " ", -1)
+			if o.Label == "" {
+				o.Label = base
+			}
+			if o.PluralLabel == "" {
+				o.PluralLabel = inflect.Pluralize(o.Label)
+			}
+			if o.SharingModel == "" {
+				o.SharingModel = "ReadWrite"
+			}
+			if o.NameField.Label == "" {
+				o.NameField.Label = o.Label + " Name"
+			}
+			if o.NameField.Type == "" {
+				o.NameField.Type = "Text"
+			}
+			if strings.EqualFold(o.NameField.Type, "AutoNumber") && o.NameField.DisplayFormat == "" {
+				o.NameField.DisplayFormat = strings.ToUpper(o.Label[0:1]) + "-{00000}"
+			}
+		}
+		for j := range o.Fields {
+			if err := o.Fields[j].normalize(); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %s", o.Name, err.Error()))
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return spec, fmt.Errorf("Invalid schema:\n%s", strings.Join(problems, "\n"))
+	}
+	return spec, nil
+}
+
+func (o SchemaObject) isCustom() bool {
+	return strings.HasSuffix(o.Name, "__c")
+}
+
+func (f *SchemaField) normalize() error {
+	if f.Name == "" {
+		return fmt.Errorf("field missing name")
+	}
+	if !strings.HasSuffix(f.Name, "__c") {
+		f.Name += "__c"
+	}
+	t, ok := schemaFieldTypes[strings.ToLower(f.Type)]
+	if !ok {
+		return fmt.Errorf("%s: unsupported type %q", f.Name, f.Type)
+	}
+	f.Type = t.metadataType
+	if f.Label == "" {
+		f.Label = strings.Replace(strings.TrimSuffix(f.Name, "__c"), "_", " ", -1)
+	}
+	switch f.Type {
+	case "Text":
+		if f.Length == 0 {
+			f.Length = 255
+		}
+	case "LongTextArea", "Html":
+		if f.Length == 0 {
+			f.Length = 32768
+		}
+		if f.VisibleLines == 0 {
+			f.VisibleLines = 5
+			if f.Type == "Html" {
+				f.VisibleLines = 25
+			}
+		}
+	case "MultiselectPicklist":
+		if f.VisibleLines == 0 {
+			f.VisibleLines = 4
+		}
+		fallthrough
+	case "Picklist":
+		if len(f.Values) == 0 {
+			return fmt.Errorf("%s: picklist values required", f.Name)
+		}
+	case "Number", "Currency", "Percent":
+		if f.Precision == 0 {
+			f.Precision = 18
+			if f.Scale == 0 && f.Type == "Currency" {
+				f.Scale = 2
+			}
+		}
+	case "Checkbox":
+		if f.DefaultValue == "" {
+			f.DefaultValue = "false"
+		}
+	case "Lookup", "MasterDetail":
+		if f.ReferenceTo == "" {
+			return fmt.Errorf("%s: referenceTo required", f.Name)
+		}
+		if f.RelationshipName == "" {
+			f.RelationshipName = strings.TrimSuffix(f.Name, "__c")
+		}
+		if f.RelationshipLabel == "" {
+			f.RelationshipLabel = f.Label
+		}
+		if f.Type == "Lookup" && f.DeleteConstraint == "" {
+			f.DeleteConstraint = "SetNull"
+		}
+	}
+	for profile, access := range f.Permissions {
+		access = strings.ToLower(access)
+		switch access {
+		case SchemaAccessNone, SchemaAccessRead, SchemaAccessEdit:
+			f.Permissions[profile] = access
+		default:
+			return fmt.Errorf("%s: invalid access %q for %s; must be none, read, or edit", f.Name, access, profile)
+		}
+	}
+	return nil
+}
+
+// SchemaChange is a difference between a schema spec and an org
+type SchemaChange struct {
+	// Action is create or update
+	Action  string
+	Object  string
+	Field   string
+	Profile string
+	// Differences describes what will be updated
+	Differences []string
+}
+
+func (c SchemaChange) String() string {
+	symbol := "~"
+	if c.Action == "create" {
+		symbol = "+"
+	}
+	var s string
+	switch {
+	case c.Profile != "":
+		s = fmt.Sprintf("%s %s field permissions %s.%s for %s", symbol, c.Action, c.Object, c.Field, c.Profile)
+	case c.Field != "":
+		s = fmt.Sprintf("%s %s field %s.%s", symbol, c.Action, c.Object, c.Field)
+	default:
+		s = fmt.Sprintf("%s %s object %s", symbol, c.Action, c.Object)
+	}
+	for _, d := range c.Differences {
+		s += "\n    " + d
+	}
+	return s
+}
+
+// SchemaFieldAccess is the current field-level security, keyed by profile
+// name and then by Object.Field
+type SchemaFieldAccess map[string]map[string]string
+
+// SchemaPlan is the set of changes needed to make an org match a schema spec
+type SchemaPlan struct {
+	Spec    SchemaSpec
+	Changes []SchemaChange
+}
+
+// NewSchemaPlan compares a schema spec to the describe results of its
+// objects, which are nil for objects that don't exist and whose fields include
+// their descriptions, and the current field-level security.
+func NewSchemaPlan(spec SchemaSpec, describes map[string]ForceSobject, access SchemaFieldAccess) SchemaPlan {
+	plan := SchemaPlan{Spec: spec}
+	for _, o := range spec.Objects {
+		describe := describes[o.Name]
+		existingFields := make(map[string]map[string]interface{})
+		if describe == nil {
+			plan.Changes = append(plan.Changes, SchemaChange{Action: "create", Object: o.Name})
+		} else {
+			fields, _ := describe["fields"].([]interface{})
+			for _, f := range fields {
+				if field, ok := f.(map[string]interface{}); ok {
+					name, _ := field["name"].(string)
+					existingFields[strings.ToLower(name)] = field
+				}
+			}
+		}
+		for _, f := range o.Fields {
+			existing, ok := existingFields[strings.ToLower(f.Name)]
+			if !ok {
+				plan.Changes = append(plan.Changes, SchemaChange{Action: "create", Object: o.Name, Field: f.Name})
+			} else if differences := f.differences(existing); len(differences) > 0 {
+				plan.Changes = append(plan.Changes, SchemaChange{Action: "update", Object: o.Name, Field: f.Name, Differences: differences})
+			}
+			for _, profile := range sortedStringKeys(f.Permissions) {
+				want := f.Permissions[profile]
+				have := access[profile][o.Name+"."+f.Name]
+				if have == "" {
+					have = SchemaAccessNone
+				}
+				if want == have {
+					continue
+				}
+				plan.Changes = append(plan.Changes, SchemaChange{
+					Action:      "update",
+					Object:      o.Name,
+					Field:       f.Name,
+					Profile:     profile,
+					Differences: []string{fmt.Sprintf("access: %s -> %s", have, want)},
+				})
+			}
+		}
+	}
+	return plan
+}
+
+func (f SchemaField) differences(describe map[string]interface{}) []string {
+	var differences []string
+	compare := func(name string, have, want interface{}) {
+		if fmt.Sprint(have) != fmt.Sprint(want) {
+			differences = append(differences, fmt.Sprintf("%s: %v -> %v", name, have, want))
+		}
+	}
+	str := func(key string) string {
+		s, _ := describe[key].(string)
+		return s
+	}
+	num := func(key string) int {
+		n, _ := describe[key].(float64)
+		return int(n)
+	}
+	flag := func(key string) bool {
+		b, _ := describe[key].(bool)
+		return b
+	}
+
+	compare("label", str("label"), f.Label)
+	describeType := schemaFieldTypes[strings.ToLower(f.Type)].describeType
+	if str("type") != describeType {
+		compare("type", str("type"), describeType)
+		return differences
+	}
+	switch f.Type {
+	case "Text", "LongTextArea", "Html":
+		compare("length", num("length"), f.Length)
+	case "Number", "Currency", "Percent":
+		compare("precision", num("precision"), f.Precision)
+		compare("scale", num("scale"), f.Scale)
+	case "Picklist", "MultiselectPicklist":
+		var values []string
+		entries, _ := describe["picklistValues"].([]interface{})
+		for _, e := range entries {
+			if entry, ok := e.(map[string]interface{}); ok {
+				if active, _ := entry["active"].(bool); active {
+					value, _ := entry["value"].(string)
+					values = append(values, value)
+				}
+			}
+		}
+		compare("values", strings.Join(values, ", "), strings.Join(f.Values, ", "))
+	case "Lookup", "MasterDetail":
+		var references []string
+		refs, _ := describe["referenceTo"].([]interface{})
+		for _, r := range refs {
+			references = append(references, fmt.Sprint(r))
+		}
+		compare("referenceTo", strings.Join(references, ", "), f.ReferenceTo)
+		compare("masterDetail", flag("cascadeDelete"), f.Type == "MasterDetail")
+	}
+	if f.Type != "Checkbox" && f.Type != "MasterDetail" {
+		compare("required", !flag("nillable"), f.Required)
+	}
+	if f.Type == "Text" || f.Type == "Number" || f.Type == "Email" {
+		compare("unique", flag("unique"), f.Unique)
+		compare("externalId", flag("externalId"), f.ExternalId)
+	}
+	compare("description", str("description"), f.Description)
+	compare("inlineHelpText", str("inlineHelpText"), f.InlineHelpText)
+	return differences
+}
+
+// MetadataFiles returns the metadata to deploy to apply the plan's changes.
+// profileFullNames maps the profile names used in the spec to their metadata
+// names, e.g. "System Administrator" to "Admin".
+func (p SchemaPlan) MetadataFiles(profileFullNames map[string]string) (ForceMetadataFiles, error) {
+	files := make(ForceMetadataFiles)
+	pb := NewPushBuilder()
+	objects := make(map[string]SchemaObject)
+	for _, o := range p.Spec.Objects {
+		objects[o.Name] = o
+	}
+
+	createObjects := make(map[string]bool)
+	objectFields := make(map[string][]SchemaField)
+	profileFields := make(map[string][]string)
+	for _, c := range p.Changes {
+		switch {
+		case c.Profile != "":
+			profileFields[c.Profile] = append(profileFields[c.Profile], c.Object+"."+c.Field)
+		case c.Field == "":
+			createObjects[c.Object] = true
+			objectFields[c.Object] = objectFields[c.Object]
+		default:
+			for _, f := range objects[c.Object].Fields {
+				if f.Name == c.Field {
+					objectFields[c.Object] = append(objectFields[c.Object], f)
+					pb.AddMetaToPackage("CustomField", c.Object+"."+c.Field)
+				}
+			}
+		}
+	}
+	var objectOrder []string
+	for name := range objectFields {
+		objectOrder = append(objectOrder, name)
+	}
+	sort.Strings(objectOrder)
+	for _, name := range objectOrder {
+		o := objects[name]
+		var b strings.Builder
+		b.WriteString(xml.Header)
+		b.WriteString(`<CustomObject xmlns="http://soap.sforce.com/2006/04/metadata">` + "\n")
+		if createObjects[name] {
+			pb.AddMetaToPackage("CustomObject", name)
+			writeSchemaElement(&b, "    ", "deploymentStatus", "Deployed")
+			writeSchemaElement(&b, "    ", "description", o.Description)
+		}
+		for _, f := range objectFields[name] {
+			b.WriteString("    <fields>\n")
+			f.writeXml(&b, "        ")
+			b.WriteString("    </fields>\n")
+		}
+		if createObjects[name] {
+			writeSchemaElement(&b, "    ", "label", o.Label)
+			b.WriteString("    <nameField>\n")
+			if strings.EqualFold(o.NameField.Type, "AutoNumber") {
+				writeSchemaElement(&b, "        ", "displayFormat", o.NameField.DisplayFormat)
+			}
+			writeSchemaElement(&b, "        ", "label", o.NameField.Label)
+			writeSchemaElement(&b, "        ", "type", o.NameField.Type)
+			b.WriteString("    </nameField>\n")
+			writeSchemaElement(&b, "    ", "pluralLabel", o.PluralLabel)
+			writeSchemaElement(&b, "    ", "sharingModel", o.SharingModel)
+		}
+		b.WriteString("</CustomObject>\n")
+		files["objects/"+name+".object"] = []byte(b.String())
+	}
+
+	var profiles []string
+	for profile := range profileFields {
+		profiles = append(profiles, profile)
+	}
+	sort.Strings(profiles)
+	for _, profile := range profiles {
+		fullName, ok := profileFullNames[profile]
+		if !ok {
+			return nil, fmt.Errorf("Unknown profile %s", profile)
+		}
+		pb.AddMetaToPackage("Profile", fullName)
+		var b strings.Builder
+		b.WriteString(xml.Header)
+		b.WriteString(`<Profile xmlns="http://soap.sforce.com/2006/04/metadata">` + "\n")
+		fields := profileFields[profile]
+		sort.Strings(fields)
+		for _, field := range fields {
+			access := p.fieldAccess(field, profile)
+			b.WriteString("    <fieldPermissions>\n")
+			writeSchemaElement(&b, "        ", "editable", strconv.FormatBool(access == SchemaAccessEdit))
+			writeSchemaElement(&b, "        ", "field", field)
+			writeSchemaElement(&b, "        ", "readable", strconv.FormatBool(access != SchemaAccessNone))
+			b.WriteString("    </fieldPermissions>\n")
+		}
+		b.WriteString("</Profile>\n")
+		files["profiles/"+fullName+".profile"] = []byte(b.String())
+	}
+	files["package.xml"] = pb.PackageXml()
+	return files, nil
+}
+
+func (p SchemaPlan) fieldAccess(objectField string, profile string) string {
+	for _, o := range p.Spec.Objects {
+		for _, f := range o.Fields {
+			if o.Name+"."+f.Name == objectField {
+				return f.Permissions[profile]
+			}
+		}
+	}
+	return SchemaAccessNone
+}
+
+// Profiles returns the names of the profiles whose field-level security is
+// declared in the spec
+func (spec SchemaSpec) Profiles() []string {
+	profiles := make(map[string]string)
+	for _, o := range spec.Objects {
+		for _, f := range o.Fields {
+			for profile := range f.Permissions {
+				profiles[profile] = profile
+			}
+		}
+	}
+	return sortedStringKeys(profiles)
+}
+
+func (f SchemaField) writeXml(b *strings.Builder, indent string) {
+	writeSchemaElement(b, indent, "fullName", f.Name)
+	if f.DefaultValue != "" {
+		writeSchemaElement(b, indent, "defaultValue", f.DefaultValue)
+	}
+	if f.Type == "Lookup" {
+		writeSchemaElement(b, indent, "deleteConstraint", f.DeleteConstraint)
+	}
+	writeSchemaElement(b, indent, "description", f.Description)
+	if f.Type == "Text" || f.Type == "Number" || f.Type == "Email" {
+		writeSchemaElement(b, indent, "externalId", strconv.FormatBool(f.ExternalId))
+	}
+	writeSchemaElement(b, indent, "inlineHelpText", f.InlineHelpText)
+	writeSchemaElement(b, indent, "label", f.Label)
+	switch f.Type {
+	case "Text", "LongTextArea", "Html":
+		writeSchemaElement(b, indent, "length", strconv.Itoa(f.Length))
+	case "Number", "Currency", "Percent":
+		writeSchemaElement(b, indent, "precision", strconv.Itoa(f.Precision))
+	case "Lookup", "MasterDetail":
+		writeSchemaElement(b, indent, "referenceTo", f.ReferenceTo)
+		writeSchemaElement(b, indent, "relationshipLabel", f.RelationshipLabel)
+		writeSchemaElement(b, indent, "relationshipName", f.RelationshipName)
+	}
+	if f.Type != "Checkbox" && f.Type != "MasterDetail" {
+		writeSchemaElement(b, indent, "required", strconv.FormatBool(f.Required))
+	}
+	switch f.Type {
+	case "Number", "Currency", "Percent":
+		writeSchemaElement(b, indent, "scale", strconv.Itoa(f.Scale))
+	}
+	writeSchemaElement(b, indent, "type", f.Type)
+	if f.Type == "Text" || f.Type == "Number" || f.Type == "Email" {
+		writeSchemaElement(b, indent, "unique", strconv.FormatBool(f.Unique))
+	}
+	if f.Type == "Picklist" || f.Type == "MultiselectPicklist" {
+		b.WriteString(indent + "<valueSet>\n")
+		b.WriteString(indent + "    <valueSetDefinition>\n")
+		for _, v := range f.Values {
+			b.WriteString(indent + "        <value>\n")
+			writeSchemaElement(b, indent+"            ", "fullName", v)
+			writeSchemaElement(b, indent+"            ", "default", "false")
+			writeSchemaElement(b, indent+"            ", "label", v)
+			b.WriteString(indent + "        </value>\n")
+		}
+		b.WriteString(indent + "    </valueSetDefinition>\n")
+		b.WriteString(indent + "</valueSet>\n")
+	}
+	if f.VisibleLines > 0 {
+		writeSchemaElement(b, indent, "visibleLines", strconv.Itoa(f.VisibleLines))
+	}
+}
+
+func writeSchemaElement(b *strings.Builder, indent string, name string, value string) {
+	if value == "" {
+		return
+	}
+	fmt.Fprintf(b, "%s<%s>%s</%s>\n", indent, name, xmlEscape(value), name)
+}
+
+func sortedStringKeys(m map[string]string) []string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// PlanSchema compares a schema spec to the org
+func (f *Force) PlanSchema(spec SchemaSpec) (SchemaPlan, error) {
+	describes := make(map[string]ForceSobject)
+	var objectNames []string
+	for _, o := range spec.Objects {
+		objectNames = append(objectNames, o.Name)
+		sobject, err := f.GetSobject(o.Name)
+		var forceErrors ForceErrors
+		switch {
+		case errors.As(err, &forceErrors) && len(forceErrors) > 0 && forceErrors[0].ErrorCode == "NOT_FOUND":
+			if !o.isCustom() {
+				return SchemaPlan{}, fmt.Errorf("Object %s does not exist", o.Name)
+			}
+			describes[o.Name] = nil
+		case err != nil:
+			return SchemaPlan{}, fmt.Errorf("Could not describe %s: %w", o.Name, err)
+		default:
+			if err := f.addFieldDescriptions(o.Name, sobject); err != nil {
+				return SchemaPlan{}, err
+			}
+			describes[o.Name] = sobject
+		}
+	}
+	access, err := f.queryFieldAccess(spec.Profiles(), objectNames)
+	if err != nil {
+		return SchemaPlan{}, err
+	}
+	return NewSchemaPlan(spec, describes, access), nil
+}
+
+// addFieldDescriptions adds the description of each field, which isn't
+// included in describe results, to the fields of an object's describe
+func (f *Force) addFieldDescriptions(object string, describe ForceSobject) error {
+	var definitions []struct {
+		QualifiedApiName string
+		Description      string
+	}
+	soql := fmt.Sprintf("SELECT QualifiedApiName, Description FROM FieldDefinition WHERE EntityDefinition.QualifiedApiName = %s", soqlQuote(object))
+	if err := f.QueryInto(soql, &definitions, isTooling); err != nil {
+		return fmt.Errorf("Could not query field descriptions for %s: %w", object, err)
+	}
+	descriptions := make(map[string]string)
+	for _, d := range definitions {
+		descriptions[strings.ToLower(d.QualifiedApiName)] = d.Description
+	}
+	fields, _ := describe["fields"].([]interface{})
+	for _, field := range fields {
+		if field, ok := field.(map[string]interface{}); ok {
+			name, _ := field["name"].(string)
+			field["description"] = descriptions[strings.ToLower(name)]
+		}
+	}
+	return nil
+}
+
+func (f *Force) queryFieldAccess(profiles []string, objects []string) (SchemaFieldAccess, error) {
+	access := make(SchemaFieldAccess)
+	if len(profiles) == 0 {
+		return access, nil
+	}
+	soql := fmt.Sprintf(`SELECT Field, PermissionsRead, PermissionsEdit, Parent.Profile.Name
+	FROM FieldPermissions
+	WHERE Parent.IsOwnedByProfile = true AND Parent.Profile.Name IN (%s) AND SobjectType IN (%s)`,
+		soqlQuoteList(profiles), soqlQuoteList(objects))
+	var permissions []struct {
+		Field           string
+		PermissionsRead bool
+		PermissionsEdit bool
+		Parent          struct {
+			Profile struct {
+				Name string
+			}
+		}
+	}
+	if err := f.QueryInto(soql, &permissions); err != nil {
+		return nil, fmt.Errorf("Could not query field permissions: %w", err)
+	}
+	for _, p := range permissions {
+		profile := p.Parent.Profile.Name
+		if access[profile] == nil {
+			access[profile] = make(map[string]string)
+		}
+		switch {
+		case p.PermissionsEdit:
+			access[profile][p.Field] = SchemaAccessEdit
+		case p.PermissionsRead:
+			access[profile][p.Field] = SchemaAccessRead
+		}
+	}
+	return access, nil
+}
+
+// ProfileFullNames returns the metadata names of profiles, e.g. Admin for
+// System Administrator
+func (f *Force) ProfileFullNames(profiles []string) (map[string]string, error) {
+	fullNames := make(map[string]string)
+	for _, name := range profiles {
+		var results []struct {
+			FullName string
+		}
+		err := f.QueryInto(fmt.Sprintf("SELECT Id, FullName FROM Profile WHERE Name = %s LIMIT 1", soqlQuote(name)), &results, isTooling)
+		if err != nil {
+			return nil, fmt.Errorf("Could not query profile %s: %w", name, err)
+		}
+		if len(results) == 0 {
+			return nil, fmt.Errorf("Profile %s not found", name)
+		}
+		fullNames[name] = results[0].FullName
+	}
+	return fullNames, nil
+}
+
+// ApplySchema deploys the changes in a schema plan
+func (f *Force) ApplySchema(plan SchemaPlan) error {
+	if len(plan.Changes) == 0 {
+		return nil
+	}
+	fullNames, err := f.ProfileFullNames(plan.Spec.Profiles())
+	if err != nil {
+		return err
+	}
+	files, err := plan.MetadataFiles(fullNames)
+	if err != nil {
+		return err
+	}
+	return f.deployMetadataFiles(files, "schema")
+}
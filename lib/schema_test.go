@@ -0,0 +1,189 @@
+package lib_test
+
+import (
+	"net/http"
+
+	. "github.com/ForceCLI/force/lib"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("Schema", func() {
+	const specYaml = `
+objects:
+  - name: Invoice__c
+    fields:
+      - name: Amount
+        type: currency
+        permissions:
+          System Administrator: Edit
+  - name: Account
+    fields:
+      - name: Region__c
+        label: Region
+        type: text
+        length: 40
+      - name: Tier__c
+        type: picklist
+        values: [Gold, Silver]
+`
+
+	var spec SchemaSpec
+
+	BeforeEach(func() {
+		var err error
+		spec, err = ParseSchemaSpec([]byte(specYaml))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	Describe("ParseSchemaSpec", func() {
+		It("should fill in defaults", func() {
+			invoice := spec.Objects[0]
+			Expect(invoice.Label).To(Equal("Invoice"))
+			Expect(invoice.PluralLabel).To(Equal("Invoices"))
+			Expect(invoice.Fields[0].Name).To(Equal("Amount__c"))
+			Expect(invoice.Fields[0].Type).To(Equal("Currency"))
+			Expect(invoice.Fields[0].Precision).To(Equal(18))
+			Expect(invoice.Fields[0].Scale).To(Equal(2))
+			Expect(invoice.Fields[0].Permissions).To(HaveKeyWithValue("System Administrator", "edit"))
+		})
+
+		It("should reject invalid fields", func() {
+			_, err := ParseSchemaSpec([]byte(`
+objects:
+  - name: Invoice__c
+    fields:
+      - name: Owner__c
+        type: lookup
+      - name: Size__c
+        type: huge
+`))
+			Expect(err).To(MatchError(ContainSubstring("Owner__c: referenceTo required")))
+			Expect(err).To(MatchError(ContainSubstring(`Size__c: unsupported type "huge"`)))
+		})
+	})
+
+	Describe("NewSchemaPlan", func() {
+		account := ForceSobject{
+			"name": "Account",
+			"fields": []interface{}{
+				map[string]interface{}{"name": "Region__c", "label": "Region", "type": "string", "length": float64(40), "nillable": true},
+				map[string]interface{}{"name": "Tier__c", "label": "Tier", "type": "picklist", "nillable": true, "picklistValues": []interface{}{
+					map[string]interface{}{"value": "Gold", "active": true},
+					map[string]interface{}{"value": "Bronze", "active": true},
+				}},
+			},
+		}
+
+		It("should only include differences", func() {
+			plan := NewSchemaPlan(spec, map[string]ForceSobject{"Invoice__c": nil, "Account": account}, SchemaFieldAccess{})
+			Expect(plan.Changes).To(HaveLen(4))
+			Expect(plan.Changes[0].String()).To(Equal("+ create object Invoice__c"))
+			Expect(plan.Changes[1].String()).To(Equal("+ create field Invoice__c.Amount__c"))
+			Expect(plan.Changes[2].String()).To(Equal("~ update field permissions Invoice__c.Amount__c for System Administrator\n    access: none -> edit"))
+			Expect(plan.Changes[3].String()).To(Equal("~ update field Account.Tier__c\n    values: Gold, Bronze -> Gold, Silver"))
+		})
+
+		It("should skip field permissions that match", func() {
+			access := SchemaFieldAccess{"System Administrator": {"Invoice__c.Amount__c": "edit"}}
+			plan := NewSchemaPlan(spec, map[string]ForceSobject{"Invoice__c": nil, "Account": account}, access)
+			Expect(plan.Changes).To(HaveLen(3))
+		})
+
+		It("should include description changes", func() {
+			spec.Objects[1].Fields[0].Description = "Sales region"
+			plan := NewSchemaPlan(spec, map[string]ForceSobject{"Invoice__c": nil, "Account": account}, SchemaFieldAccess{})
+			Expect(plan.Changes).To(HaveLen(5))
+			Expect(plan.Changes[3].String()).To(Equal("~ update field Account.Region__c\n    description:  -> Sales region"))
+		})
+	})
+
+	Describe("PlanSchema", func() {
+		var sfServer *Server
+		var f *Force
+
+		BeforeEach(func() {
+			sfServer = NewServer()
+			f = NewForce(&ForceSession{InstanceUrl: sfServer.URL()})
+		})
+
+		AfterEach(func() {
+			sfServer.Close()
+		})
+
+		It("should compare field descriptions and quote profile names", func() {
+			spec, err := ParseSchemaSpec([]byte(`
+objects:
+  - name: Account
+    fields:
+      - name: Region__c
+        type: text
+        length: 40
+        description: Sales region
+        permissions:
+          "Custom: Sales Rep's": Read
+`))
+			Expect(err).ToNot(HaveOccurred())
+			sfServer.AppendHandlers(
+				CombineHandlers(
+					VerifyRequest("GET", "/services/data/"+ApiVersion()+"/sobjects/Account/describe"),
+					RespondWith(200, `{"name":"Account","fields":[{"name":"Region__c","label":"Region","type":"string","length":40,"nillable":true}]}`, JsonHeaders),
+				),
+				CombineHandlers(
+					verifyToolingQuery("FieldDefinition"),
+					func(w http.ResponseWriter, r *http.Request) {
+						Expect(r.URL.Query().Get("q")).To(HaveSuffix("EntityDefinition.QualifiedApiName = 'Account'"))
+					},
+					RespondWith(200, queryResponse(`{"QualifiedApiName":"Region__c","Description":"Region"}`), JsonHeaders),
+				),
+				CombineHandlers(
+					verifyQuery("FieldPermissions"),
+					func(w http.ResponseWriter, r *http.Request) {
+						Expect(r.URL.Query().Get("q")).To(ContainSubstring(`Parent.Profile.Name IN ('Custom: Sales Rep\'s')`))
+					},
+					RespondWith(200, `{"totalSize":0,"done":true,"records":[]}`, JsonHeaders),
+				),
+			)
+			plan, err := f.PlanSchema(spec)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(plan.Changes).To(HaveLen(2))
+			Expect(plan.Changes[0].String()).To(Equal("~ update field Account.Region__c\n    description: Region -> Sales region"))
+		})
+	})
+
+	Describe("MetadataFiles", func() {
+		It("should generate objects and profiles", func() {
+			plan := NewSchemaPlan(spec, map[string]ForceSobject{"Invoice__c": nil, "Account": {"name": "Account", "fields": []interface{}{}}}, SchemaFieldAccess{})
+			files, err := plan.MetadataFiles(map[string]string{"System Administrator": "Admin"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(files).To(HaveKey("objects/Invoice__c.object"))
+			Expect(files).To(HaveKey("objects/Account.object"))
+			Expect(files).To(HaveKey("profiles/Admin.profile"))
+
+			invoice := string(files["objects/Invoice__c.object"])
+			Expect(invoice).To(ContainSubstring("<sharingModel>ReadWrite</sharingModel>"))
+			Expect(invoice).To(ContainSubstring("<fullName>Amount__c</fullName>"))
+			Expect(invoice).To(ContainSubstring("<type>Currency</type>"))
+
+			account := string(files["objects/Account.object"])
+			Expect(account).ToNot(ContainSubstring("<label>Account</label>"))
+			Expect(account).To(ContainSubstring("<length>40</length>"))
+			Expect(account).To(ContainSubstring("<fullName>Silver</fullName>"))
+
+			Expect(string(files["profiles/Admin.profile"])).To(ContainSubstring("<field>Invoice__c.Amount__c</field>"))
+
+			packageXml := string(files["package.xml"])
+			Expect(packageXml).To(ContainSubstring("<members>Invoice__c</members>"))
+			Expect(packageXml).To(ContainSubstring("<members>Account.Region__c</members>"))
+			Expect(packageXml).To(ContainSubstring("<members>Admin</members>"))
+		})
+
+		It("should require profile metadata names", func() {
+			plan := NewSchemaPlan(spec, map[string]ForceSobject{"Invoice__c": nil, "Account": nil}, SchemaFieldAccess{})
+			_, err := plan.MetadataFiles(map[string]string{})
+			Expect(err).To(MatchError("Unknown profile System Administrator"))
+		})
+	})
+})
@@ -8,12 +8,15 @@ import (
 	"io/ioutil"
 	"net/url"
 	"os"
+	"strconv"
+	"strings"
 )
 
 type ScratchOrg struct {
-	UserName    string
-	InstanceUrl string
-	AuthCode    string
+	UserName       string
+	InstanceUrl    string
+	AuthCode       string
+	ExpirationDate string
 }
 
 type AuthCodeSession struct {
@@ -36,15 +39,29 @@ func (f *Force) getScratchOrg(scratchOrgId string) (scratchOrg ScratchOrg, err e
 		InstanceUrl: org["LoginUrl"].(string),
 		AuthCode:    org["AuthCode"].(string),
 	}
+	scratchOrg.ExpirationDate, _ = org["ExpirationDate"].(string)
 	return
 }
 
+// deployScratchOrgSettings deploys the settings from a scratch org definition
+func (f *Force) deployScratchOrgSettings(settings map[string]interface{}) error {
+	files, err := ScratchOrgSettingsMetadata(settings)
+	if err != nil {
+		return err
+	}
+	return f.deployMetadataFiles(files, "scratch org settings")
+}
+
 // Log into a Scratch Org
 func (f *Force) ForceLoginNewScratch(scratchOrgId string) (session ForceSession, err error) {
 	scratchOrg, err := f.getScratchOrg(scratchOrgId)
 	if err != nil {
 		return
 	}
+	return scratchOrg.login()
+}
+
+func (scratchOrg ScratchOrg) login() (session ForceSession, err error) {
 	session, err = scratchOrg.getSession()
 	if err != nil {
 		return
@@ -108,6 +125,13 @@ func (f *Force) CreateScratchOrg() (id string, err error) {
 }
 
 func (f *Force) CreateScratchOrgWithUser(username string) (id string, err error) {
+	return f.CreateScratchOrgWithDefinition(ScratchOrgDefinition{Username: username})
+}
+
+// Create a new Scratch Org from a Dev Hub Org using the options in an
+// sfdx-style scratch org definition.  Settings are not applied; use
+// ScratchOrgSettingsMetadata to deploy them once the org is created.
+func (f *Force) CreateScratchOrgWithDefinition(definition ScratchOrgDefinition) (id string, err error) {
 	params := make(map[string]string)
 	params["ConnectedAppCallbackUrl"] = "http://localhost:1717/OauthRedirect"
 	params["ConnectedAppConsumerKey"] = "PlatformCLI"
@@ -115,8 +139,38 @@ func (f *Force) CreateScratchOrgWithUser(username string) (id string, err error)
 	params["Edition"] = "Developer"
 	params["Features"] = "AuthorApex;API;AddCustomApps:30;AddCustomTabs:30;ForceComPlatform;Sites;CustomerSelfService"
 	params["OrgName"] = "Force CLI Scratch"
-	if username != "" {
-		params["Username"] = username
+	if definition.Username != "" {
+		params["Username"] = definition.Username
+	}
+	if definition.Country != "" {
+		params["Country"] = definition.Country
+	}
+	if definition.Edition != "" {
+		params["Edition"] = definition.Edition
+	}
+	if len(definition.Features) > 0 {
+		params["Features"] = strings.Join(definition.Features, ";")
+	}
+	if definition.OrgName != "" {
+		params["OrgName"] = definition.OrgName
+	}
+	if definition.Language != "" {
+		params["Language"] = definition.Language
+	}
+	if definition.DurationDays > 0 {
+		params["DurationDays"] = strconv.Itoa(definition.DurationDays)
+	}
+	if definition.AdminEmail != "" {
+		params["AdminEmail"] = definition.AdminEmail
+	}
+	if definition.Description != "" {
+		params["Description"] = definition.Description
+	}
+	if definition.Release != "" {
+		params["Release"] = definition.Release
+	}
+	if definition.HasSampleData {
+		params["HasSampleData"] = "true"
 	}
 	id, err, messages := f.CreateRecord("ScratchOrgInfo", params)
 	if err != nil {
@@ -127,3 +181,67 @@ func (f *Force) CreateScratchOrgWithUser(username string) (id string, err error)
 	}
 	return
 }
+
+// ScratchOrgInfo is a scratch org request on a Dev Hub
+type ScratchOrgInfo struct {
+	Id             string
+	OrgName        string
+	SignupUsername string
+	ScratchOrg     string
+	Edition        string
+	Status         string
+	ExpirationDate string
+	CreatedDate    string
+	LoginUrl       string
+}
+
+// ActiveScratchOrg is an active scratch org on a Dev Hub
+type ActiveScratchOrg struct {
+	Id               string
+	ScratchOrg       string
+	ScratchOrgInfoId string
+	SignupUsername   string
+	OrgName          string
+	ExpirationDate   string
+	LastLoginDate    string
+}
+
+func devHubQueryError(err error) error {
+	if strings.Contains(err.Error(), "INVALID_TYPE") || strings.Contains(err.Error(), "is not supported") {
+		return DevHubOrgRequiredError
+	}
+	return err
+}
+
+// QueryScratchOrgInfos returns the scratch orgs created from the Dev Hub,
+// newest first.  Deleted and expired orgs are only included if all is true.
+func (f *Force) QueryScratchOrgInfos(all bool) ([]ScratchOrgInfo, error) {
+	soql := "SELECT Id, OrgName, SignupUsername, ScratchOrg, Edition, Status, ExpirationDate, CreatedDate, LoginUrl FROM ScratchOrgInfo"
+	if !all {
+		soql += " WHERE Status IN ('New', 'Creating', 'Active')"
+	}
+	soql += " ORDER BY CreatedDate DESC"
+	var orgs []ScratchOrgInfo
+	if err := f.QueryInto(soql, &orgs); err != nil {
+		return nil, devHubQueryError(err)
+	}
+	return orgs, nil
+}
+
+// QueryActiveScratchOrgs returns the active scratch orgs on the Dev Hub
+func (f *Force) QueryActiveScratchOrgs() ([]ActiveScratchOrg, error) {
+	soql := "SELECT Id, ScratchOrg, ScratchOrgInfoId, SignupUsername, OrgName, ExpirationDate, LastLoginDate FROM ActiveScratchOrg ORDER BY ExpirationDate"
+	var orgs []ActiveScratchOrg
+	if err := f.QueryInto(soql, &orgs); err != nil {
+		return nil, devHubQueryError(err)
+	}
+	return orgs, nil
+}
+
+// DeleteScratchOrg deletes an active scratch org from the Dev Hub
+func (f *Force) DeleteScratchOrg(activeScratchOrgId string) error {
+	if err := f.DeleteRecord("ActiveScratchOrg", activeScratchOrgId); err != nil {
+		return devHubQueryError(err)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package lib
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ScratchOrgDefinition is an sfdx-style scratch org definition, typically
+// read from config/project-scratch-def.json
+type ScratchOrgDefinition struct {
+	OrgName       string                 `json:"orgName"`
+	Edition       string                 `json:"edition"`
+	Country       string                 `json:"country"`
+	Language      string                 `json:"language"`
+	Username      string                 `json:"username"`
+	AdminEmail    string                 `json:"adminEmail"`
+	Description   string                 `json:"description"`
+	Release       string                 `json:"release"`
+	HasSampleData bool                   `json:"hasSampleData"`
+	DurationDays  int                    `json:"durationDays"`
+	Features      ScratchOrgFeatures     `json:"features"`
+	Settings      map[string]interface{} `json:"settings"`
+}
+
+// ScratchOrgFeatures can be given as an array or as a semicolon-separated
+// string in a scratch org definition
+type ScratchOrgFeatures []string
+
+func (features *ScratchOrgFeatures) UnmarshalJSON(data []byte) error {
+	var list []string
+	if err := json.Unmarshal(data, &list); err == nil {
+		*features = list
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("features must be an array or a string")
+	}
+	*features = nil
+	for _, f := range strings.Split(s, ";") {
+		if f = strings.TrimSpace(f); f != "" {
+			*features = append(*features, f)
+		}
+	}
+	return nil
+}
+
+// ParseScratchOrgDefinition parses the contents of a scratch org definition
+// file
+func ParseScratchOrgDefinition(data []byte) (definition ScratchOrgDefinition, err error) {
+	if err = json.Unmarshal(data, &definition); err != nil {
+		err = fmt.Errorf("Invalid scratch org definition: %w", err)
+	}
+	return
+}
+
+// ScratchOrgSettingsMetadata converts the settings in a scratch org
+// definition, e.g. {"lightningExperienceSettings": {"enableS1DesktopEnabled":
+// true}}, to Settings metadata files that can be deployed to the org.
+func ScratchOrgSettingsMetadata(settings map[string]interface{}) (ForceMetadataFiles, error) {
+	files := make(ForceMetadataFiles)
+	var names []string
+	for _, key := range sortedKeys(settings) {
+		values, ok := settings[key].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("Invalid value for %s in settings", key)
+		}
+		elementName := upperFirst(key)
+		name := strings.TrimSuffix(elementName, "Settings")
+		var b strings.Builder
+		b.WriteString(xml.Header)
+		fmt.Fprintf(&b, "<%s xmlns=\"http://soap.sforce.com/2006/04/metadata\">\n", elementName)
+		writeSettingsXml(&b, values, "    ")
+		fmt.Fprintf(&b, "</%s>\n", elementName)
+		files["settings/"+name+".settings"] = []byte(b.String())
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return files, nil
+	}
+	pb := NewPushBuilder()
+	for _, name := range names {
+		pb.AddMetaToPackage("Settings", name)
+	}
+	files["package.xml"] = pb.PackageXml()
+	return files, nil
+}
+
+func writeSettingsXml(b *strings.Builder, values map[string]interface{}, indent string) {
+	for _, key := range sortedKeys(values) {
+		writeSettingsValue(b, key, values[key], indent)
+	}
+}
+
+func writeSettingsValue(b *strings.Builder, name string, value interface{}, indent string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		fmt.Fprintf(b, "%s<%s>\n", indent, name)
+		writeSettingsXml(b, v, indent+"    ")
+		fmt.Fprintf(b, "%s</%s>\n", indent, name)
+	case []interface{}:
+		for _, item := range v {
+			writeSettingsValue(b, name, item, indent)
+		}
+	default:
+		var text strings.Builder
+		xml.EscapeText(&text, []byte(settingsScalar(v)))
+		fmt.Fprintf(b, "%s<%s>%s</%s>\n", indent, name, text.String(), name)
+	}
+}
+
+func settingsScalar(value interface{}) string {
+	switch v := value.(type) {
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case nil:
+		return ""
+	}
+	return fmt.Sprint(value)
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func upperFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	r := []rune(s)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
@@ -0,0 +1,58 @@
+package lib_test
+
+import (
+	"encoding/xml"
+
+	. "github.com/ForceCLI/force/lib"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ScratchOrgDefinition", func() {
+	Describe("ParseScratchOrgDefinition", func() {
+		It("should accept features as an array", func() {
+			definition, err := ParseScratchOrgDefinition([]byte(`{"edition": "Enterprise", "features": ["API", "AuthorApex"]}`))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(definition.Edition).To(Equal("Enterprise"))
+			Expect([]string(definition.Features)).To(Equal([]string{"API", "AuthorApex"}))
+		})
+		It("should accept features as a semicolon-separated string", func() {
+			definition, err := ParseScratchOrgDefinition([]byte(`{"features": "API; AuthorApex;MultiCurrency"}`))
+			Expect(err).ToNot(HaveOccurred())
+			Expect([]string(definition.Features)).To(Equal([]string{"API", "AuthorApex", "MultiCurrency"}))
+		})
+		It("should fail on invalid json", func() {
+			_, err := ParseScratchOrgDefinition([]byte(`{"features": 1}`))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("ScratchOrgSettingsMetadata", func() {
+		It("should convert settings to Settings metadata", func() {
+			definition, err := ParseScratchOrgDefinition([]byte(`{
+				"settings": {
+					"lightningExperienceSettings": {"enableS1DesktopEnabled": true},
+					"securitySettings": {
+						"passwordPolicies": {"minimumPasswordLength": 12},
+						"sessionSettings": {"sessionTimeout": "TwelveHours"}
+					}
+				}
+			}`))
+			Expect(err).ToNot(HaveOccurred())
+			files, err := ScratchOrgSettingsMetadata(definition.Settings)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(files).To(HaveKey("package.xml"))
+			Expect(string(files["settings/LightningExperience.settings"])).To(ContainSubstring(
+				"<LightningExperienceSettings xmlns=\"http://soap.sforce.com/2006/04/metadata\">\n    <enableS1DesktopEnabled>true</enableS1DesktopEnabled>\n</LightningExperienceSettings>"))
+			Expect(string(files["settings/Security.settings"])).To(ContainSubstring(
+				"    <passwordPolicies>\n        <minimumPasswordLength>12</minimumPasswordLength>\n    </passwordPolicies>"))
+
+			var p Package
+			Expect(xml.Unmarshal(files["package.xml"], &p)).To(Succeed())
+			Expect(p.Types).To(HaveLen(1))
+			Expect(p.Types[0].Name).To(Equal("Settings"))
+			Expect(p.Types[0].Members).To(ConsistOf("LightningExperience", "Security"))
+		})
+	})
+})
@@ -0,0 +1,355 @@
+package lib
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	SecurityGrantProfile            = "Profile"
+	SecurityGrantPermissionSet      = "PermissionSet"
+	SecurityGrantPermissionSetGroup = "PermissionSetGroup"
+)
+
+// ObjectAccess is the object-level security granted on an object
+type ObjectAccess struct {
+	Create    bool `json:"create"`
+	Read      bool `json:"read"`
+	Edit      bool `json:"edit"`
+	Delete    bool `json:"delete"`
+	ViewAll   bool `json:"viewAll"`
+	ModifyAll bool `json:"modifyAll"`
+}
+
+// FieldAccess is the field-level security granted on a field
+type FieldAccess struct {
+	Read bool `json:"read"`
+	Edit bool `json:"edit"`
+}
+
+// SecurityGrant is the object and field access granted by a profile,
+// permission set, or permission set group
+type SecurityGrant struct {
+	Id   string `json:"id,omitempty"`
+	Name string `json:"name"`
+	// Type is Profile, PermissionSet, or PermissionSetGroup
+	Type string `json:"type"`
+	// Objects is keyed by object name
+	Objects map[string]ObjectAccess `json:"objects"`
+	// Fields is keyed by Object.Field
+	Fields map[string]FieldAccess `json:"fields"`
+}
+
+// SecurityDifference is a permission that differs between two security
+// grants
+type SecurityDifference struct {
+	Object     string `json:"object"`
+	Field      string `json:"field,omitempty"`
+	Permission string `json:"permission"`
+	A          bool   `json:"a"`
+	B          bool   `json:"b"`
+}
+
+var objectPermissionNames = []string{"Create", "Read", "Edit", "Delete", "View All", "Modify All"}
+
+// ObjectPermissionNames returns the names of the object permissions in
+// display order
+func ObjectPermissionNames() []string {
+	return objectPermissionNames
+}
+
+// Permission returns the object permission with the given name, as returned
+// by ObjectPermissionNames
+func (a ObjectAccess) Permission(name string) bool {
+	switch name {
+	case "Create":
+		return a.Create
+	case "Read":
+		return a.Read
+	case "Edit":
+		return a.Edit
+	case "Delete":
+		return a.Delete
+	case "View All":
+		return a.ViewAll
+	case "Modify All":
+		return a.ModifyAll
+	}
+	return false
+}
+
+func (a ObjectAccess) merge(b ObjectAccess) ObjectAccess {
+	return ObjectAccess{
+		Create:    a.Create || b.Create,
+		Read:      a.Read || b.Read,
+		Edit:      a.Edit || b.Edit,
+		Delete:    a.Delete || b.Delete,
+		ViewAll:   a.ViewAll || b.ViewAll,
+		ModifyAll: a.ModifyAll || b.ModifyAll,
+	}
+}
+
+// String returns Edit, Read, or None
+func (a FieldAccess) String() string {
+	switch {
+	case a.Edit:
+		return "Edit"
+	case a.Read:
+		return "Read"
+	}
+	return "None"
+}
+
+// MergeSecurityGrants combines the access granted by several profiles,
+// permission sets, and permission set groups, such as those assigned to a
+// user, into the effective access
+func MergeSecurityGrants(name string, grants []SecurityGrant) SecurityGrant {
+	merged := SecurityGrant{
+		Name:    name,
+		Objects: make(map[string]ObjectAccess),
+		Fields:  make(map[string]FieldAccess),
+	}
+	for _, g := range grants {
+		for object, access := range g.Objects {
+			merged.Objects[object] = merged.Objects[object].merge(access)
+		}
+		for field, access := range g.Fields {
+			current := merged.Fields[field]
+			merged.Fields[field] = FieldAccess{Read: current.Read || access.Read, Edit: current.Edit || access.Edit}
+		}
+	}
+	return merged
+}
+
+// CompareSecurityGrants returns the object and field permissions that differ
+// between two security grants, sorted by object and field
+func CompareSecurityGrants(a SecurityGrant, b SecurityGrant) []SecurityDifference {
+	var differences []SecurityDifference
+	objects := make(map[string]bool)
+	for o := range a.Objects {
+		objects[o] = true
+	}
+	for o := range b.Objects {
+		objects[o] = true
+	}
+	for o := range objects {
+		for _, name := range objectPermissionNames {
+			if x, y := a.Objects[o].Permission(name), b.Objects[o].Permission(name); x != y {
+				differences = append(differences, SecurityDifference{Object: o, Permission: name, A: x, B: y})
+			}
+		}
+	}
+	fields := make(map[string]bool)
+	for f := range a.Fields {
+		fields[f] = true
+	}
+	for f := range b.Fields {
+		fields[f] = true
+	}
+	for f := range fields {
+		object, field := f, ""
+		if i := strings.Index(f, "."); i >= 0 {
+			object, field = f[:i], f[i+1:]
+		}
+		x, y := a.Fields[f], b.Fields[f]
+		if x.Read != y.Read {
+			differences = append(differences, SecurityDifference{Object: object, Field: field, Permission: "Read", A: x.Read, B: y.Read})
+		}
+		if x.Edit != y.Edit {
+			differences = append(differences, SecurityDifference{Object: object, Field: field, Permission: "Edit", A: x.Edit, B: y.Edit})
+		}
+	}
+	sort.SliceStable(differences, func(i, j int) bool {
+		d, e := differences[i], differences[j]
+		if d.Object != e.Object {
+			return d.Object < e.Object
+		}
+		if d.Field != e.Field {
+			return d.Field < e.Field
+		}
+		return permissionIndex(d.Permission) < permissionIndex(e.Permission)
+	})
+	return differences
+}
+
+func permissionIndex(name string) int {
+	for i, n := range objectPermissionNames {
+		if n == name {
+			return i
+		}
+	}
+	return len(objectPermissionNames)
+}
+
+// QuerySecurityGrants returns the access granted on the given objects, or all
+// objects if none are given, by the profiles, permission sets, and permission
+// set groups matching a SOQL condition on PermissionSet, or all of them if the
+// condition is empty
+func (f *Force) QuerySecurityGrants(condition string, objects []string) ([]SecurityGrant, error) {
+	soql := `SELECT Id, Name, IsOwnedByProfile, Profile.Name, PermissionSetGroupId, PermissionSetGroup.DeveloperName
+	FROM PermissionSet`
+	if condition != "" {
+		soql += " WHERE " + condition
+	}
+	var parents []struct {
+		Id               string
+		Name             string
+		IsOwnedByProfile bool
+		Profile          *struct {
+			Name string
+		}
+		PermissionSetGroupId string
+		PermissionSetGroup   *struct {
+			DeveloperName string
+		}
+	}
+	if err := f.QueryInto(soql, &parents); err != nil {
+		return nil, fmt.Errorf("Could not query permission sets: %w", err)
+	}
+	if len(parents) == 0 {
+		return nil, nil
+	}
+
+	grants := make(map[string]*SecurityGrant)
+	var ids []string
+	for _, p := range parents {
+		g := &SecurityGrant{
+			Id:      p.Id,
+			Name:    p.Name,
+			Type:    SecurityGrantPermissionSet,
+			Objects: make(map[string]ObjectAccess),
+			Fields:  make(map[string]FieldAccess),
+		}
+		switch {
+		case p.IsOwnedByProfile && p.Profile != nil:
+			g.Name = p.Profile.Name
+			g.Type = SecurityGrantProfile
+		case p.PermissionSetGroupId != "" && p.PermissionSetGroup != nil:
+			g.Name = p.PermissionSetGroup.DeveloperName
+			g.Type = SecurityGrantPermissionSetGroup
+		}
+		grants[p.Id] = g
+		ids = append(ids, p.Id)
+	}
+
+	var filters []string
+	if condition != "" {
+		filters = append(filters, fmt.Sprintf("ParentId IN ('%s')", strings.Join(ids, "','")))
+	}
+	if len(objects) > 0 {
+		filters = append(filters, fmt.Sprintf("SobjectType IN ('%s')", strings.Join(objects, "','")))
+	}
+	where := ""
+	if len(filters) > 0 {
+		where = " WHERE " + strings.Join(filters, " AND ")
+	}
+
+	var objectPermissions []struct {
+		ParentId                    string
+		SobjectType                 string
+		PermissionsCreate           bool
+		PermissionsRead             bool
+		PermissionsEdit             bool
+		PermissionsDelete           bool
+		PermissionsViewAllRecords   bool
+		PermissionsModifyAllRecords bool
+	}
+	soql = `SELECT ParentId, SobjectType, PermissionsCreate, PermissionsRead, PermissionsEdit, PermissionsDelete,
+	PermissionsViewAllRecords, PermissionsModifyAllRecords
+	FROM ObjectPermissions` + where
+	if err := f.QueryInto(soql, &objectPermissions); err != nil {
+		return nil, fmt.Errorf("Could not query object permissions: %w", err)
+	}
+	for _, p := range objectPermissions {
+		if g, ok := grants[p.ParentId]; ok {
+			g.Objects[p.SobjectType] = ObjectAccess{
+				Create:    p.PermissionsCreate,
+				Read:      p.PermissionsRead,
+				Edit:      p.PermissionsEdit,
+				Delete:    p.PermissionsDelete,
+				ViewAll:   p.PermissionsViewAllRecords,
+				ModifyAll: p.PermissionsModifyAllRecords,
+			}
+		}
+	}
+
+	var fieldPermissions []struct {
+		ParentId        string
+		Field           string
+		PermissionsRead bool
+		PermissionsEdit bool
+	}
+	soql = "SELECT ParentId, Field, PermissionsRead, PermissionsEdit FROM FieldPermissions" + where
+	if err := f.QueryInto(soql, &fieldPermissions); err != nil {
+		return nil, fmt.Errorf("Could not query field permissions: %w", err)
+	}
+	for _, p := range fieldPermissions {
+		if g, ok := grants[p.ParentId]; ok {
+			g.Fields[p.Field] = FieldAccess{Read: p.PermissionsRead, Edit: p.PermissionsEdit}
+		}
+	}
+
+	var results []SecurityGrant
+	for _, id := range ids {
+		results = append(results, *grants[id])
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Type != results[j].Type {
+			return results[i].Type < results[j].Type
+		}
+		return results[i].Name < results[j].Name
+	})
+	return results, nil
+}
+
+// SecurityGrantCondition returns the SOQL condition on PermissionSet matching
+// the profile, permission set, or permission set group with the given name
+func SecurityGrantCondition(name string) string {
+	quoted := soqlQuote(name)
+	return fmt.Sprintf("(IsOwnedByProfile = true AND Profile.Name = %[1]s) OR "+
+		"(IsOwnedByProfile = false AND PermissionSetGroupId = null AND Name = %[1]s) OR "+
+		"PermissionSetGroup.DeveloperName = %[1]s", quoted)
+}
+
+// GetSecurityGrant returns the access granted on the given objects, or all
+// objects if none are given, by the profile, permission set, or permission set
+// group with the given name
+func (f *Force) GetSecurityGrant(name string, objects []string) (SecurityGrant, error) {
+	grants, err := f.QuerySecurityGrants(SecurityGrantCondition(name), objects)
+	if err != nil {
+		return SecurityGrant{}, err
+	}
+	switch len(grants) {
+	case 0:
+		return SecurityGrant{}, fmt.Errorf("No profile, permission set, or permission set group named %s", name)
+	case 1:
+		return grants[0], nil
+	}
+	return SecurityGrant{}, fmt.Errorf("%s matches more than one profile, permission set, or permission set group", name)
+}
+
+// UserSecurityGrant returns the effective access on the given objects, or all
+// objects if none are given, granted to a user by their profile, permission
+// sets, and permission set groups
+func (f *Force) UserSecurityGrant(username string, objects []string) (SecurityGrant, error) {
+	var assignments []struct {
+		PermissionSetId string
+	}
+	soql := fmt.Sprintf("SELECT PermissionSetId FROM PermissionSetAssignment WHERE Assignee.Username = %s", soqlQuote(username))
+	if err := f.QueryInto(soql, &assignments); err != nil {
+		return SecurityGrant{}, fmt.Errorf("Could not query permission set assignments: %w", err)
+	}
+	if len(assignments) == 0 {
+		return SecurityGrant{}, fmt.Errorf("No permission sets assigned to %s", username)
+	}
+	var ids []string
+	for _, a := range assignments {
+		ids = append(ids, a.PermissionSetId)
+	}
+	grants, err := f.QuerySecurityGrants(fmt.Sprintf("Id IN ('%s')", strings.Join(ids, "','")), objects)
+	if err != nil {
+		return SecurityGrant{}, err
+	}
+	return MergeSecurityGrants(username, grants), nil
+}
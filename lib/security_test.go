@@ -0,0 +1,59 @@
+package lib_test
+
+import (
+	. "github.com/ForceCLI/force/lib"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Security", func() {
+	profile := SecurityGrant{
+		Name:    "Standard User",
+		Type:    SecurityGrantProfile,
+		Objects: map[string]ObjectAccess{"Case": {Read: true}},
+		Fields:  map[string]FieldAccess{"Case.Subject": {Read: true}},
+	}
+	permissionSet := SecurityGrant{
+		Name: "Support",
+		Type: SecurityGrantPermissionSet,
+		Objects: map[string]ObjectAccess{
+			"Case":    {Read: true, Create: true, Edit: true},
+			"Account": {Read: true},
+		},
+		Fields: map[string]FieldAccess{
+			"Case.Subject":  {Read: true, Edit: true},
+			"Case.Priority": {Read: true},
+		},
+	}
+
+	It("should merge access into the effective access", func() {
+		merged := MergeSecurityGrants("jane@example.com", []SecurityGrant{profile, permissionSet})
+		Expect(merged.Name).To(Equal("jane@example.com"))
+		Expect(merged.Objects["Case"]).To(Equal(ObjectAccess{Read: true, Create: true, Edit: true}))
+		Expect(merged.Objects["Account"]).To(Equal(ObjectAccess{Read: true}))
+		Expect(merged.Fields["Case.Subject"].String()).To(Equal("Edit"))
+		Expect(merged.Fields["Case.Priority"].String()).To(Equal("Read"))
+	})
+
+	It("should list differing object and field permissions", func() {
+		differences := CompareSecurityGrants(profile, permissionSet)
+		Expect(differences).To(Equal([]SecurityDifference{
+			{Object: "Account", Permission: "Read", A: false, B: true},
+			{Object: "Case", Permission: "Create", A: false, B: true},
+			{Object: "Case", Permission: "Edit", A: false, B: true},
+			{Object: "Case", Field: "Priority", Permission: "Read", A: false, B: true},
+			{Object: "Case", Field: "Subject", Permission: "Edit", A: false, B: true},
+		}))
+	})
+
+	It("should report no differences for identical access", func() {
+		Expect(CompareSecurityGrants(profile, profile)).To(BeEmpty())
+	})
+
+	It("should match profiles, permission sets, and groups by name", func() {
+		condition := SecurityGrantCondition("Owner's Profile")
+		Expect(condition).To(ContainSubstring(`Profile.Name = 'Owner\'s Profile'`))
+		Expect(condition).To(ContainSubstring(`PermissionSetGroup.DeveloperName = 'Owner\'s Profile'`))
+	})
+})
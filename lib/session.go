@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"net/url"
 
@@ -13,7 +14,9 @@ import (
 var SessionRefreshError = errors.New("Failed to refresh session.  Please run `force login`.")
 var SessionRefreshUnavailable = errors.New("Unable to refresh.  Please run `force login`.")
 
-func (f *Force) refreshOauth() (err error) {
+// refreshedOauthSession gets a new access token using the refresh token,
+// without saving it
+func (f *Force) refreshedOauthSession() (result ForceSession, err error) {
 	attrs := url.Values{}
 	attrs.Set("grant_type", "refresh_token")
 	attrs.Set("refresh_token", f.Credentials.RefreshToken)
@@ -21,6 +24,9 @@ func (f *Force) refreshOauth() (err error) {
 	if f.Credentials.ClientId != "" {
 		attrs.Set("client_id", f.Credentials.ClientId)
 	}
+	if f.Credentials.ClientSecret != "" {
+		attrs.Set("client_secret", f.Credentials.ClientSecret)
+	}
 	attrs.Set("format", "json")
 
 	postVars := attrs.Encode()
@@ -38,31 +44,58 @@ func (f *Force) refreshOauth() (err error) {
 	defer res.Body.Close()
 	body, err := ioutil.ReadAll(res.Body)
 	if res.StatusCode != 200 {
-		err = SessionRefreshError
+		err = refreshTokenError(body, res.StatusCode)
 		return
 	}
 	if err != nil {
 		return
 	}
 
-	var result ForceSession
 	json.Unmarshal(body, &result)
-	f.UpdateCredentials(result)
+	// Connected apps with refresh token rotation issue a new refresh token
+	var token oauthTokenResponse
+	json.Unmarshal(body, &token)
+	result.RefreshToken = token.RefreshToken
 	return
 }
 
-func (f *Force) refreshSFDX() (err error) {
+// refreshedSFDXSession gets a new access token from SFDX, without saving it
+func (f *Force) refreshedSFDXSession() (ForceSession, error) {
 	Log.Info("Refreshing Session Token Using SFDX")
+	refreshed, err := RefreshSFDXAuth(f.Credentials.UserInfo.UserName)
+	switch {
+	case err == nil:
+		return ForceSession{
+			AccessToken: refreshed.AccessToken,
+			InstanceUrl: refreshed.InstanceUrl,
+		}, nil
+	case errors.Is(err, SessionRefreshError):
+		return ForceSession{}, err
+	}
+	Log.Info(fmt.Sprintf("Could not refresh using SFDX auth files: %s", err.Error()))
 	sfdxAuth, err := GetSFDXAuth(f.Credentials.UserInfo.UserName)
 	if err != nil {
-		return
+		return ForceSession{}, err
 	}
-	newCreds := ForceSession{
+	return ForceSession{
 		AccessToken: sfdxAuth.AccessToken,
 		InstanceUrl: sfdxAuth.InstanceUrl,
+	}, nil
+}
+
+// refreshedSession gets a new access token using the session's
+// RefreshMethod, without saving it
+func (f *Force) refreshedSession() (ForceSession, error) {
+	if f.Credentials.SessionOptions == nil {
+		return ForceSession{}, SessionRefreshUnavailable
 	}
-	f.UpdateCredentials(newCreds)
-	return
+	switch f.Credentials.SessionOptions.RefreshMethod {
+	case RefreshOauth:
+		return f.refreshedOauthSession()
+	case RefreshSFDX:
+		return f.refreshedSFDXSession()
+	}
+	return ForceSession{}, SessionRefreshUnavailable
 }
 
 func (f *Force) RefreshSessionOrExit() {
@@ -73,15 +106,13 @@ func (f *Force) RefreshSessionOrExit() {
 }
 
 func (f *Force) RefreshSession() error {
-	if f.Credentials.SessionOptions == nil {
-		return SessionRefreshUnavailable
-	}
-	if f.Credentials.SessionOptions.RefreshFunc != nil {
+	if f.Credentials.SessionOptions != nil && f.Credentials.SessionOptions.RefreshFunc != nil {
 		return f.Credentials.SessionOptions.RefreshFunc(f)
-	} else if f.Credentials.SessionOptions.RefreshMethod == RefreshOauth {
-		return f.refreshOauth()
-	} else if f.Credentials.SessionOptions.RefreshMethod == RefreshSFDX {
-		return f.refreshSFDX()
 	}
-	return SessionRefreshUnavailable
+	session, err := f.refreshedSession()
+	if err != nil {
+		return err
+	}
+	f.UpdateCredentials(session)
+	return nil
 }
@@ -1,10 +1,18 @@
 package lib
 
 import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/hex"
 	"encoding/json"
-	. "github.com/ForceCLI/force/error"
+	"errors"
+	"fmt"
+	"net/url"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
 )
 
 type SFDXAuth struct {
@@ -22,6 +30,25 @@ type SFDXAuth struct {
 	Username    string
 }
 
+// sfdxAuthFile is the auth info stored by the sf CLI in ~/.sfdx/<username>.json.
+// The access and refresh tokens are encrypted.
+type sfdxAuthFile struct {
+	AccessToken  string `json:"accessToken"`
+	RefreshToken string `json:"refreshToken"`
+	InstanceUrl  string `json:"instanceUrl"`
+	LoginUrl     string `json:"loginUrl"`
+	OrgId        string `json:"orgId"`
+	Username     string `json:"username"`
+	ClientId     string `json:"clientId"`
+	ClientSecret string `json:"clientSecret"`
+}
+
+// The client id used by the sf CLI when the org wasn't authorized with a
+// custom connected app
+const sfdxDefaultClientId = "PlatformCLI"
+
+var SFDXAuthNotFoundError = errors.New("SFDX auth info not found")
+
 func UseSFDXSession(authData SFDXAuth) {
 	creds := ForceSession{
 		AccessToken: authData.AccessToken,
@@ -39,8 +66,20 @@ func UseSFDXSession(authData SFDXAuth) {
 	ForceSaveLogin(creds, os.Stderr)
 }
 
+// GetSFDXAuth returns the auth info for an sf CLI username or alias.  The
+// auth files are read directly, falling back to running `sfdx
+// force:org:display` if they can't be read.
 func GetSFDXAuth(user string) (auth SFDXAuth, err error) {
 	Log.Info("Getting SFDX AUTH FOR " + user)
+	auth, err = ReadSFDXAuth(user)
+	if err == nil {
+		return
+	}
+	Log.Info(fmt.Sprintf("Could not read SFDX auth files: %s.  Running sfdx.", err.Error()))
+	return getSFDXAuthFromCommand(user)
+}
+
+func getSFDXAuthFromCommand(user string) (auth SFDXAuth, err error) {
 	cmd := exec.Command("sfdx", "force:org:display", "-u"+user, "--json")
 
 	stdout, err := cmd.StdoutPipe()
@@ -56,12 +95,263 @@ func GetSFDXAuth(user string) (auth SFDXAuth, err error) {
 		Result SFDXAuth
 	}
 	var aData authData
-	if err := json.NewDecoder(stdout).Decode(&aData); err != nil {
-		ErrorAndExit(err.Error())
+	if err = json.NewDecoder(stdout).Decode(&aData); err != nil {
+		return
 	}
-	if err := cmd.Wait(); err != nil {
-		ErrorAndExit(err.Error())
+	if err = cmd.Wait(); err != nil {
+		return
 	}
 	auth = aData.Result
 	return
 }
+
+// ReadSFDXAuth reads the auth info for an sf CLI username or alias from the
+// sf CLI's auth files, decrypting the access token
+func ReadSFDXAuth(user string) (auth SFDXAuth, err error) {
+	username := ResolveSFDXAlias(user)
+	authFile, err := readSFDXAuthFile(username)
+	if err != nil {
+		return
+	}
+	key, err := sfdxKey()
+	if err != nil {
+		return
+	}
+	accessToken, err := decryptSFDXValue(key, authFile.AccessToken)
+	if err != nil {
+		return
+	}
+	auth = SFDXAuth{
+		AccessToken: accessToken,
+		ClientId:    authFile.ClientId,
+		Id:          authFile.OrgId,
+		InstanceUrl: authFile.InstanceUrl,
+		Username:    authFile.Username,
+	}
+	if username != user {
+		auth.Alias = user
+	}
+	return
+}
+
+// RefreshSFDXAuth gets a new access token using the refresh token stored by
+// the sf CLI for a username or alias
+func RefreshSFDXAuth(user string) (creds ForceSession, err error) {
+	authFile, err := readSFDXAuthFile(ResolveSFDXAlias(user))
+	if err != nil {
+		return
+	}
+	if authFile.RefreshToken == "" {
+		return creds, fmt.Errorf("No refresh token found for %s", user)
+	}
+	key, err := sfdxKey()
+	if err != nil {
+		return
+	}
+	refreshToken, err := decryptSFDXValue(key, authFile.RefreshToken)
+	if err != nil {
+		return
+	}
+	clientId := authFile.ClientId
+	if clientId == "" {
+		clientId = sfdxDefaultClientId
+	}
+	attrs := url.Values{}
+	attrs.Set("grant_type", "refresh_token")
+	attrs.Set("refresh_token", refreshToken)
+	attrs.Set("client_id", clientId)
+	if authFile.ClientSecret != "" {
+		clientSecret, err := decryptSFDXValue(key, authFile.ClientSecret)
+		if err != nil {
+			return creds, err
+		}
+		attrs.Set("client_secret", clientSecret)
+	}
+	body, status, err := oauthTokenRequest(authFile.InstanceUrl, attrs)
+	if err != nil {
+		return
+	}
+	if status != 200 {
+		err = refreshTokenError(body, status)
+		return
+	}
+	if err = json.Unmarshal(body, &creds); err != nil {
+		err = fmt.Errorf("Could not parse token response: %w", err)
+	}
+	return
+}
+
+func sfdxStateDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".sfdx"), nil
+}
+
+func readSFDXAuthFile(username string) (authFile sfdxAuthFile, err error) {
+	dir, err := sfdxStateDir()
+	if err != nil {
+		return
+	}
+	data, err := os.ReadFile(filepath.Join(dir, username+".json"))
+	if os.IsNotExist(err) {
+		return authFile, SFDXAuthNotFoundError
+	}
+	if err != nil {
+		return
+	}
+	if err = json.Unmarshal(data, &authFile); err != nil {
+		return authFile, fmt.Errorf("Could not parse SFDX auth file for %s: %w", username, err)
+	}
+	return
+}
+
+// ResolveSFDXAlias returns the username for an sf CLI alias, or the argument
+// unchanged if it isn't an alias
+func ResolveSFDXAlias(alias string) string {
+	dir, err := sfdxStateDir()
+	if err != nil {
+		return alias
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "alias.json"))
+	if err != nil {
+		return alias
+	}
+	var aliases struct {
+		Orgs map[string]string `json:"orgs"`
+	}
+	if err = json.Unmarshal(data, &aliases); err != nil {
+		return alias
+	}
+	if username, ok := aliases.Orgs[alias]; ok {
+		return username
+	}
+	return alias
+}
+
+// SFDXDefaultUsername returns the default username, or default Dev Hub
+// username if devHub is true, from the sf CLI config in the current
+// directory or the global config
+func SFDXDefaultUsername(devHub bool) (string, error) {
+	sfdxConfigKey, sfConfigKey := "defaultusername", "target-org"
+	if devHub {
+		sfdxConfigKey, sfConfigKey = "defaultdevhubusername", "target-dev-hub"
+	}
+	var dirs []string
+	if cwd, err := os.Getwd(); err == nil {
+		dirs = append(dirs, cwd)
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, home)
+	}
+	for _, dir := range dirs {
+		configs := []struct {
+			path string
+			key  string
+		}{
+			{filepath.Join(dir, ".sf", "config.json"), sfConfigKey},
+			{filepath.Join(dir, ".sfdx", "sfdx-config.json"), sfdxConfigKey},
+		}
+		for _, c := range configs {
+			data, err := os.ReadFile(c.path)
+			if err != nil {
+				continue
+			}
+			var config map[string]interface{}
+			if err = json.Unmarshal(data, &config); err != nil {
+				continue
+			}
+			if username, ok := config[c.key].(string); ok && username != "" {
+				return username, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("No default username found in SFDX config")
+}
+
+// sfdxKey returns the key used by the sf CLI to encrypt tokens, from
+// ~/.sfdx/key.json if the generic keychain is used, or from the OS keychain
+func sfdxKey() (string, error) {
+	dir, err := sfdxStateDir()
+	if err != nil {
+		return "", err
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "key.json")); err == nil {
+		var keyFile struct {
+			Key string `json:"key"`
+		}
+		if err = json.Unmarshal(data, &keyFile); err != nil {
+			return "", fmt.Errorf("Could not parse SFDX key file: %w", err)
+		}
+		return keyFile.Key, nil
+	}
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("security", "find-generic-password", "-a", "local", "-s", "sfdx", "-w")
+	case "linux":
+		cmd = exec.Command("secret-tool", "lookup", "user", "local", "domain", "sfdx")
+	default:
+		return "", fmt.Errorf("SFDX key not found")
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("Could not read SFDX key from keychain: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// decryptSFDXValue decrypts a value encrypted by the sf CLI using AES-256-GCM.
+// Encrypted values are <iv><ciphertext>:<tag>, hex-encoded.  Older keys are
+// 32 hex characters used as-is along with a 12 character hex IV; newer keys
+// are 64 hex characters decoded to 32 bytes along with a 12 byte IV.
+func decryptSFDXValue(key string, value string) (string, error) {
+	tokens := strings.Split(value, ":")
+	if len(tokens) != 2 {
+		// Not encrypted
+		return value, nil
+	}
+	var keyBytes, iv []byte
+	var ciphertext string
+	switch len(key) {
+	case 32:
+		if len(tokens[0]) < 12 {
+			return "", fmt.Errorf("Invalid SFDX encrypted value")
+		}
+		keyBytes = []byte(key)
+		iv = []byte(tokens[0][:12])
+		ciphertext = tokens[0][12:]
+	case 64:
+		var err error
+		if keyBytes, err = hex.DecodeString(key); err != nil {
+			return "", fmt.Errorf("Invalid SFDX key: %w", err)
+		}
+		if len(tokens[0]) < 24 {
+			return "", fmt.Errorf("Invalid SFDX encrypted value")
+		}
+		if iv, err = hex.DecodeString(tokens[0][:24]); err != nil {
+			return "", fmt.Errorf("Invalid SFDX encrypted value: %w", err)
+		}
+		ciphertext = tokens[0][24:]
+	default:
+		return "", fmt.Errorf("Invalid SFDX key")
+	}
+	sealed, err := hex.DecodeString(ciphertext + tokens[1])
+	if err != nil {
+		return "", fmt.Errorf("Invalid SFDX encrypted value: %w", err)
+	}
+	block, err := aes.NewCipher(keyBytes)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := cipher.NewGCMWithNonceSize(block, len(iv))
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := gcm.Open(nil, iv, sealed, nil)
+	if err != nil {
+		return "", fmt.Errorf("Could not decrypt SFDX value: %w", err)
+	}
+	return string(plaintext), nil
+}
@@ -0,0 +1,110 @@
+package lib_test
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	. "github.com/ForceCLI/force/lib"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// encryptSFDXValue encrypts a value the way the sf CLI does with a 32 byte key
+func encryptSFDXValue(key []byte, iv []byte, value string) string {
+	block, err := aes.NewCipher(key)
+	Expect(err).ToNot(HaveOccurred())
+	gcm, err := cipher.NewGCMWithNonceSize(block, len(iv))
+	Expect(err).ToNot(HaveOccurred())
+	sealed := gcm.Seal(nil, iv, []byte(value), nil)
+	ciphertext, tag := sealed[:len(sealed)-gcm.Overhead()], sealed[len(sealed)-gcm.Overhead():]
+	return hex.EncodeToString(iv) + hex.EncodeToString(ciphertext) + ":" + hex.EncodeToString(tag)
+}
+
+var _ = Describe("SFDX", func() {
+	var home, originalHome string
+	key := []byte("0123456789abcdef0123456789abcdef")
+	iv := []byte("123456789012")
+
+	writeFile := func(name string, contents string) {
+		Expect(os.WriteFile(filepath.Join(home, ".sfdx", name), []byte(contents), 0600)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		var err error
+		home, err = os.MkdirTemp("", "sfdx")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(os.Mkdir(filepath.Join(home, ".sfdx"), 0700)).To(Succeed())
+		originalHome = os.Getenv("HOME")
+		os.Setenv("HOME", home)
+		writeFile("key.json", `{"service":"sfdx","account":"local","key":"`+hex.EncodeToString(key)+`"}`)
+		writeFile("alias.json", `{"orgs":{"dev":"user@example.com"}}`)
+	})
+
+	AfterEach(func() {
+		os.Setenv("HOME", originalHome)
+		os.RemoveAll(home)
+	})
+
+	It("should read auth info for an alias", func() {
+		writeFile("user@example.com.json", `{
+			"accessToken": "`+encryptSFDXValue(key, iv, "00Dxx!token")+`",
+			"instanceUrl": "https://example.my.salesforce.com",
+			"orgId": "00Dxx0000000001",
+			"username": "user@example.com"
+		}`)
+		auth, err := ReadSFDXAuth("dev")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(auth).To(Equal(SFDXAuth{
+			AccessToken: "00Dxx!token",
+			Alias:       "dev",
+			Id:          "00Dxx0000000001",
+			InstanceUrl: "https://example.my.salesforce.com",
+			Username:    "user@example.com",
+		}))
+	})
+
+	It("should decrypt tokens encrypted with a legacy key", func() {
+		legacyKey := "0123456789abcdef0123456789abcdef"
+		legacyIv := "a1b2c3d4e5f6"
+		value := encryptSFDXValue([]byte(legacyKey), []byte(legacyIv), "00Dxx!legacy")
+		// Legacy IVs are stored as-is rather than hex-encoded
+		value = legacyIv + value[len(hex.EncodeToString([]byte(legacyIv))):]
+		writeFile("key.json", `{"key":"`+legacyKey+`"}`)
+		writeFile("user@example.com.json", `{"accessToken":"`+value+`","username":"user@example.com"}`)
+		auth, err := ReadSFDXAuth("user@example.com")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(auth.AccessToken).To(Equal("00Dxx!legacy"))
+		Expect(auth.Alias).To(BeEmpty())
+	})
+
+	It("should return an error if the org isn't authorized", func() {
+		_, err := ReadSFDXAuth("other@example.com")
+		Expect(err).To(Equal(SFDXAuthNotFoundError))
+	})
+
+	It("should refresh the session using the stored refresh token", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.ParseForm()
+			Expect(r.Form.Get("grant_type")).To(Equal("refresh_token"))
+			Expect(r.Form.Get("refresh_token")).To(Equal("5Aep!refresh"))
+			Expect(r.Form.Get("client_id")).To(Equal("PlatformCLI"))
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"access_token":"00Dxx!new","instance_url":"https://example.my.salesforce.com"}`))
+		}))
+		defer server.Close()
+		writeFile("user@example.com.json", `{
+			"refreshToken": "`+encryptSFDXValue(key, iv, "5Aep!refresh")+`",
+			"instanceUrl": "`+server.URL+`",
+			"username": "user@example.com"
+		}`)
+		creds, err := RefreshSFDXAuth("dev")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(creds.AccessToken).To(Equal("00Dxx!new"))
+	})
+})
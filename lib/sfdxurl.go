@@ -0,0 +1,147 @@
+package lib
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+const sfdxAuthUrlScheme = "force://"
+
+// SfdxAuthUrl is an SFDX auth URL, as used by `sf org login sfdx-url`, in the
+// form force://<clientId>:<clientSecret>:<refreshToken>@<instance>.  The
+// client secret is usually empty.
+type SfdxAuthUrl struct {
+	ClientId     string
+	ClientSecret string
+	RefreshToken string
+	InstanceUrl  string
+}
+
+// ParseSfdxAuthUrl parses an SFDX auth URL
+func ParseSfdxAuthUrl(authUrl string) (u SfdxAuthUrl, err error) {
+	authUrl = strings.TrimSpace(authUrl)
+	if !strings.HasPrefix(authUrl, sfdxAuthUrlScheme) {
+		return u, fmt.Errorf("Invalid SFDX auth URL: must start with %s", sfdxAuthUrlScheme)
+	}
+	rest := strings.TrimPrefix(authUrl, sfdxAuthUrlScheme)
+	at := strings.LastIndex(rest, "@")
+	if at < 0 {
+		return u, fmt.Errorf("Invalid SFDX auth URL: missing instance")
+	}
+	parts := strings.SplitN(rest[:at], ":", 3)
+	if len(parts) != 3 || parts[0] == "" || parts[2] == "" {
+		return u, fmt.Errorf("Invalid SFDX auth URL: expected force://<clientId>:<clientSecret>:<refreshToken>@<instance>")
+	}
+	instance := strings.TrimSuffix(rest[at+1:], "/")
+	if instance == "" {
+		return u, fmt.Errorf("Invalid SFDX auth URL: missing instance")
+	}
+	if !strings.Contains(instance, "://") {
+		instance = "https://" + instance
+	}
+	u = SfdxAuthUrl{
+		ClientId:     parts[0],
+		ClientSecret: parts[1],
+		RefreshToken: parts[2],
+		InstanceUrl:  instance,
+	}
+	return
+}
+
+// ReadSfdxAuthUrlFile reads an SFDX auth URL from a file containing either the
+// URL itself or the JSON output of `sf org display --verbose --json`
+func ReadSfdxAuthUrlFile(filename string) (u SfdxAuthUrl, err error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return
+	}
+	contents := strings.TrimSpace(string(data))
+	if strings.HasPrefix(contents, "{") {
+		var display struct {
+			SfdxAuthUrl string `json:"sfdxAuthUrl"`
+			Result      struct {
+				SfdxAuthUrl string `json:"sfdxAuthUrl"`
+			} `json:"result"`
+		}
+		if err = json.Unmarshal(data, &display); err != nil {
+			return u, fmt.Errorf("Could not parse %s: %w", filename, err)
+		}
+		contents = display.SfdxAuthUrl
+		if contents == "" {
+			contents = display.Result.SfdxAuthUrl
+		}
+		if contents == "" {
+			return u, fmt.Errorf("No sfdxAuthUrl found in %s", filename)
+		}
+	}
+	return ParseSfdxAuthUrl(contents)
+}
+
+// String returns the SFDX auth URL
+func (u SfdxAuthUrl) String() string {
+	instance := strings.TrimPrefix(u.InstanceUrl, "https://")
+	return fmt.Sprintf("%s%s:%s:%s@%s", sfdxAuthUrlScheme, u.ClientId, u.ClientSecret, u.RefreshToken, instance)
+}
+
+// SfdxAuthUrlForSession returns the SFDX auth URL for a saved session.  Only
+// sessions with a refresh token can be exported.
+func SfdxAuthUrlForSession(creds ForceSession) (u SfdxAuthUrl, err error) {
+	if creds.RefreshToken == "" {
+		return u, fmt.Errorf("Login has no refresh token.  Log in with OAuth to export it.")
+	}
+	clientId := creds.ClientId
+	if clientId == "" {
+		clientId = ClientId
+	}
+	u = SfdxAuthUrl{
+		ClientId:     clientId,
+		ClientSecret: creds.ClientSecret,
+		RefreshToken: creds.RefreshToken,
+		InstanceUrl:  creds.InstanceUrl,
+	}
+	return
+}
+
+// LoginWithSfdxAuthUrl gets a session using the refresh token from an SFDX
+// auth URL
+func LoginWithSfdxAuthUrl(u SfdxAuthUrl) (creds ForceSession, err error) {
+	attrs := url.Values{}
+	attrs.Set("grant_type", "refresh_token")
+	attrs.Set("refresh_token", u.RefreshToken)
+	attrs.Set("client_id", u.ClientId)
+	if u.ClientSecret != "" {
+		attrs.Set("client_secret", u.ClientSecret)
+	}
+	body, status, err := oauthTokenRequest(u.InstanceUrl, attrs)
+	if err != nil {
+		return
+	}
+	if status != 200 {
+		err = fmt.Errorf("Could not log in with SFDX auth URL: %w", oauthError(body, status))
+		return
+	}
+	creds, err = sessionFromTokenResponse(body, u.InstanceUrl, u.ClientId)
+	if err != nil {
+		return
+	}
+	// The refresh token is only returned if the connected app rotates it
+	if creds.RefreshToken == "" {
+		creds.RefreshToken = u.RefreshToken
+	}
+	creds.SessionOptions.RefreshMethod = RefreshOauth
+	creds.SessionOptions.SharedRefreshToken = true
+	creds.ClientSecret = u.ClientSecret
+	return
+}
+
+func ForceLoginAndSaveSfdxAuthUrl(u SfdxAuthUrl, output *os.File) (username string, err error) {
+	creds, err := LoginWithSfdxAuthUrl(u)
+	if err != nil {
+		return
+	}
+	username, err = ForceSaveLogin(creds, output)
+	return
+}
@@ -0,0 +1,320 @@
+package lib
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SchemaSnapshot records the describe results of an org's objects so they can
+// be compared later
+type SchemaSnapshot struct {
+	Username    string           `json:"username,omitempty"`
+	InstanceUrl string           `json:"instanceUrl,omitempty"`
+	CreatedDate string           `json:"createdDate"`
+	Objects     []SnapshotObject `json:"objects"`
+}
+
+// SnapshotObject is an object in a schema snapshot
+type SnapshotObject struct {
+	Name   string          `json:"name"`
+	Label  string          `json:"label"`
+	Custom bool            `json:"custom"`
+	Fields []SnapshotField `json:"fields"`
+}
+
+// SnapshotField is a field in a schema snapshot
+type SnapshotField struct {
+	Name           string   `json:"name"`
+	Label          string   `json:"label"`
+	Type           string   `json:"type"`
+	Length         int      `json:"length,omitempty"`
+	Precision      int      `json:"precision,omitempty"`
+	Scale          int      `json:"scale,omitempty"`
+	Nillable       bool     `json:"nillable"`
+	Unique         bool     `json:"unique,omitempty"`
+	ExternalId     bool     `json:"externalId,omitempty"`
+	Formula        string   `json:"formula,omitempty"`
+	ReferenceTo    []string `json:"referenceTo,omitempty"`
+	PicklistValues []string `json:"picklistValues,omitempty"`
+}
+
+// SchemaDrift is a difference between two schema snapshots
+type SchemaDrift struct {
+	// Change is added, removed, or changed
+	Change      string
+	Object      string
+	Field       string
+	Differences []string
+}
+
+func (d SchemaDrift) String() string {
+	symbol := map[string]string{"added": "+", "removed": "-", "changed": "~"}[d.Change]
+	name := d.Object
+	if d.Field != "" {
+		name += "." + d.Field
+	}
+	s := fmt.Sprintf("%s %s %s", symbol, name, d.Change)
+	for _, difference := range d.Differences {
+		s += "\n    " + difference
+	}
+	return s
+}
+
+// NewSnapshotObject extracts the fields recorded in a snapshot from an
+// object's describe result
+func NewSnapshotObject(sobject ForceSobject) SnapshotObject {
+	o := SnapshotObject{}
+	o.Name, _ = sobject["name"].(string)
+	o.Label, _ = sobject["label"].(string)
+	o.Custom, _ = sobject["custom"].(bool)
+	fields, _ := sobject["fields"].([]interface{})
+	for _, f := range fields {
+		field, ok := f.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		num := func(key string) int {
+			n, _ := field[key].(float64)
+			return int(n)
+		}
+		sf := SnapshotField{
+			Length:    num("length"),
+			Precision: num("precision"),
+			Scale:     num("scale"),
+		}
+		sf.Name, _ = field["name"].(string)
+		sf.Label, _ = field["label"].(string)
+		sf.Type, _ = field["type"].(string)
+		sf.Nillable, _ = field["nillable"].(bool)
+		sf.Unique, _ = field["unique"].(bool)
+		sf.ExternalId, _ = field["externalId"].(bool)
+		sf.Formula, _ = field["calculatedFormula"].(string)
+		references, _ := field["referenceTo"].([]interface{})
+		for _, r := range references {
+			sf.ReferenceTo = append(sf.ReferenceTo, fmt.Sprint(r))
+		}
+		values, _ := field["picklistValues"].([]interface{})
+		for _, v := range values {
+			if value, ok := v.(map[string]interface{}); ok {
+				if active, _ := value["active"].(bool); active {
+					sf.PicklistValues = append(sf.PicklistValues, fmt.Sprint(value["value"]))
+				}
+			}
+		}
+		o.Fields = append(o.Fields, sf)
+	}
+	sort.Slice(o.Fields, func(i, j int) bool { return o.Fields[i].Name < o.Fields[j].Name })
+	return o
+}
+
+// TakeSchemaSnapshot describes the given objects, or all objects if none are
+// given, and records them in a snapshot.  Objects that don't exist in the org
+// are left out.
+func (f *Force) TakeSchemaSnapshot(objects []string) (SchemaSnapshot, error) {
+	snapshot := SchemaSnapshot{
+		InstanceUrl: f.Credentials.InstanceUrl,
+		CreatedDate: time.Now().UTC().Format(time.RFC3339),
+	}
+	if f.Credentials.UserInfo != nil {
+		snapshot.Username = f.Credentials.UserInfo.UserName
+	}
+	// Objects that can't be described are skipped when snapshotting all
+	// objects
+	skipErrors := len(objects) == 0
+	if skipErrors {
+		sobjects, err := f.ListSobjects()
+		if err != nil {
+			return snapshot, fmt.Errorf("Could not list objects: %w", err)
+		}
+		for _, s := range sobjects {
+			if queryable, _ := s["queryable"].(bool); queryable {
+				objects = append(objects, fmt.Sprint(s["name"]))
+			}
+		}
+	}
+
+	const concurrency = 8
+	results := make([]SnapshotObject, len(objects))
+	errs := make([]error, len(objects))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, name := range objects {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, name string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			sobject, err := f.GetSobject(name)
+			if err != nil {
+				errs[i] = fmt.Errorf("Could not describe %s: %w", name, err)
+				return
+			}
+			results[i] = NewSnapshotObject(sobject)
+		}(i, name)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		switch {
+		case err == nil:
+			snapshot.Objects = append(snapshot.Objects, results[i])
+		case isObjectNotFound(err):
+			// Objects that don't exist are left out of the snapshot so
+			// they're reported as removed when compared
+			Log.Info(err.Error())
+		case skipErrors:
+			Log.Info(err.Error())
+		default:
+			return snapshot, err
+		}
+	}
+	sort.Slice(snapshot.Objects, func(i, j int) bool { return snapshot.Objects[i].Name < snapshot.Objects[j].Name })
+	return snapshot, nil
+}
+
+func isObjectNotFound(err error) bool {
+	var forceErrors ForceErrors
+	if !errors.As(err, &forceErrors) || len(forceErrors) == 0 {
+		return false
+	}
+	switch forceErrors[0].ErrorCode {
+	case "NOT_FOUND", "INVALID_TYPE":
+		return true
+	}
+	return false
+}
+
+// ObjectNames returns the names of the objects in the snapshot
+func (s SchemaSnapshot) ObjectNames() []string {
+	var names []string
+	for _, o := range s.Objects {
+		names = append(names, o.Name)
+	}
+	return names
+}
+
+// Only returns a copy of the snapshot with only the given objects
+func (s SchemaSnapshot) Only(objects []string) SchemaSnapshot {
+	include := make(map[string]bool)
+	for _, name := range objects {
+		include[strings.ToLower(name)] = true
+	}
+	filtered := s
+	filtered.Objects = nil
+	for _, o := range s.Objects {
+		if include[strings.ToLower(o.Name)] {
+			filtered.Objects = append(filtered.Objects, o)
+		}
+	}
+	return filtered
+}
+
+// CompareSchemaSnapshots reports the objects and fields added, removed, or
+// changed between two snapshots
+func CompareSchemaSnapshots(from SchemaSnapshot, to SchemaSnapshot) []SchemaDrift {
+	var drift []SchemaDrift
+	fromObjects := make(map[string]SnapshotObject)
+	for _, o := range from.Objects {
+		fromObjects[strings.ToLower(o.Name)] = o
+	}
+	toObjects := make(map[string]SnapshotObject)
+	for _, o := range to.Objects {
+		toObjects[strings.ToLower(o.Name)] = o
+	}
+	for _, o := range from.Objects {
+		if _, ok := toObjects[strings.ToLower(o.Name)]; !ok {
+			drift = append(drift, SchemaDrift{Change: "removed", Object: o.Name})
+		}
+	}
+	for _, o := range to.Objects {
+		previous, ok := fromObjects[strings.ToLower(o.Name)]
+		if !ok {
+			drift = append(drift, SchemaDrift{Change: "added", Object: o.Name})
+			continue
+		}
+		drift = append(drift, compareSnapshotFields(previous, o)...)
+	}
+	sort.SliceStable(drift, func(i, j int) bool {
+		if drift[i].Object != drift[j].Object {
+			return drift[i].Object < drift[j].Object
+		}
+		return drift[i].Field < drift[j].Field
+	})
+	return drift
+}
+
+func compareSnapshotFields(from SnapshotObject, to SnapshotObject) []SchemaDrift {
+	var drift []SchemaDrift
+	fromFields := make(map[string]SnapshotField)
+	for _, f := range from.Fields {
+		fromFields[strings.ToLower(f.Name)] = f
+	}
+	toFields := make(map[string]bool)
+	for _, f := range to.Fields {
+		toFields[strings.ToLower(f.Name)] = true
+		previous, ok := fromFields[strings.ToLower(f.Name)]
+		if !ok {
+			drift = append(drift, SchemaDrift{Change: "added", Object: to.Name, Field: f.Name, Differences: []string{"type: " + f.Type}})
+			continue
+		}
+		if differences := previous.differences(f); len(differences) > 0 {
+			drift = append(drift, SchemaDrift{Change: "changed", Object: to.Name, Field: f.Name, Differences: differences})
+		}
+	}
+	for _, f := range from.Fields {
+		if !toFields[strings.ToLower(f.Name)] {
+			drift = append(drift, SchemaDrift{Change: "removed", Object: to.Name, Field: f.Name})
+		}
+	}
+	return drift
+}
+
+func (f SnapshotField) differences(to SnapshotField) []string {
+	var differences []string
+	compare := func(name string, a, b interface{}) {
+		if fmt.Sprint(a) != fmt.Sprint(b) {
+			differences = append(differences, fmt.Sprintf("%s: %v -> %v", name, a, b))
+		}
+	}
+	compare("label", f.Label, to.Label)
+	compare("type", f.Type, to.Type)
+	compare("length", f.Length, to.Length)
+	compare("precision", f.Precision, to.Precision)
+	compare("scale", f.Scale, to.Scale)
+	compare("nillable", f.Nillable, to.Nillable)
+	compare("unique", f.Unique, to.Unique)
+	compare("externalId", f.ExternalId, to.ExternalId)
+	compare("formula", f.Formula, to.Formula)
+	compare("referenceTo", strings.Join(f.ReferenceTo, ", "), strings.Join(to.ReferenceTo, ", "))
+	added, removed := stringSetDifference(f.PicklistValues, to.PicklistValues)
+	if len(added) > 0 {
+		differences = append(differences, "picklist values added: "+strings.Join(added, ", "))
+	}
+	if len(removed) > 0 {
+		differences = append(differences, "picklist values removed: "+strings.Join(removed, ", "))
+	}
+	return differences
+}
+
+func stringSetDifference(from []string, to []string) (added []string, removed []string) {
+	inFrom := make(map[string]bool)
+	for _, s := range from {
+		inFrom[s] = true
+	}
+	inTo := make(map[string]bool)
+	for _, s := range to {
+		inTo[s] = true
+		if !inFrom[s] {
+			added = append(added, s)
+		}
+	}
+	for _, s := range from {
+		if !inTo[s] {
+			removed = append(removed, s)
+		}
+	}
+	return
+}
@@ -0,0 +1,116 @@
+package lib_test
+
+import (
+	. "github.com/ForceCLI/force/lib"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("SchemaSnapshot", func() {
+	It("should record field details from describe", func() {
+		o := NewSnapshotObject(ForceSobject{
+			"name":   "Invoice__c",
+			"custom": true,
+			"fields": []interface{}{
+				map[string]interface{}{"name": "Status__c", "type": "picklist", "length": float64(255), "nillable": true, "picklistValues": []interface{}{
+					map[string]interface{}{"value": "Draft", "active": true},
+					map[string]interface{}{"value": "Void", "active": false},
+				}},
+				map[string]interface{}{"name": "Account__c", "type": "reference", "referenceTo": []interface{}{"Account"}},
+			},
+		})
+		Expect(o.Custom).To(BeTrue())
+		Expect(o.Fields).To(HaveLen(2))
+		Expect(o.Fields[0].Name).To(Equal("Account__c"))
+		Expect(o.Fields[0].ReferenceTo).To(Equal([]string{"Account"}))
+		Expect(o.Fields[1].Length).To(Equal(255))
+		Expect(o.Fields[1].PicklistValues).To(Equal([]string{"Draft"}))
+	})
+
+	Describe("CompareSchemaSnapshots", func() {
+		before := SchemaSnapshot{Objects: []SnapshotObject{
+			{Name: "Account", Fields: []SnapshotField{
+				{Name: "Name", Type: "string", Length: 255},
+				{Name: "Old__c", Type: "string"},
+				{Name: "Tier__c", Type: "picklist", PicklistValues: []string{"Gold", "Bronze"}},
+			}},
+			{Name: "Legacy__c"},
+		}}
+		after := SchemaSnapshot{Objects: []SnapshotObject{
+			{Name: "Account", Fields: []SnapshotField{
+				{Name: "Name", Type: "string", Length: 80},
+				{Name: "New__c", Type: "date"},
+				{Name: "Tier__c", Type: "picklist", PicklistValues: []string{"Gold", "Silver"}},
+			}},
+			{Name: "Invoice__c"},
+		}}
+
+		It("should report added, removed, and changed objects and fields", func() {
+			var report []string
+			for _, d := range CompareSchemaSnapshots(before, after) {
+				report = append(report, d.String())
+			}
+			Expect(report).To(Equal([]string{
+				"~ Account.Name changed\n    length: 255 -> 80",
+				"+ Account.New__c added\n    type: date",
+				"- Account.Old__c removed",
+				"~ Account.Tier__c changed\n    picklist values added: Silver\n    picklist values removed: Bronze",
+				"+ Invoice__c added",
+				"- Legacy__c removed",
+			}))
+		})
+
+		It("should report nothing for identical snapshots", func() {
+			Expect(CompareSchemaSnapshots(before, before)).To(BeEmpty())
+		})
+
+		It("should compare only the selected objects", func() {
+			drift := CompareSchemaSnapshots(before.Only([]string{"legacy__c"}), after.Only([]string{"legacy__c"}))
+			Expect(drift).To(HaveLen(1))
+			Expect(drift[0].String()).To(Equal("- Legacy__c removed"))
+		})
+	})
+
+	Describe("TakeSchemaSnapshot", func() {
+		var sfServer *Server
+		var f *Force
+
+		describePath := func(object string) string {
+			return "/services/data/" + ApiVersion() + "/sobjects/" + object + "/describe"
+		}
+
+		BeforeEach(func() {
+			sfServer = NewServer()
+			f = NewForce(&ForceSession{InstanceUrl: sfServer.URL()})
+			sfServer.RouteToHandler("GET", describePath("Account"),
+				RespondWith(200, `{"name":"Account","fields":[{"name":"Name","type":"string","length":255}]}`, JsonHeaders))
+		})
+		AfterEach(func() {
+			sfServer.Close()
+		})
+
+		It("should leave out objects that have been deleted", func() {
+			sfServer.RouteToHandler("GET", describePath("Legacy__c"),
+				RespondWith(404, `[{"errorCode":"NOT_FOUND","message":"The requested resource does not exist"}]`, JsonHeaders))
+			snapshot, err := f.TakeSchemaSnapshot([]string{"Account", "Legacy__c"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(snapshot.ObjectNames()).To(Equal([]string{"Account"}))
+
+			before := SchemaSnapshot{Objects: []SnapshotObject{{Name: "Account"}, {Name: "Legacy__c"}}}
+			var report []string
+			for _, d := range CompareSchemaSnapshots(before, snapshot) {
+				report = append(report, d.String())
+			}
+			Expect(report).To(ContainElement("- Legacy__c removed"))
+		})
+
+		It("should return other describe errors", func() {
+			sfServer.RouteToHandler("GET", describePath("Invoice__c"),
+				RespondWith(400, `[{"errorCode":"REQUEST_RUNNING_TOO_LONG","message":"Try again"}]`, JsonHeaders))
+			_, err := f.TakeSchemaSnapshot([]string{"Account", "Invoice__c"})
+			Expect(err).To(MatchError(ContainSubstring("Could not describe Invoice__c")))
+		})
+	})
+})
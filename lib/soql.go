@@ -0,0 +1,19 @@
+package lib
+
+import (
+	"strings"
+)
+
+// soqlQuote quotes a string for use as a SOQL literal
+func soqlQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `\'`, -1) + "'"
+}
+
+// soqlQuoteList quotes strings for use in a SOQL IN list
+func soqlQuoteList(values []string) string {
+	var quoted []string
+	for _, v := range values {
+		quoted = append(quoted, soqlQuote(v))
+	}
+	return strings.Join(quoted, ", ")
+}
@@ -205,10 +205,22 @@ func (r ForceCheckDeploymentStatusResult) ToString(duration float64, verbose boo
 }
 
 func (r ForceCheckDeploymentStatusResult) ToJunit(duration float64) (string, error) {
+	testSuite := r.ToJunitTestSuite("apex", duration)
+	output, err := xml.MarshalIndent(testSuite, "", "   ")
+	if err != nil {
+		return "", errors.Wrap(err, "Unable to format result for junit")
+	}
+	const declaration = `<?xml version="1.0" encoding="UTF-8"?>`
+	return fmt.Sprintf("%s\n%s", declaration, string(output)), nil
+}
+
+// ToJunitTestSuite converts the component and test failures and test
+// successes to a JUnit test suite with the given name.
+func (r ForceCheckDeploymentStatusResult) ToJunitTestSuite(name string, duration float64) junit.TestSuite {
 	c := r.Details
 	hostname, _ := os.Hostname()
 	testSuite := junit.TestSuite{
-		Name:      "apex",
+		Name:      name,
 		Time:      duration,
 		Hostname:  hostname,
 		Timestamp: time.Now(),
@@ -259,12 +271,7 @@ func (r ForceCheckDeploymentStatusResult) ToJunit(duration float64) (string, err
 		testSuite.TestCases = append(testSuite.TestCases, &e)
 	}
 	testSuite.Update()
-	output, err := xml.MarshalIndent(testSuite, "", "   ")
-	if err != nil {
-		return "", errors.Wrap(err, "Unable to format result for junit")
-	}
-	const declaration = `<?xml version="1.0" encoding="UTF-8"?>`
-	return fmt.Sprintf("%s\n%s", declaration, string(output)), nil
+	return testSuite
 }
 
 func (r ForceCheckDeploymentStatusResult) HasComponentFailures() bool {
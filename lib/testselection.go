@@ -0,0 +1,210 @@
+package lib
+
+import (
+	"encoding/xml"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// SelectedTest is an Apex test class chosen to run for a deployment, along
+// with the reasons it was selected.
+type SelectedTest struct {
+	Name    string
+	Reasons []string
+}
+
+type testSelector struct {
+	tests map[string]*SelectedTest
+}
+
+var apexTestAnnotation = regexp.MustCompile(`(?i)@istest\b|\btestmethod\b`)
+
+// IsApexTestSource returns true if the Apex source contains test code.
+func IsApexTestSource(source []byte) bool {
+	return apexTestAnnotation.Match(source)
+}
+
+// TestClassNameCandidates returns the conventional names of test classes for
+// an Apex class or trigger.
+func TestClassNameCandidates(name string) []string {
+	return []string{
+		name + "Test",
+		name + "_Test",
+		name + "Tests",
+		name + "_Tests",
+		"Test" + name,
+		"Test_" + name,
+	}
+}
+
+// ChangedApexComponents returns the Apex classes and triggers included in the
+// package.xml of files.
+func ChangedApexComponents(files ForceMetadataFiles) (classes []string, triggers []string, err error) {
+	data, ok := files["package.xml"]
+	if !ok {
+		return nil, nil, fmt.Errorf("package.xml not found")
+	}
+	var p Package
+	if err = xml.Unmarshal(data, &p); err != nil {
+		return nil, nil, fmt.Errorf("Could not parse package.xml: %w", err)
+	}
+	for _, t := range p.Types {
+		switch t.Name {
+		case "ApexClass":
+			classes = append(classes, t.Members...)
+		case "ApexTrigger":
+			triggers = append(triggers, t.Members...)
+		}
+	}
+	return classes, triggers, nil
+}
+
+func (s *testSelector) add(name string, reason string) {
+	t, ok := s.tests[strings.ToLower(name)]
+	if !ok {
+		t = &SelectedTest{Name: name}
+		s.tests[strings.ToLower(name)] = t
+	}
+	for _, r := range t.Reasons {
+		if r == reason {
+			return
+		}
+	}
+	t.Reasons = append(t.Reasons, reason)
+}
+
+func (s *testSelector) selected() []SelectedTest {
+	var tests []SelectedTest
+	for _, t := range s.tests {
+		tests = append(tests, *t)
+	}
+	sort.Slice(tests, func(i, j int) bool {
+		return tests[i].Name < tests[j].Name
+	})
+	return tests
+}
+
+// soqlInChunks splits values into quoted, comma-separated lists suitable for
+// use in a SOQL IN clause without exceeding URL length limits.
+func soqlInChunks(values []string) []string {
+	const chunkSize = 100
+	var chunks []string
+	for i := 0; i < len(values); i += chunkSize {
+		end := i + chunkSize
+		if end > len(values) {
+			end = len(values)
+		}
+		quoted := make([]string, 0, end-i)
+		for _, v := range values[i:end] {
+			quoted = append(quoted, soqlQuote(v))
+		}
+		chunks = append(chunks, strings.Join(quoted, ", "))
+	}
+	return chunks
+}
+
+// SelectRelevantTests chooses the Apex test classes that exercise the Apex
+// classes and triggers being deployed in files.  Tests are selected if they
+// are themselves being deployed, if they follow the naming conventions in
+// TestClassNameCandidates, or if the Tooling API reports that they reference
+// a deployed class or the object of a deployed trigger.
+func (f *Force) SelectRelevantTests(files ForceMetadataFiles) ([]SelectedTest, error) {
+	classes, triggers, err := ChangedApexComponents(files)
+	if err != nil {
+		return nil, err
+	}
+	selector := &testSelector{tests: make(map[string]*SelectedTest)}
+	// Candidate test class name => reasons it would be selected
+	candidates := make(map[string][]string)
+	addCandidate := func(name, reason string) {
+		candidates[name] = append(candidates[name], reason)
+	}
+
+	for _, class := range classes {
+		if IsApexTestSource(files[path.Join("classes", class+".cls")]) {
+			selector.add(class, "test class is being deployed")
+			continue
+		}
+		for _, candidate := range TestClassNameCandidates(class) {
+			addCandidate(candidate, fmt.Sprintf("named after ApexClass %s", class))
+		}
+	}
+	for _, trigger := range triggers {
+		for _, candidate := range TestClassNameCandidates(trigger) {
+			addCandidate(candidate, fmt.Sprintf("named after ApexTrigger %s", trigger))
+		}
+	}
+
+	for _, chunk := range soqlInChunks(classes) {
+		dependencies, err := f.QueryMetadataComponentDependencies(fmt.Sprintf("MetadataComponentType = 'ApexClass' AND RefMetadataComponentType = 'ApexClass' AND RefMetadataComponentName IN (%s)", chunk))
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range dependencies {
+			addCandidate(d.Component.Name, fmt.Sprintf("references ApexClass %s", d.RefComponent.Name))
+		}
+	}
+
+	// Tests don't reference triggers directly, so look for tests that
+	// reference the custom objects the triggers are defined on.
+	for _, chunk := range soqlInChunks(triggers) {
+		result, err := f.Query(fmt.Sprintf("SELECT Name, TableEnumOrId FROM ApexTrigger WHERE Name IN (%s)", chunk))
+		if err != nil {
+			return nil, fmt.Errorf("Could not query triggers: %w", err)
+		}
+		for _, r := range result.Records {
+			triggerName, _ := r["Name"].(string)
+			objectId, _ := r["TableEnumOrId"].(string)
+			if !strings.HasPrefix(objectId, "01I") {
+				// Standard objects aren't tracked by MetadataComponentDependency
+				continue
+			}
+			dependencies, err := f.QueryMetadataComponentDependencies(fmt.Sprintf("MetadataComponentType = 'ApexClass' AND RefMetadataComponentId = %s", soqlQuote(objectId)))
+			if err != nil {
+				return nil, err
+			}
+			for _, d := range dependencies {
+				addCandidate(d.Component.Name, fmt.Sprintf("references %s, the object of ApexTrigger %s", d.RefComponent.Name, triggerName))
+			}
+		}
+	}
+
+	var names []string
+	for name := range candidates {
+		// Prefer the local source of classes being deployed
+		if source, ok := files[path.Join("classes", name+".cls")]; ok {
+			if IsApexTestSource(source) {
+				for _, reason := range candidates[name] {
+					selector.add(name, reason)
+				}
+			}
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, chunk := range soqlInChunks(names) {
+		result, err := f.Query(fmt.Sprintf("SELECT Name, Body FROM ApexClass WHERE Name IN (%s)", chunk))
+		if err != nil {
+			return nil, fmt.Errorf("Could not query test classes: %w", err)
+		}
+		for _, r := range result.Records {
+			name, _ := r["Name"].(string)
+			body, _ := r["Body"].(string)
+			if !IsApexTestSource([]byte(body)) {
+				continue
+			}
+			for candidate, reasons := range candidates {
+				if strings.EqualFold(candidate, name) {
+					for _, reason := range reasons {
+						selector.add(name, reason)
+					}
+				}
+			}
+		}
+	}
+	return selector.selected(), nil
+}
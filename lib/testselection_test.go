@@ -0,0 +1,162 @@
+package lib_test
+
+import (
+	"net/http"
+
+	. "github.com/ForceCLI/force/lib"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("TestSelection", func() {
+	Describe("IsApexTestSource", func() {
+		It("should detect the isTest annotation regardless of case", func() {
+			Expect(IsApexTestSource([]byte("@IsTest\nprivate class FooTest {}"))).To(BeTrue())
+			Expect(IsApexTestSource([]byte("@isTest(SeeAllData=true)\nprivate class FooTest {}"))).To(BeTrue())
+		})
+		It("should detect testMethod", func() {
+			Expect(IsApexTestSource([]byte("static testMethod void testFoo() {}"))).To(BeTrue())
+		})
+		It("should not match regular classes", func() {
+			Expect(IsApexTestSource([]byte("public class Foo { Boolean isTestable; }"))).To(BeFalse())
+		})
+	})
+
+	Describe("TestClassNameCandidates", func() {
+		It("should include common naming conventions", func() {
+			Expect(TestClassNameCandidates("Invoice")).To(ContainElements("InvoiceTest", "Invoice_Test", "TestInvoice"))
+		})
+	})
+
+	Describe("ChangedApexComponents", func() {
+		It("should return classes and triggers from package.xml", func() {
+			files := ForceMetadataFiles{
+				"package.xml": []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<Package xmlns="http://soap.sforce.com/2006/04/metadata">
+	<types>
+		<members>Invoice</members>
+		<members>InvoiceTest</members>
+		<name>ApexClass</name>
+	</types>
+	<types>
+		<members>InvoiceTrigger</members>
+		<name>ApexTrigger</name>
+	</types>
+	<types>
+		<members>Invoice__c</members>
+		<name>CustomObject</name>
+	</types>
+	<version>58.0</version>
+</Package>`),
+			}
+			classes, triggers, err := ChangedApexComponents(files)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(classes).To(Equal([]string{"Invoice", "InvoiceTest"}))
+			Expect(triggers).To(Equal([]string{"InvoiceTrigger"}))
+		})
+		It("should fail without package.xml", func() {
+			_, _, err := ChangedApexComponents(ForceMetadataFiles{})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("SelectRelevantTests", func() {
+	var sfServer *Server
+	var f *Force
+
+	const packageXml = `<?xml version="1.0" encoding="UTF-8"?>
+<Package xmlns="http://soap.sforce.com/2006/04/metadata">
+	<types>
+		<members>Invoice</members>
+		<name>ApexClass</name>
+	</types>
+	<types>
+		<members>InvoiceTrigger</members>
+		<name>ApexTrigger</name>
+	</types>
+	<version>58.0</version>
+</Package>`
+
+	verifyQueryContains := func(s string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.URL.Query().Get("q")).To(ContainSubstring(s))
+		}
+	}
+
+	BeforeEach(func() {
+		sfServer = NewServer()
+		f = NewForce(&ForceSession{InstanceUrl: sfServer.URL()})
+	})
+
+	AfterEach(func() {
+		sfServer.Close()
+	})
+
+	It("should select tests by name and by dependency", func() {
+		sfServer.AppendHandlers(
+			CombineHandlers(
+				verifyToolingQuery("MetadataComponentDependency"),
+				verifyQueryContains("RefMetadataComponentName IN ('Invoice')"),
+				RespondWith(200, queryResponse(`{"MetadataComponentName":"BillingTests","MetadataComponentType":"ApexClass","RefMetadataComponentName":"Invoice","RefMetadataComponentType":"ApexClass"}`), JsonHeaders),
+			),
+			CombineHandlers(
+				verifyQuery("ApexTrigger"),
+				verifyQueryContains("Name IN ('InvoiceTrigger')"),
+				RespondWith(200, queryResponse(`{"Name":"InvoiceTrigger","TableEnumOrId":"01I000000000001"}`), JsonHeaders),
+			),
+			CombineHandlers(
+				verifyToolingQuery("MetadataComponentDependency"),
+				verifyQueryContains("RefMetadataComponentId = '01I000000000001'"),
+				RespondWith(200, queryResponse(`{"MetadataComponentName":"PaymentTests","MetadataComponentType":"ApexClass","RefMetadataComponentName":"Invoice__c","RefMetadataComponentType":"CustomObject"}`), JsonHeaders),
+			),
+			CombineHandlers(
+				verifyQuery("ApexClass"),
+				verifyQueryContains("'InvoiceTest'"),
+				RespondWith(200, `{"totalSize":4,"done":true,"records":[
+					{"Name":"BillingTests","Body":"@IsTest\nprivate class BillingTests {}"},
+					{"Name":"InvoiceTest","Body":"@IsTest\nprivate class InvoiceTest {}"},
+					{"Name":"PaymentTests","Body":"@IsTest\nprivate class PaymentTests {}"},
+					{"Name":"TestInvoice","Body":"public class TestInvoice {}"}
+				]}`, JsonHeaders),
+			),
+		)
+		tests, err := f.SelectRelevantTests(ForceMetadataFiles{
+			"package.xml":                     []byte(packageXml),
+			"classes/Invoice.cls":             []byte("public class Invoice {}"),
+			"triggers/InvoiceTrigger.trigger": []byte("trigger InvoiceTrigger on Invoice__c (before insert) {}"),
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(tests).To(Equal([]SelectedTest{
+			{Name: "BillingTests", Reasons: []string{"references ApexClass Invoice"}},
+			{Name: "InvoiceTest", Reasons: []string{"named after ApexClass Invoice"}},
+			{Name: "PaymentTests", Reasons: []string{"references Invoice__c, the object of ApexTrigger InvoiceTrigger"}},
+		}))
+	})
+
+	It("should return no tests when none are relevant", func() {
+		sfServer.AppendHandlers(
+			CombineHandlers(
+				verifyToolingQuery("MetadataComponentDependency"),
+				RespondWith(200, `{"totalSize":0,"done":true,"records":[]}`, JsonHeaders),
+			),
+			CombineHandlers(
+				verifyQuery("ApexTrigger"),
+				RespondWith(200, queryResponse(`{"Name":"InvoiceTrigger","TableEnumOrId":"Account"}`), JsonHeaders),
+			),
+			CombineHandlers(
+				verifyQuery("ApexClass"),
+				RespondWith(200, queryResponse(`{"Name":"TestInvoice","Body":"public class TestInvoice {}"}`), JsonHeaders),
+			),
+		)
+		tests, err := f.SelectRelevantTests(ForceMetadataFiles{
+			"package.xml":         []byte(packageXml),
+			"classes/Invoice.cls": []byte("public class Invoice {}"),
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(tests).To(BeEmpty())
+		Expect(sfServer.ReceivedRequests()).To(HaveLen(3))
+	})
+})
@@ -0,0 +1,504 @@
+package lib
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math/rand"
+	"strings"
+	"unicode"
+)
+
+// UserSpec describes a user to create.  Profile, Role, and PermissionSets are
+// names rather than ids.
+type UserSpec struct {
+	FirstName         string
+	LastName          string
+	Email             string
+	Username          string
+	Alias             string
+	Profile           string
+	Role              string
+	PermissionSets    []string
+	TimeZoneSidKey    string
+	LocaleSidKey      string
+	LanguageLocaleKey string
+	EmailEncodingKey  string
+}
+
+// UserCreateResult is the outcome of creating a user
+type UserCreateResult struct {
+	Username string
+	Id       string
+	Error    error
+}
+
+// User is an existing user
+type User struct {
+	Id            string
+	Username      string
+	Name          string
+	Profile       string
+	Role          string
+	IsActive      bool
+	IsFrozen      bool
+	LastLoginDate string
+}
+
+// UserUpdateResult is the outcome of freezing, unfreezing, or deactivating a
+// user
+type UserUpdateResult struct {
+	Username string
+	// Status is frozen, unfrozen, deactivated, unchanged, or failed
+	Status string
+	Error  error
+}
+
+var userSpecColumns = map[string]func(*UserSpec, string){
+	"firstname":         func(s *UserSpec, v string) { s.FirstName = v },
+	"lastname":          func(s *UserSpec, v string) { s.LastName = v },
+	"email":             func(s *UserSpec, v string) { s.Email = v },
+	"username":          func(s *UserSpec, v string) { s.Username = v },
+	"alias":             func(s *UserSpec, v string) { s.Alias = v },
+	"profile":           func(s *UserSpec, v string) { s.Profile = v },
+	"role":              func(s *UserSpec, v string) { s.Role = v },
+	"timezonesidkey":    func(s *UserSpec, v string) { s.TimeZoneSidKey = v },
+	"localesidkey":      func(s *UserSpec, v string) { s.LocaleSidKey = v },
+	"languagelocalekey": func(s *UserSpec, v string) { s.LanguageLocaleKey = v },
+	"emailencodingkey":  func(s *UserSpec, v string) { s.EmailEncodingKey = v },
+	"permissionsets": func(s *UserSpec, v string) {
+		for _, p := range strings.Split(v, ";") {
+			if p = strings.TrimSpace(p); p != "" {
+				s.PermissionSets = append(s.PermissionSets, p)
+			}
+		}
+	},
+}
+
+// ReadUserSpecs reads users to create from a CSV file.  Column names are
+// case-insensitive and match the UserSpec fields.  PermissionSets are
+// separated by semicolons.
+func ReadUserSpecs(r io.Reader) ([]UserSpec, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("Could not read CSV header: %w", err)
+	}
+	for _, h := range header {
+		if _, ok := userSpecColumns[strings.ToLower(strings.TrimSpace(h))]; !ok {
+			return nil, fmt.Errorf("Unknown column: %s", h)
+		}
+	}
+	var specs []UserSpec
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Could not read CSV: %w", err)
+		}
+		var spec UserSpec
+		for i, h := range header {
+			userSpecColumns[strings.ToLower(strings.TrimSpace(h))](&spec, strings.TrimSpace(row[i]))
+		}
+		specs = append(specs, spec)
+	}
+	return specs, nil
+}
+
+// WithDefaults fills in the fields of the spec that aren't set from a
+// template user.  The template's name, email, username, and alias are not
+// used.
+func (s UserSpec) WithDefaults(template UserSpec) UserSpec {
+	defaults := func(value *string, fallback string) {
+		if *value == "" {
+			*value = fallback
+		}
+	}
+	defaults(&s.Profile, template.Profile)
+	defaults(&s.Role, template.Role)
+	defaults(&s.TimeZoneSidKey, template.TimeZoneSidKey)
+	defaults(&s.LocaleSidKey, template.LocaleSidKey)
+	defaults(&s.LanguageLocaleKey, template.LanguageLocaleKey)
+	defaults(&s.EmailEncodingKey, template.EmailEncodingKey)
+	if len(s.PermissionSets) == 0 {
+		s.PermissionSets = template.PermissionSets
+	}
+	return s
+}
+
+// Validate checks that the fields required to create a user are set
+func (s UserSpec) Validate() error {
+	var missing []string
+	for _, field := range []struct {
+		name  string
+		value string
+	}{
+		{"LastName", s.LastName},
+		{"Email", s.Email},
+		{"Profile", s.Profile},
+	} {
+		if field.value == "" {
+			missing = append(missing, field.name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("Missing %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// GenerateUsername builds a username from the local part of an email address,
+// a suffix to make it unique, and a domain, such as the domain of the org's
+// existing usernames
+func GenerateUsername(email string, suffix string, domain string) string {
+	local := email
+	if i := strings.Index(email, "@"); i >= 0 {
+		local = email[:i]
+	}
+	if suffix != "" {
+		local += "." + suffix
+	}
+	return strings.ToLower(local + "@" + domain)
+}
+
+// GenerateUserAlias builds an alias of up to eight characters from the first
+// letter of the first name and the last name
+func GenerateUserAlias(firstName string, lastName string) string {
+	var alias []rune
+	for _, r := range firstName {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			alias = append(alias, unicode.ToLower(r))
+			break
+		}
+	}
+	for _, r := range lastName {
+		if len(alias) == 8 {
+			break
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			alias = append(alias, unicode.ToLower(r))
+		}
+	}
+	return string(alias)
+}
+
+func usernameDomain(username string) string {
+	if i := strings.LastIndex(username, "@"); i >= 0 {
+		return username[i+1:]
+	}
+	return username
+}
+
+func randomUsernameSuffix() string {
+	const letters = "abcdefghijklmnopqrstuvwxyz0123456789"
+	b := make([]byte, 6)
+	for i := range b {
+		b[i] = letters[rand.Intn(len(letters))]
+	}
+	return string(b)
+}
+
+// GetUserTemplate returns the profile, role, permission sets, and locale
+// settings of an existing user, to be used as defaults for new users
+func (f *Force) GetUserTemplate(username string) (UserSpec, error) {
+	var users []struct {
+		Id      string
+		Profile struct {
+			Name string
+		}
+		UserRole *struct {
+			Name string
+		}
+		TimeZoneSidKey    string
+		LocaleSidKey      string
+		LanguageLocaleKey string
+		EmailEncodingKey  string
+	}
+	soql := fmt.Sprintf(`SELECT Id, Profile.Name, UserRole.Name, TimeZoneSidKey, LocaleSidKey, LanguageLocaleKey, EmailEncodingKey
+	FROM User
+	WHERE Username = %s`, soqlQuote(username))
+	if err := f.QueryInto(soql, &users); err != nil {
+		return UserSpec{}, fmt.Errorf("Could not query user %s: %w", username, err)
+	}
+	if len(users) == 0 {
+		return UserSpec{}, fmt.Errorf("No user with username %s", username)
+	}
+	u := users[0]
+	template := UserSpec{
+		Username:          username,
+		Profile:           u.Profile.Name,
+		TimeZoneSidKey:    u.TimeZoneSidKey,
+		LocaleSidKey:      u.LocaleSidKey,
+		LanguageLocaleKey: u.LanguageLocaleKey,
+		EmailEncodingKey:  u.EmailEncodingKey,
+	}
+	if u.UserRole != nil {
+		template.Role = u.UserRole.Name
+	}
+
+	var assignments []struct {
+		PermissionSet struct {
+			Name string
+		}
+		PermissionSetGroup *struct {
+			DeveloperName string
+		}
+	}
+	soql = fmt.Sprintf(`SELECT PermissionSet.Name, PermissionSetGroup.DeveloperName
+	FROM PermissionSetAssignment
+	WHERE AssigneeId = %s AND PermissionSet.IsOwnedByProfile = false`, soqlQuote(u.Id))
+	if err := f.QueryInto(soql, &assignments); err != nil {
+		return UserSpec{}, fmt.Errorf("Could not query permission sets of %s: %w", username, err)
+	}
+	for _, a := range assignments {
+		if a.PermissionSetGroup != nil {
+			template.PermissionSets = append(template.PermissionSets, a.PermissionSetGroup.DeveloperName)
+		} else {
+			template.PermissionSets = append(template.PermissionSets, a.PermissionSet.Name)
+		}
+	}
+	return template, nil
+}
+
+func (f *Force) queryId(soql string, description string) (string, error) {
+	var records []struct {
+		Id string
+	}
+	if err := f.QueryInto(soql, &records); err != nil {
+		return "", fmt.Errorf("Could not query %s: %w", description, err)
+	}
+	if len(records) == 0 {
+		return "", fmt.Errorf("No %s found", description)
+	}
+	return records[0].Id, nil
+}
+
+// CreateUsers creates users, generating usernames and aliases when they
+// aren't set, and assigns their permission sets.  Usernames are generated
+// from the email address and the domain of the active user's username.  All
+// specs are validated, and their profiles, roles, and permission sets looked
+// up, before any user is created.
+func (f *Force) CreateUsers(specs []UserSpec) ([]UserCreateResult, error) {
+	domain := ""
+	if f.Credentials.UserInfo != nil {
+		domain = usernameDomain(f.Credentials.UserInfo.UserName)
+	}
+	profileIds := make(map[string]string)
+	roleIds := make(map[string]string)
+	permissionSets := make(map[string]PermissionSet)
+
+	specs = append([]UserSpec(nil), specs...)
+	for i := range specs {
+		spec := &specs[i]
+		if err := spec.Validate(); err != nil {
+			return nil, err
+		}
+		if spec.Username == "" {
+			if domain == "" {
+				return nil, fmt.Errorf("Username required for %s", spec.Email)
+			}
+			spec.Username = GenerateUsername(spec.Email, randomUsernameSuffix(), domain)
+		}
+		if spec.Alias == "" {
+			spec.Alias = GenerateUserAlias(spec.FirstName, spec.LastName)
+		}
+	}
+	for _, spec := range specs {
+		if _, ok := profileIds[spec.Profile]; !ok {
+			id, err := f.queryId(fmt.Sprintf("SELECT Id FROM Profile WHERE Name = %s", soqlQuote(spec.Profile)), "profile "+spec.Profile)
+			if err != nil {
+				return nil, err
+			}
+			profileIds[spec.Profile] = id
+		}
+		if _, ok := roleIds[spec.Role]; !ok && spec.Role != "" {
+			id, err := f.queryId(fmt.Sprintf("SELECT Id FROM UserRole WHERE Name = %[1]s OR DeveloperName = %[1]s", soqlQuote(spec.Role)), "role "+spec.Role)
+			if err != nil {
+				return nil, err
+			}
+			roleIds[spec.Role] = id
+		}
+		for _, name := range spec.PermissionSets {
+			if _, ok := permissionSets[name]; !ok {
+				ps, err := f.GetPermissionSet(name)
+				if err != nil {
+					return nil, err
+				}
+				permissionSets[name] = ps
+			}
+		}
+	}
+
+	var results []UserCreateResult
+	for _, spec := range specs {
+		attrs := map[string]string{
+			"FirstName":         spec.FirstName,
+			"LastName":          spec.LastName,
+			"Email":             spec.Email,
+			"Username":          spec.Username,
+			"Alias":             spec.Alias,
+			"ProfileId":         profileIds[spec.Profile],
+			"TimeZoneSidKey":    spec.TimeZoneSidKey,
+			"LocaleSidKey":      spec.LocaleSidKey,
+			"LanguageLocaleKey": spec.LanguageLocaleKey,
+			"EmailEncodingKey":  spec.EmailEncodingKey,
+		}
+		if spec.Role != "" {
+			attrs["UserRoleId"] = roleIds[spec.Role]
+		}
+		id, err, messages := f.CreateRecord("User", attrs)
+		if err != nil {
+			if len(messages) > 0 {
+				err = ForceErrors(messages)
+			}
+			results = append(results, UserCreateResult{Username: spec.Username, Error: err})
+			continue
+		}
+		result := UserCreateResult{Username: spec.Username, Id: id}
+		for _, name := range spec.PermissionSets {
+			assigned, err := f.AssignPermissionSet(permissionSets[name], map[string]string{spec.Username: id})
+			if err == nil && len(assigned) > 0 {
+				err = assigned[0].Error
+			}
+			if err != nil {
+				result.Error = fmt.Errorf("Could not assign %s: %w", name, err)
+				break
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// QueryUsers returns the users matching a SOQL condition on User, or all
+// active users if the condition is empty
+func (f *Force) QueryUsers(condition string) ([]User, error) {
+	if condition == "" {
+		condition = "IsActive = true"
+	}
+	var users []struct {
+		Id       string
+		Username string
+		Name     string
+		Profile  *struct {
+			Name string
+		}
+		UserRole *struct {
+			Name string
+		}
+		IsActive      bool
+		LastLoginDate string
+	}
+	soql := fmt.Sprintf(`SELECT Id, Username, Name, Profile.Name, UserRole.Name, IsActive, LastLoginDate
+	FROM User
+	WHERE %s
+	ORDER BY Username`, condition)
+	if err := f.QueryInto(soql, &users); err != nil {
+		return nil, fmt.Errorf("Could not query users: %w", err)
+	}
+	var ids []string
+	for _, u := range users {
+		ids = append(ids, u.Id)
+	}
+	frozen, err := f.queryUserLogins(ids)
+	if err != nil {
+		return nil, err
+	}
+	var results []User
+	for _, u := range users {
+		user := User{
+			Id:            u.Id,
+			Username:      u.Username,
+			Name:          u.Name,
+			IsActive:      u.IsActive,
+			IsFrozen:      frozen[u.Id].IsFrozen,
+			LastLoginDate: u.LastLoginDate,
+		}
+		if u.Profile != nil {
+			user.Profile = u.Profile.Name
+		}
+		if u.UserRole != nil {
+			user.Role = u.UserRole.Name
+		}
+		results = append(results, user)
+	}
+	return results, nil
+}
+
+type userLogin struct {
+	Id       string
+	UserId   string
+	IsFrozen bool
+}
+
+// queryUserLogins returns the UserLogin records of users, keyed by user id
+func (f *Force) queryUserLogins(userIds []string) (map[string]userLogin, error) {
+	logins := make(map[string]userLogin)
+	const chunkSize = 200
+	for start := 0; start < len(userIds); start += chunkSize {
+		end := start + chunkSize
+		if end > len(userIds) {
+			end = len(userIds)
+		}
+		var records []userLogin
+		soql := fmt.Sprintf("SELECT Id, UserId, IsFrozen FROM UserLogin WHERE UserId IN ('%s')", strings.Join(userIds[start:end], "','"))
+		if err := f.QueryInto(soql, &records); err != nil {
+			return nil, fmt.Errorf("Could not query user logins: %w", err)
+		}
+		for _, r := range records {
+			logins[r.UserId] = r
+		}
+	}
+	return logins, nil
+}
+
+// FreezeUsers freezes or unfreezes users, keyed by username, so they can't
+// log in.  Users who are already in the requested state are left unchanged.
+func (f *Force) FreezeUsers(userIds map[string]string, freeze bool) ([]UserUpdateResult, error) {
+	var ids []string
+	for _, id := range userIds {
+		ids = append(ids, id)
+	}
+	logins, err := f.queryUserLogins(ids)
+	if err != nil {
+		return nil, err
+	}
+	status := "unfrozen"
+	if freeze {
+		status = "frozen"
+	}
+	var results []UserUpdateResult
+	for _, username := range sortedStringKeys(userIds) {
+		login, ok := logins[userIds[username]]
+		if !ok {
+			results = append(results, UserUpdateResult{Username: username, Status: "failed", Error: fmt.Errorf("No UserLogin found")})
+			continue
+		}
+		if login.IsFrozen == freeze {
+			results = append(results, UserUpdateResult{Username: username, Status: "unchanged"})
+			continue
+		}
+		if err := f.UpdateRecord("UserLogin", login.Id, map[string]string{"IsFrozen": fmt.Sprint(freeze)}); err != nil {
+			results = append(results, UserUpdateResult{Username: username, Status: "failed", Error: err})
+			continue
+		}
+		results = append(results, UserUpdateResult{Username: username, Status: status})
+	}
+	return results, nil
+}
+
+// DeactivateUsers deactivates users, keyed by username
+func (f *Force) DeactivateUsers(userIds map[string]string) ([]UserUpdateResult, error) {
+	var results []UserUpdateResult
+	for _, username := range sortedStringKeys(userIds) {
+		if err := f.UpdateRecord("User", userIds[username], map[string]string{"IsActive": "false"}); err != nil {
+			results = append(results, UserUpdateResult{Username: username, Status: "failed", Error: err})
+			continue
+		}
+		results = append(results, UserUpdateResult{Username: username, Status: "deactivated"})
+	}
+	return results, nil
+}
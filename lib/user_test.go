@@ -0,0 +1,114 @@
+package lib_test
+
+import (
+	"net/http"
+	"strings"
+
+	. "github.com/ForceCLI/force/lib"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/ghttp"
+)
+
+// verifyQuery verifies a REST API query against the given object
+func verifyQuery(object string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		Expect(r.Method).To(Equal("GET"))
+		Expect(strings.TrimSuffix(r.URL.Path, "/")).To(Equal("/services/data/" + ApiVersion() + "/query"))
+		Expect(r.URL.Query().Get("q")).To(ContainSubstring("FROM " + object))
+	}
+}
+
+var _ = Describe("User", func() {
+	It("should read users from CSV", func() {
+		input := `FirstName,LastName,email,Profile,PermissionSets
+Jane,Doe,jane@example.com,Standard User,Sales_Ops; Support
+John,Smith,john@example.com,,
+`
+		specs, err := ReadUserSpecs(strings.NewReader(input))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(specs).To(Equal([]UserSpec{
+			{FirstName: "Jane", LastName: "Doe", Email: "jane@example.com", Profile: "Standard User", PermissionSets: []string{"Sales_Ops", "Support"}},
+			{FirstName: "John", LastName: "Smith", Email: "john@example.com"},
+		}))
+	})
+
+	It("should reject unknown CSV columns", func() {
+		_, err := ReadUserSpecs(strings.NewReader("LastName,Department\nDoe,Sales\n"))
+		Expect(err).To(MatchError("Unknown column: Department"))
+	})
+
+	It("should fill in defaults from a template user", func() {
+		template := UserSpec{
+			Username:       "template@example.com",
+			Email:          "template@example.com",
+			Profile:        "Standard User",
+			Role:           "Sales",
+			PermissionSets: []string{"Sales_Ops"},
+			TimeZoneSidKey: "America/New_York",
+		}
+		spec := UserSpec{LastName: "Doe", Email: "jane@example.com", Role: "Support"}.WithDefaults(template)
+		Expect(spec).To(Equal(UserSpec{
+			LastName:       "Doe",
+			Email:          "jane@example.com",
+			Profile:        "Standard User",
+			Role:           "Support",
+			PermissionSets: []string{"Sales_Ops"},
+			TimeZoneSidKey: "America/New_York",
+		}))
+	})
+
+	It("should require a last name, email, and profile", func() {
+		Expect(UserSpec{FirstName: "Jane"}.Validate()).To(MatchError("Missing LastName, Email, Profile"))
+		Expect(UserSpec{LastName: "Doe", Email: "jane@example.com", Profile: "Standard User"}.Validate()).To(Succeed())
+	})
+
+	It("should generate usernames and aliases", func() {
+		Expect(GenerateUsername("Jane.Doe@example.com", "x1y2z3", "acme.com.uat")).To(Equal("jane.doe.x1y2z3@acme.com.uat"))
+		Expect(GenerateUserAlias("Jane", "O'Donnell-Smith")).To(Equal("jodonnel"))
+		Expect(GenerateUserAlias("", "Doe")).To(Equal("doe"))
+	})
+
+	Describe("CreateUsers", func() {
+		var sfServer *Server
+		var f *Force
+
+		BeforeEach(func() {
+			sfServer = NewServer()
+			f = NewForce(&ForceSession{InstanceUrl: sfServer.URL()})
+		})
+		AfterEach(func() {
+			sfServer.Close()
+		})
+
+		It("should not create any users if a spec can't be resolved", func() {
+			sfServer.AppendHandlers(
+				CombineHandlers(
+					verifyQuery("Profile"),
+					RespondWith(200, queryResponse(`{"Id":"00e000000000001"}`), JsonHeaders),
+				),
+				CombineHandlers(
+					verifyQuery("Profile"),
+					RespondWith(200, `{"totalSize":0,"done":true,"records":[]}`, JsonHeaders),
+				),
+			)
+			results, err := f.CreateUsers([]UserSpec{
+				{LastName: "Doe", Email: "jane@example.com", Username: "jane@example.com", Profile: "Standard User"},
+				{LastName: "Roe", Email: "rick@example.com", Username: "rick@example.com", Profile: "Missing Profile"},
+			})
+			Expect(err).To(MatchError("No profile Missing Profile found"))
+			Expect(results).To(BeEmpty())
+			Expect(sfServer.ReceivedRequests()).To(HaveLen(2))
+		})
+
+		It("should not create any users if a spec is invalid", func() {
+			_, err := f.CreateUsers([]UserSpec{
+				{LastName: "Doe", Email: "jane@example.com", Username: "jane@example.com", Profile: "Standard User"},
+				{LastName: "Roe", Profile: "Standard User"},
+			})
+			Expect(err).To(MatchError("Missing Email"))
+			Expect(sfServer.ReceivedRequests()).To(BeEmpty())
+		})
+	})
+})